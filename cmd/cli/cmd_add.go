@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"pj/internal/catalog"
 	"pj/internal/config"
+	"pj/internal/detect"
 )
 
 type AddCmd struct {
@@ -28,21 +29,46 @@ func (cmd *AddCmd) Run(g *Globals) error {
 		return fmt.Errorf("path is not a directory: %s", path)
 	}
 
+	// A worktree belongs to its main project rather than standing alone.
+	if main, ok := mainProjectOf(cat, path); ok {
+		main, err := syncWorktrees(cat, main)
+		if err != nil {
+			return err
+		}
+		for _, w := range main.Worktrees {
+			if w.Path == path {
+				fmt.Fprintf(g.Out, "Recorded worktree: %s@%s (%s)\n", main.Name, w.Name(), path)
+				return nil
+			}
+		}
+	}
+
 	name := cmd.Name
 	if name == "" {
 		name = filepath.Base(path)
 	}
 
-	p := catalog.NewProject(name, path)
-
-	if err := cat.Add(p); err != nil {
-		return fmt.Errorf("failed to add project %q: %w", name, err)
-	}
+	p := catalog.NewProject(name, path).
+		WithStack(detect.Dir(path)).
+		WithRemote(originOf(path))
 
-	if err := cat.Save(); err != nil {
-		return fmt.Errorf("failed to save catalog: %w", err)
+	var children []catalog.Project
+	err = mutate(cat, func(tx catalog.Tx) error {
+		if err := tx.Add(p); err != nil {
+			return fmt.Errorf("failed to add project %q: %w", name, err)
+		}
+		if children, _, err = syncSubprojects(tx, g.Config, p); err != nil {
+			return fmt.Errorf("failed to add sub-projects of %q: %w", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	fmt.Fprintf(g.Out, "Added: %s (%s)\n", p.Name, p.Path)
+	for _, c := range children {
+		fmt.Fprintf(g.Out, "Added sub-project: %s/%s (%s)\n", p.Name, c.Name, c.Path)
+	}
 	return nil
 }
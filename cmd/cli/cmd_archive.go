@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pj/internal/archive"
+	"pj/internal/catalog"
+	"strings"
+)
+
+type ArchiveCmd struct {
+	Name     string `arg:"" help:"Project to archive" completion:"pj list -n"`
+	Compress bool   `help:"Move the project directory into a .tar.gz in the archive directory"`
+}
+
+func (cmd *ArchiveCmd) Run(g *Globals) error {
+	project, err := findProject(g.Cat, cmd.Name)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+	if project.Archived() && (!cmd.Compress || project.ArchivePath != "") {
+		fmt.Fprintf(g.Out, "Already archived: %s\n", project.Name)
+		return nil
+	}
+
+	var dest string
+	if cmd.Compress {
+		if dest, err = compressProject(g, project); err != nil {
+			return err
+		}
+	}
+
+	// Sub-projects live inside the monorepo and go with it.
+	var children []catalog.Project
+	err = mutate(g.Cat, func(tx catalog.Tx) error {
+		current, err := tx.Get(project.ID)
+		if err != nil {
+			return err
+		}
+		for _, c := range descendants(tx, project.ID) {
+			if c.Archived() {
+				continue
+			}
+			if err := tx.Update(c.ArchiveWithParent()); err != nil {
+				return err
+			}
+			children = append(children, c)
+		}
+		return tx.Update(current.WithStatus(catalog.StatusArchived).WithArchivePath(dest))
+	})
+	if err != nil {
+		if dest != "" {
+			os.Remove(dest)
+		}
+		return fmt.Errorf("failed to archive %q: %w", project.Name, err)
+	}
+
+	fmt.Fprintf(g.Out, "Archived: %s\n", project.Name)
+	if len(children) > 0 {
+		fmt.Fprintf(g.Out, "Archived %d sub-projects\n", len(children))
+	}
+	if dest == "" {
+		return nil
+	}
+	if err := os.RemoveAll(project.Path); err != nil {
+		return fmt.Errorf("compressed into %s but failed to remove %s: %w", dest, project.Path, err)
+	}
+	fmt.Fprintf(g.Out, "Compressed %s into %s\n", project.Path, dest)
+	return nil
+}
+
+// compressProject writes the project's directory to a tarball in the
+// archive directory and returns its path. The directory is left in place.
+func compressProject(g *Globals, project catalog.Project) (string, error) {
+	if len(project.Worktrees) > 0 {
+		return "", fmt.Errorf("%s has worktrees outside its directory; remove them with 'pj wt rm' first", project.Name)
+	}
+	info, err := os.Stat(project.Path)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("cannot compress %s: %s is not a directory on this host", project.Name, project.Path)
+	}
+
+	dir, err := g.Config.ArchiveDir(g.CatalogPath)
+	if err != nil {
+		return "", err
+	}
+	name := strings.ReplaceAll(project.Name, string(filepath.Separator), "-")
+	dest := filepath.Join(dir, fmt.Sprintf("%s-%.8s.tar.gz", name, project.ID))
+	if _, err := os.Stat(dest); err == nil {
+		return "", fmt.Errorf("archive %s already exists", dest)
+	}
+	if err := archive.Create(project.Path, dest); err != nil {
+		return "", fmt.Errorf("failed to compress %s: %w", project.Path, err)
+	}
+	return dest, nil
+}
+
+type UnarchiveCmd struct {
+	Name string `arg:"" help:"Project to unarchive" completion:"pj list -n --all"`
+}
+
+func (cmd *UnarchiveCmd) Run(g *Globals) error {
+	project, err := findProject(g.Cat, cmd.Name)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+	if !project.Archived() && project.ArchivePath == "" {
+		fmt.Fprintf(g.Out, "Not archived: %s\n", project.Name)
+		return nil
+	}
+
+	if project.ArchivePath != "" {
+		if err := archive.Extract(project.ArchivePath, project.Path); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", project.Name, err)
+		}
+	}
+
+	// Sub-projects archived on their own stay archived.
+	err = mutate(g.Cat, func(tx catalog.Tx) error {
+		current, err := tx.Get(project.ID)
+		if err != nil {
+			return err
+		}
+		for _, c := range descendants(tx, project.ID) {
+			if restored := c.RestoreWithParent(); restored.StatusBeforeArchive != c.StatusBeforeArchive {
+				if err := tx.Update(restored); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Update(current.WithStatus("").WithArchivePath(""))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unarchive %q: %w", project.Name, err)
+	}
+
+	fmt.Fprintf(g.Out, "Unarchived: %s\n", project.Name)
+	if project.ArchivePath != "" {
+		fmt.Fprintf(g.Out, "Restored %s from %s\n", project.Path, project.ArchivePath)
+		if err := os.Remove(project.ArchivePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("restored %s but failed to remove %s: %w", project.Path, project.ArchivePath, err)
+		}
+	}
+	return nil
+}
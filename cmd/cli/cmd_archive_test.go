@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveCmd_Run(t *testing.T) {
+	t.Run("hides archived projects unless --all", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "old-api")
+		createTestProject(t, g, "web")
+		require.NoError(t, (&ArchiveCmd{Name: "old-api"}).Run(g))
+
+		out.Reset()
+		require.NoError(t, (&ListCmd{Names: true}).Run(g))
+		assert.Equal(t, "web\n", out.String())
+
+		out.Reset()
+		require.NoError(t, (&ListCmd{Names: true, All: true}).Run(g))
+		assert.Equal(t, "old-api\nweb\n", out.String())
+
+		out.Reset()
+		require.NoError(t, (&ListCmd{All: true}).Run(g))
+		assert.Contains(t, out.String(), "(archived)")
+
+		assert.Equal(t, []string{"web"}, cdCompletions(g.Cat, ""))
+	})
+
+	t.Run("name matches prefer active projects", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		createTestProject(t, g, "api-v1")
+		require.NoError(t, (&ArchiveCmd{Name: "api-v1"}).Run(g))
+
+		p, err := findProject(g.Cat, "api")
+		require.NoError(t, err)
+		assert.Equal(t, "api", p.Name)
+		p, err = findProject(g.Cat, "v1")
+		require.NoError(t, err)
+		assert.Equal(t, "api-v1", p.Name)
+	})
+
+	t.Run("unarchive makes the project active again", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		require.NoError(t, (&ArchiveCmd{Name: "api"}).Run(g))
+		out.Reset()
+
+		require.NoError(t, (&UnarchiveCmd{Name: "api"}).Run(g))
+
+		assert.Equal(t, "Unarchived: api\n", out.String())
+		p, err := findProject(g.Cat, "api")
+		require.NoError(t, err)
+		assert.False(t, p.Archived())
+	})
+
+	t.Run("archives sub-projects with their monorepo", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createMonorepo(t, g)
+		out.Reset()
+
+		require.NoError(t, (&ArchiveCmd{Name: "mono"}).Run(g))
+
+		assert.Equal(t, "Archived: mono\nArchived 2 sub-projects\n", out.String())
+		assert.Empty(t, g.Cat.Filter(catalog.FilterOptions{ExcludeArchived: true}))
+	})
+
+	t.Run("unarchive restores the sub-projects' own statuses", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createMonorepo(t, g)
+		require.NoError(t, (&EditCmd{Name: "mono/pkg-a", Status: "paused"}).Run(g))
+		require.NoError(t, (&ArchiveCmd{Name: "mono/pkg-b"}).Run(g))
+		out.Reset()
+
+		require.NoError(t, (&ArchiveCmd{Name: "mono"}).Run(g))
+		require.NoError(t, (&UnarchiveCmd{Name: "mono"}).Run(g))
+
+		assert.Equal(t, "Archived: mono\nArchived 1 sub-projects\nUnarchived: mono\n", out.String())
+		a, err := findProject(g.Cat, "mono/pkg-a")
+		require.NoError(t, err)
+		assert.Equal(t, catalog.StatusPaused, a.Status)
+		b, err := findProject(g.Cat, "mono/pkg-b")
+		require.NoError(t, err)
+		assert.True(t, b.Archived())
+	})
+}
+
+func TestArchiveCmd_Compress(t *testing.T) {
+	g, out := newTestGlobals(t)
+	dir := createGoProject(t, g, "api")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+	g.Config.ArchiveRoot = filepath.Join(t.TempDir(), "attic")
+	out.Reset()
+
+	require.NoError(t, (&ArchiveCmd{Name: "api", Compress: true}).Run(g))
+
+	assert.NoDirExists(t, dir)
+	p, err := findProject(g.Cat, "api")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(g.Config.ArchiveRoot, "api-"+p.ID[:8]+".tar.gz"), p.ArchivePath)
+	assert.FileExists(t, p.ArchivePath)
+	assert.Contains(t, out.String(), "Compressed "+dir+" into "+p.ArchivePath+"\n")
+
+	out.Reset()
+	require.NoError(t, (&UnarchiveCmd{Name: "api"}).Run(g))
+
+	assert.Contains(t, out.String(), "Restored "+dir+" from "+p.ArchivePath+"\n")
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data))
+	assert.NoFileExists(t, p.ArchivePath)
+	p, err = findProject(g.Cat, "api")
+	require.NoError(t, err)
+	assert.Empty(t, p.ArchivePath)
+	assert.Empty(t, p.Status)
+}
+
+func TestEditCmd_Status(t *testing.T) {
+	g, out := newTestGlobals(t)
+	createTestProject(t, g, "api")
+
+	require.NoError(t, (&EditCmd{Name: "api", Status: "paused"}).Run(g))
+	out.Reset()
+	require.NoError(t, (&ShowCmd{Name: "api"}).Run(g))
+	assert.Contains(t, out.String(), "Status: paused\n")
+
+	out.Reset()
+	require.NoError(t, (&ListCmd{}).Run(g))
+	assert.Contains(t, out.String(), "(paused)")
+
+	require.NoError(t, (&EditCmd{Name: "api", Status: "active"}).Run(g))
+	p, err := findProject(g.Cat, "api")
+	require.NoError(t, err)
+	assert.Empty(t, p.Status)
+}
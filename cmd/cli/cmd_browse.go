@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pj/internal/forge"
+	"pj/internal/git"
+	"runtime"
+)
+
+type BrowseCmd struct {
+	Name   string `arg:"" help:"Project name" completion:"pj list -n"`
+	Path   string `arg:"" optional:"" help:"File inside the project, as file[:line]"`
+	Branch string `short:"b" help:"Branch or commit (default: the checked-out branch for files)"`
+	PR     int    `name:"pr" help:"Open a pull or merge request"`
+	Issue  int    `help:"Open an issue"`
+	Print  bool   `short:"p" help:"Print the URL instead of opening it"`
+}
+
+func (cmd *BrowseCmd) Run(g *Globals) error {
+	project, err := findProject(g.Cat, cmd.Name)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+
+	targets := 0
+	for _, set := range []bool{cmd.Path != "", cmd.PR > 0, cmd.Issue > 0} {
+		if set {
+			targets++
+		}
+	}
+	if targets > 1 {
+		return errors.New("a path, --pr and --issue cannot be combined")
+	}
+
+	remote := project.Remote
+	if remote == "" {
+		remote = originOf(project.Path)
+	}
+	if remote == "" {
+		return fmt.Errorf("%s has no git remote", project.Name)
+	}
+	u, err := git.ParseRemote(remote)
+	if err != nil {
+		return err
+	}
+
+	link := forge.Link{Branch: cmd.Branch, PullRequest: cmd.PR, Issue: cmd.Issue}
+	if cmd.Path != "" {
+		target, err := parseFileTarget(project.Path, cmd.Path)
+		if err != nil {
+			return err
+		}
+		link.File, link.Line = target.Path, target.Line
+		if link.Branch == "" {
+			link.Branch = currentBranch(project.Path)
+		}
+	}
+
+	webURL, err := forge.URL(g.Config.Forges, u, link)
+	if err != nil {
+		return fmt.Errorf("%s: %w", project.Name, err)
+	}
+	if cmd.Print {
+		fmt.Fprintln(g.Out, webURL)
+		return nil
+	}
+
+	fmt.Fprintf(g.Out, "Opening %s\n", webURL)
+	runCmd := g.RunCmd
+	if runCmd == nil {
+		runCmd = defaultRunCmd
+	}
+	return runCmd(browserCommand(), webURL)
+}
+
+// currentBranch is the checked-out branch, or "" when it cannot be told.
+func currentBranch(dir string) string {
+	status, err := git.StatusOf(context.Background(), dir)
+	if err != nil || status.Branch == "(detached)" {
+		return ""
+	}
+	return status.Branch
+}
+
+func browserCommand() string {
+	if runtime.GOOS == "darwin" {
+		return "open"
+	}
+	return "xdg-open"
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"pj/internal/catalog"
+)
+
+type CatalogCmd struct {
+	Merge   CatalogMergeCmd   `cmd:"" help:"Three-way merge catalog files (usable as a git merge driver)"`
+	Migrate CatalogMigrateCmd `cmd:"" help:"Convert the catalog to another storage backend"`
+}
+
+type CatalogMergeCmd struct {
+	Base   string `arg:"" help:"Common ancestor catalog (git's %O)"`
+	Ours   string `arg:"" help:"Our catalog; receives the result unless --output is set (git's %A)"`
+	Theirs string `arg:"" help:"Their catalog (git's %B)"`
+	Output string `short:"o" help:"Write the merged catalog here instead of over ours"`
+	JSON   bool   `help:"Report conflicts as JSON"`
+}
+
+func (cmd *CatalogMergeCmd) Run(g *Globals) error {
+	var snaps [3]catalog.Snapshot
+	for i, path := range []string{cmd.Base, cmd.Ours, cmd.Theirs} {
+		snap, err := catalog.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		snaps[i] = snap
+	}
+
+	result := catalog.Merge(snaps[0], snaps[1], snaps[2])
+
+	output := cmd.Output
+	if output == "" {
+		output = cmd.Ours
+	}
+	if err := catalog.WriteFile(output, result.Snapshot); err != nil {
+		return fmt.Errorf("failed to write merged catalog: %w", err)
+	}
+
+	if len(result.Conflicts) == 0 {
+		if !cmd.JSON {
+			fmt.Fprintf(g.Out, "Merged %d projects into %s\n", len(result.Snapshot.Projects), output)
+		}
+		return nil
+	}
+
+	if cmd.JSON {
+		enc := json.NewEncoder(g.Out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result.Conflicts); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(g.Out, "Merged with %d unresolved conflicts:\n", len(result.Conflicts))
+		for _, c := range result.Conflicts {
+			fmt.Fprintf(g.Out, "  - %s: %s\n", c.Kind, c.Message)
+		}
+	}
+	return errors.New("catalog merge has unresolved conflicts")
+}
+
+type CatalogMigrateCmd struct {
+	To     catalog.Backend `required:"" enum:"yaml,sqlite" help:"Backend to convert to (yaml, sqlite)"`
+	Output string          `short:"o" help:"Where to write the new catalog (default: the current path with the backend's extension)"`
+	Force  bool            `short:"f" help:"Overwrite the output if it exists"`
+}
+
+func (cmd *CatalogMigrateCmd) Run(g *Globals) error {
+	from := catalog.BackendForPath(g.CatalogPath, catalog.Backend(g.Config.Catalog.Backend))
+	if from == cmd.To {
+		return fmt.Errorf("catalog %s already uses the %s backend", g.CatalogPath, cmd.To)
+	}
+
+	output := cmd.Output
+	if output == "" {
+		output = catalog.PathForBackend(g.CatalogPath, cmd.To)
+	}
+	if _, err := os.Stat(output); err == nil && !cmd.Force {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", output)
+	}
+
+	snap, err := catalog.ReadSnapshot(g.CatalogPath, from)
+	if err != nil {
+		return err
+	}
+	if err := catalog.WriteSnapshot(output, cmd.To, snap); err != nil {
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+
+	fmt.Fprintf(g.Out, "Migrated %d projects and %d workspaces to %s\n", len(snap.Projects), len(snap.Workspaces), output)
+	fmt.Fprintf(g.Out, "Use it with --catalog %s or set catalog.backend: %s in your config\n", output, cmd.To)
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCatalogFile(t *testing.T, dir, name string, projects ...catalog.Project) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, catalog.WriteFile(path, catalog.Snapshot{Projects: projects}))
+	return path
+}
+
+func TestCatalogMergeCmd_Run(t *testing.T) {
+	added := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	api := catalog.Project{ID: "a", Name: "api", Path: "/src/api", AddedAt: added}
+
+	t.Run("writes merged catalog over ours", func(t *testing.T) {
+		g, buf := newTestGlobals(t)
+		dir := t.TempDir()
+		base := writeCatalogFile(t, dir, "base.yaml", api)
+		ours := writeCatalogFile(t, dir, "ours.yaml", api.WithEditor("nvim"))
+		theirs := writeCatalogFile(t, dir, "theirs.yaml", api.WithTags("work"))
+
+		cmd := CatalogMergeCmd{Base: base, Ours: ours, Theirs: theirs}
+		err := cmd.Run(g)
+
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Merged 1 projects")
+		snap, err := catalog.ReadFile(ours)
+		require.NoError(t, err)
+		require.Len(t, snap.Projects, 1)
+		assert.Equal(t, "nvim", snap.Projects[0].Editor)
+		assert.Equal(t, []string{"work"}, snap.Projects[0].Tags)
+	})
+
+	t.Run("writes to output when given", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		dir := t.TempDir()
+		base := writeCatalogFile(t, dir, "base.yaml")
+		ours := writeCatalogFile(t, dir, "ours.yaml", api)
+		theirs := writeCatalogFile(t, dir, "theirs.yaml")
+		out := filepath.Join(dir, "merged.yaml")
+
+		cmd := CatalogMergeCmd{Base: base, Ours: ours, Theirs: theirs, Output: out}
+		require.NoError(t, cmd.Run(g))
+
+		snap, err := catalog.ReadFile(out)
+		require.NoError(t, err)
+		assert.Len(t, snap.Projects, 1)
+	})
+
+	t.Run("reports duplicate paths as JSON and fails", func(t *testing.T) {
+		g, buf := newTestGlobals(t)
+		dir := t.TempDir()
+		base := writeCatalogFile(t, dir, "base.yaml")
+		ours := writeCatalogFile(t, dir, "ours.yaml", api)
+		theirs := writeCatalogFile(t, dir, "theirs.yaml",
+			catalog.Project{ID: "b", Name: "api2", Path: "/src/api", AddedAt: added})
+
+		cmd := CatalogMergeCmd{Base: base, Ours: ours, Theirs: theirs, JSON: true}
+		err := cmd.Run(g)
+
+		require.Error(t, err)
+		var conflicts []catalog.MergeConflict
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &conflicts))
+		require.Len(t, conflicts, 1)
+		assert.Equal(t, "a", conflicts[0].Kept)
+		snap, err := catalog.ReadFile(ours)
+		require.NoError(t, err)
+		assert.Len(t, snap.Projects, 1)
+	})
+}
+
+func TestCatalogMigrateCmd_Run(t *testing.T) {
+	t.Run("converts yaml catalog to sqlite next to it", func(t *testing.T) {
+		g, buf := newTestGlobals(t)
+		g.CatalogPath = filepath.Join(t.TempDir(), "catalog.yaml")
+		g.Cat = openTestCatalog(t, g.CatalogPath)
+		createTestProject(t, g, "api")
+		createTestProject(t, g, "web")
+
+		cmd := CatalogMigrateCmd{To: catalog.BackendSQLite}
+		err := cmd.Run(g)
+
+		require.NoError(t, err)
+		dbPath := filepath.Join(filepath.Dir(g.CatalogPath), "catalog.db")
+		assert.Contains(t, buf.String(), "Migrated 2 projects and 0 workspaces to "+dbPath)
+		migrated, err := catalog.Open(dbPath, catalog.BackendSQLite, config.PathMapper{})
+		require.NoError(t, err)
+		assert.Len(t, migrated.Search("api"), 1)
+		assert.Equal(t, 2, migrated.Count())
+	})
+
+	t.Run("refuses to overwrite without force", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		dir := t.TempDir()
+		g.CatalogPath = filepath.Join(dir, "catalog.yaml")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.db"), nil, 0o644))
+
+		cmd := CatalogMigrateCmd{To: catalog.BackendSQLite}
+		err := cmd.Run(g)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+	})
+
+	t.Run("rejects migrating to the current backend", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		g.CatalogPath = filepath.Join(t.TempDir(), "catalog.yaml")
+
+		cmd := CatalogMigrateCmd{To: catalog.BackendYAML}
+		err := cmd.Run(g)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already uses the yaml backend")
+	})
+}
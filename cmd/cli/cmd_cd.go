@@ -3,14 +3,185 @@ package main
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/pkg/pj"
+	"slices"
+	"strings"
 )
 
 type CdCmd struct {
-	Name string `arg:"" help:"Project name" completion:"pj list -n"`
+	Name     string `arg:"" help:"Project name, optionally followed by /subdir, or - for the previous project" completion:"pj list -n"`
+	Complete bool   `hidden:"" help:"Print completions for a partial argument"`
 }
 
 func (cmd *CdCmd) Run(g *Globals) error {
-	fmt.Fprintln(g.Out, "The 'cd' command requires shell integration.")
-	fmt.Fprintln(g.Out, "Add to your shell config: eval \"$(pj init)\"")
-	return errors.New("shell integration required")
+	if cmd.Complete {
+		for _, c := range cdCompletions(g.Cat, cmd.Name) {
+			fmt.Fprintln(g.Out, c)
+		}
+		return nil
+	}
+
+	if !shellIntegrationActive() {
+		fmt.Fprintln(g.Out, "The 'cd' command requires shell integration.")
+		fmt.Fprintln(g.Out, "Add to your shell config: eval \"$(pj init)\"")
+		return errors.New("shell integration required")
+	}
+
+	if cmd.Name == "-" {
+		return cdPrevious(g)
+	}
+
+	dir, err := resolveCdTarget(g.Cat, cmd.Name)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+	changeDir(g, dir)
+	return nil
+}
+
+func cdPrevious(g *Globals) error {
+	id, ok := sessionID()
+	if !ok {
+		return errors.New("no shell session; re-run eval \"$(pj init)\" to enable 'pj cd -'")
+	}
+	previous := loadSession(id).Previous
+	if previous == "" {
+		return errors.New("no previous project in this shell session")
+	}
+	if _, err := os.Stat(previous); err != nil {
+		return fmt.Errorf("path no longer exists: %s", previous)
+	}
+	changeDir(g, previous)
+	return nil
+}
+
+// resolveCdTarget resolves "name/sub/dir" to sub/dir inside the project
+// matching name, and "name@worktree/sub/dir" to sub/dir inside one of its
+// worktrees. In a monorepo, "name/pkg" enters the sub-project pkg. A
+// target that names no project before its first slash is matched whole,
+// so path fragments keep working.
+func resolveCdTarget(cat *pj.Catalog, target string) (string, error) {
+	project, root, sub, err := splitCdTarget(cat, target)
+	if err != nil {
+		return "", err
+	}
+
+	if sub == "" {
+		if _, err := os.Stat(root); err != nil {
+			return "", fmt.Errorf("path no longer exists: %s", root)
+		}
+		return root, nil
+	}
+
+	dir := filepath.Join(root, sub)
+	if rel, err := filepath.Rel(root, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside project %s", sub, project.Name)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("no directory %s in project %s", sub, project.Name)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s in project %s is not a directory", sub, project.Name)
+	}
+	return dir, nil
+}
+
+// splitCdTarget finds the project directory a cd target starts in and the
+// rest of the path below it.
+func splitCdTarget(cat *pj.Catalog, target string) (catalog.Project, string, string, error) {
+	name, sub, found := strings.Cut(target, "/")
+	if !found || name == "" {
+		name, sub = target, ""
+	}
+
+	// Worktrees are often named after branches such as feature/x, so the
+	// longest prefix naming one wins.
+	if at := strings.Index(name, "@"); at > 0 {
+		for end := len(target); end > at; end = strings.LastIndex(target[:end], "/") {
+			project, root, err := findProjectDir(cat, target[:end])
+			if !errors.Is(err, errNoWorktree) || end == len(name) {
+				return project, root, strings.TrimPrefix(target[end:], "/"), err
+			}
+		}
+	}
+
+	project, root, err := findProjectDir(cat, name)
+	if errors.Is(err, pj.ErrNoMatch) && sub != "" {
+		sub = ""
+		project, root, err = findProjectDir(cat, target)
+	}
+	if err == nil && root == project.Path {
+		project, sub = descendSubprojects(cat, project, sub)
+		root = project.Path
+	}
+	return project, root, sub, err
+}
+
+// cdCompletions completes project names, then sub-projects and
+// directories inside the project once the word contains a slash.
+func cdCompletions(cat *pj.Catalog, word string) []string {
+	name, sub, found := strings.Cut(word, "/")
+	if !found {
+		var names []string
+		for _, p := range cat.List() {
+			if p.Archived() {
+				continue
+			}
+			if strings.HasPrefix(p.Name, word) {
+				names = append(names, p.Name)
+			}
+			for _, w := range p.Worktrees {
+				if ref := p.Name + "@" + w.Name(); strings.HasPrefix(word, p.Name+"@") && strings.HasPrefix(ref, word) {
+					names = append(names, ref)
+				}
+			}
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	project, root, err := findProjectDir(cat, name)
+	if err != nil {
+		return nil
+	}
+	parent, prefix := "", sub
+	if i := strings.LastIndex(sub, "/"); i >= 0 {
+		parent, prefix = sub[:i+1], sub[i+1:]
+	}
+	inside := parent
+	if root == project.Path {
+		project, inside = descendSubprojects(cat, project, parent)
+		root = project.Path
+	}
+
+	var dirs []string
+	if inside == "" {
+		for _, c := range subprojects(cat, project.ID) {
+			if strings.HasPrefix(c.Name, prefix) {
+				dirs = append(dirs, name+"/"+parent+c.Name+"/")
+			}
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(root, inside))
+	if err != nil {
+		return dirs
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+		if strings.HasPrefix(e.Name(), ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		dirs = append(dirs, name+"/"+parent+e.Name()+"/")
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
 }
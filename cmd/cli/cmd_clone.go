@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/detect"
+	"pj/internal/git"
+)
+
+type CloneCmd struct {
+	URL  string `arg:"" help:"Repository URL (https, ssh, user@host:owner/repo, file://) or local path"`
+	Name string `short:"n" help:"Project name (defaults to the repository name)"`
+}
+
+func (cmd *CloneCmd) Run(g *Globals) error {
+	remote := cmd.URL
+	if _, err := os.Stat(remote); err == nil {
+		if remote, err = filepath.Abs(remote); err != nil {
+			return err
+		}
+	}
+	u, err := git.ParseURL(remote)
+	if err != nil {
+		return err
+	}
+
+	root, err := g.Config.CloneDir()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(root, u.Host, filepath.FromSlash(u.Path))
+
+	if existing, err := g.Cat.GetByPath(dir); err == nil {
+		fmt.Fprintf(g.Out, "Already cloned: %s (%s)\n", existing.Name, existing.Path)
+		changeDir(g, dir)
+		return nil
+	} else if !errors.Is(err, catalog.ErrNotFound) {
+		return err
+	}
+
+	if _, err := os.Stat(dir); err == nil {
+		if err := checkExistingClone(dir, remote, u); err != nil {
+			return err
+		}
+		fmt.Fprintf(g.Out, "Using existing clone: %s\n", dir)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(dir), err)
+		}
+		fmt.Fprintf(g.Out, "Cloning %s into %s\n", remote, dir)
+		if err := git.Clone(context.Background(), remote, dir); err != nil {
+			return err
+		}
+	}
+
+	name := cmd.Name
+	if name == "" {
+		name = u.Repo()
+	}
+	p := catalog.NewProject(name, dir).
+		WithStack(detect.Dir(dir)).
+		WithRemote(u.String())
+	if err := mutate(g.Cat, func(tx catalog.Tx) error { return tx.Add(p) }); err != nil {
+		return fmt.Errorf("failed to add project %q: %w", name, err)
+	}
+
+	fmt.Fprintf(g.Out, "Added: %s (%s)\n", p.Name, p.Path)
+	changeDir(g, dir)
+	return nil
+}
+
+// checkExistingClone makes sure the directory in the way of a clone is a
+// clone of the same repository, so an unrelated directory is not
+// registered under its URL.
+func checkExistingClone(dir, remote string, want git.RemoteURL) error {
+	origin, err := git.Origin(context.Background(), dir)
+	if err != nil || origin == "" {
+		return fmt.Errorf("%s already exists and is not a clone of %s", dir, remote)
+	}
+	if got, err := git.ParseURL(origin); err != nil || got != want {
+		return fmt.Errorf("%s already exists as a clone of %s, not %s", dir, origin, remote)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBareRepo creates <dir>/acme/api.git holding one commit with a go.mod.
+func newBareRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	work := t.TempDir()
+	bare := filepath.Join(t.TempDir(), "acme", "api.git")
+	require.NoError(t, os.WriteFile(filepath.Join(work, "go.mod"), []byte("module api\n"), 0o644))
+	for _, args := range [][]string{
+		{"-C", work, "init", "-q"},
+		{"-C", work, "add", "go.mod"},
+		{"-C", work, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+		{"clone", "-q", "--bare", work, bare},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return bare
+}
+
+func TestCloneCmd_Run(t *testing.T) {
+	t.Setenv(cdFileEnv, "")
+	bare := newBareRepo(t)
+
+	t.Run("clones into the host/owner/repo layout and registers", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		g.Config.CloneRoot = t.TempDir()
+		want := filepath.Join(g.Config.CloneRoot, "localhost", "acme", "api")
+
+		require.NoError(t, (&CloneCmd{URL: bare}).Run(g))
+
+		assert.FileExists(t, filepath.Join(want, "go.mod"))
+		p, err := g.Cat.GetByPath(want)
+		require.NoError(t, err)
+		assert.Equal(t, "api", p.Name)
+		assert.Equal(t, "localhost/acme/api", p.Remote)
+		assert.Equal(t, []string{"go"}, p.Languages())
+		assert.Contains(t, out.String(), "Added: api ("+want+")")
+		assert.Contains(t, out.String(), "Run: cd "+want)
+	})
+
+	t.Run("accepts file URLs and a custom name", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		g.Config.CloneRoot = t.TempDir()
+
+		require.NoError(t, (&CloneCmd{URL: "file://" + bare, Name: "acme-api"}).Run(g))
+
+		projects := g.Cat.Search("acme-api")
+		require.Len(t, projects, 1)
+		assert.Equal(t, "localhost/acme/api", projects[0].Remote)
+	})
+
+	t.Run("changes into an existing clone instead of cloning twice", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		g.Config.CloneRoot = t.TempDir()
+		cdFile := filepath.Join(t.TempDir(), "cd")
+		t.Setenv(cdFileEnv, cdFile)
+		t.Setenv("XDG_STATE_HOME", t.TempDir())
+		require.NoError(t, (&CloneCmd{URL: bare}).Run(g))
+		out.Reset()
+
+		require.NoError(t, (&CloneCmd{URL: bare}).Run(g))
+
+		assert.Contains(t, out.String(), "Already cloned: api")
+		assert.Equal(t, 1, g.Cat.Count())
+		data, err := os.ReadFile(cdFile)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(g.Config.CloneRoot, "localhost", "acme", "api"), string(data))
+	})
+
+	t.Run("registers an existing clone of the same repository", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		g.Config.CloneRoot = t.TempDir()
+		dir := filepath.Join(g.Config.CloneRoot, "localhost", "acme", "api")
+		require.NoError(t, os.MkdirAll(filepath.Dir(dir), 0o755))
+		gitOut, err := exec.Command("git", "clone", "-q", bare, dir).CombinedOutput()
+		require.NoError(t, err, string(gitOut))
+
+		require.NoError(t, (&CloneCmd{URL: bare}).Run(g))
+
+		assert.Contains(t, out.String(), "Using existing clone: "+dir)
+		assert.Equal(t, 1, g.Cat.Count())
+	})
+
+	t.Run("refuses an existing directory that is not a clone", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		g.Config.CloneRoot = t.TempDir()
+		dir := filepath.Join(g.Config.CloneRoot, "localhost", "acme", "api")
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+
+		err := (&CloneCmd{URL: bare}).Run(g)
+
+		assert.ErrorContains(t, err, "already exists and is not a clone of "+bare)
+		assert.Equal(t, 0, g.Cat.Count())
+	})
+
+	t.Run("refuses an existing clone of another repository", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		g.Config.CloneRoot = t.TempDir()
+		dir := filepath.Join(g.Config.CloneRoot, "localhost", "acme", "api")
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		for _, args := range [][]string{
+			{"-C", dir, "init", "-q"},
+			{"-C", dir, "remote", "add", "origin", "https://github.com/other/api.git"},
+		} {
+			gitOut, err := exec.Command("git", args...).CombinedOutput()
+			require.NoError(t, err, string(gitOut))
+		}
+
+		err := (&CloneCmd{URL: bare}).Run(g)
+
+		assert.ErrorContains(t, err, "already exists as a clone of https://github.com/other/api.git")
+		assert.Equal(t, 0, g.Cat.Count())
+	})
+
+	t.Run("reports clone failures", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		g.Config.CloneRoot = t.TempDir()
+
+		err := (&CloneCmd{URL: filepath.Join(t.TempDir(), "missing.git")}).Run(g)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, g.Cat.Count())
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"pj/internal/catalog"
+	"pj/internal/detect"
 	"pj/internal/ui"
 	"strings"
 
@@ -131,7 +132,7 @@ func executeCreate(g *Globals, result createResult) error {
 
 	completed = true
 	renderCreateSummary(g, result)
-	printCdHint(g, projectPath)
+	changeDir(g, projectPath)
 	return nil
 }
 
@@ -184,13 +185,12 @@ func createGitignore(projectPath string) error {
 func registerProject(g *Globals, result createResult, projectPath string) error {
 	p := catalog.NewProject(result.Name, projectPath).
 		WithDescription(result.Description).
-		WithEditor(result.Editor)
-	if err := g.Cat.Add(p); err != nil {
+		WithEditor(result.Editor).
+		WithStack(detect.Dir(projectPath)).
+		WithRemote(originOf(projectPath))
+	if err := mutate(g.Cat, func(tx catalog.Tx) error { return tx.Add(p) }); err != nil {
 		return fmt.Errorf("adding project to catalog: %w", err)
 	}
-	if err := g.Cat.Save(); err != nil {
-		return fmt.Errorf("saving catalog: %w", err)
-	}
 	return nil
 }
 
@@ -218,13 +218,3 @@ func renderCreateSummary(g *Globals, r createResult) {
 	output := ui.RenderSuccess(r.Name, projectPath, checks)
 	fmt.Fprint(g.Out, output)
 }
-
-func printCdHint(g *Globals, projectPath string) {
-	cdFile := os.Getenv("__PJ_CD_FILE")
-	if cdFile != "" {
-		if err := os.WriteFile(cdFile, []byte(projectPath), 0o600); err == nil {
-			return
-		}
-	}
-	fmt.Fprintf(g.Out, "\nRun: cd %s\n", projectPath)
-}
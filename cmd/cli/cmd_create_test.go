@@ -287,18 +287,17 @@ func TestRegisterProject(t *testing.T) {
 		}, projectPath)
 
 		require.NoError(t, err)
-		require.NoError(t, g.Cat.Load())
-		assert.Equal(t, 1, g.Cat.Count())
+		assert.Equal(t, 1, openTestCatalog(t, g.CatalogPath).Count())
 	})
 }
 
-func TestPrintCdHint(t *testing.T) {
+func TestChangeDir(t *testing.T) {
 	t.Run("writes path to cd file when env set", func(t *testing.T) {
 		g, out := newTestGlobals(t)
 		cdFile := filepath.Join(t.TempDir(), "cd-target")
 		t.Setenv("__PJ_CD_FILE", cdFile)
 
-		printCdHint(g, "/home/user/projects/my-project")
+		changeDir(g, "/home/user/projects/my-project")
 
 		content, err := os.ReadFile(cdFile)
 		require.NoError(t, err)
@@ -310,7 +309,7 @@ func TestPrintCdHint(t *testing.T) {
 		g, out := newTestGlobals(t)
 		t.Setenv("__PJ_CD_FILE", "")
 
-		printCdHint(g, "/home/user/projects/my-project")
+		changeDir(g, "/home/user/projects/my-project")
 
 		assert.Contains(t, out.String(), "cd /home/user/projects/my-project")
 	})
@@ -334,7 +333,7 @@ func TestExecuteCreateCleansUpOnError(t *testing.T) {
 		location := t.TempDir()
 		projectPath := filepath.Join(location, "doomed")
 		require.NoError(t, os.Mkdir(projectPath, 0o755))
-		require.NoError(t, g.Cat.Add(catalog.NewProject("doomed", projectPath)))
+		mutateCatalog(t, g, func(tx catalog.Tx) error { return tx.Add(catalog.NewProject("doomed", projectPath)) })
 		require.NoError(t, os.Remove(projectPath))
 
 		err := executeCreate(g, createResult{
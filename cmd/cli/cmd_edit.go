@@ -6,13 +6,35 @@ import (
 )
 
 type EditCmd struct {
-	Name   string `arg:"" help:"Project name to edit" completion:"pj list -n"`
-	Editor string `help:"Set editor command (e.g., code, nvim)"`
+	Name     string   `arg:"" help:"Project name to edit" completion:"pj list -n"`
+	Editor   string   `help:"Set editor command (e.g., code, nvim)"`
+	Tag      []string `help:"Add tags"`
+	Untag    []string `help:"Remove tags"`
+	TmuxPane []string `name:"tmux-pane" sep:"none" help:"Set a pane command for 'pj tmux' (repeatable, \"\" for a shell)"`
+	Status   string   `enum:"active,paused,archived," default:"" help:"Set the lifecycle status: active, paused or archived"`
 }
 
 func (cmd *EditCmd) applyEdits(p *catalog.Project) {
 	if cmd.Editor != "" {
-		p.Editor = cmd.Editor
+		*p = p.WithEditor(cmd.Editor)
+	}
+	if len(cmd.Tag) > 0 {
+		*p = p.WithTags(cmd.Tag...)
+	}
+	if len(cmd.Untag) > 0 {
+		*p = p.WithoutTags(cmd.Untag...)
+	}
+	if cmd.Status == string(catalog.StatusActive) {
+		*p = p.WithStatus("")
+	} else if cmd.Status != "" {
+		*p = p.WithStatus(catalog.Status(cmd.Status))
+	}
+	if len(cmd.TmuxPane) > 0 {
+		*p = p.WithTmuxLayout(&catalog.TmuxLayout{Windows: []catalog.TmuxWindow{{
+			Name:   "main",
+			Layout: "main-vertical",
+			Panes:  cmd.TmuxPane,
+		}}})
 	}
 }
 
@@ -25,16 +47,17 @@ func (cmd *EditCmd) Run(g *Globals) error {
 		return err
 	}
 
-	cmd.applyEdits(&project)
-
-	if err := g.Cat.Update(project); err != nil {
+	err = mutate(g.Cat, func(tx catalog.Tx) error {
+		if project, err = tx.Get(project.ID); err != nil {
+			return err
+		}
+		cmd.applyEdits(&project)
+		return tx.Update(project)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update project %q: %w", project.Name, err)
 	}
 
-	if err := g.Cat.Save(); err != nil {
-		return fmt.Errorf("failed to save catalog: %w", err)
-	}
-
 	fmt.Fprintf(g.Out, "Updated: %s\n", project.Name)
 	return nil
 }
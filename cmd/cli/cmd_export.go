@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"pj/internal/exporter"
+)
+
+type ExportCmd struct {
+	FilterFlags `embed:""`
+
+	To     string `required:"" enum:"json,yaml,csv,vscode-project-manager,markdown" help:"Output format (json, yaml, csv, vscode-project-manager, markdown)"`
+	Output string `short:"o" help:"Write to this file instead of stdout"`
+	All    bool   `short:"a" help:"Include archived projects"`
+}
+
+func (cmd *ExportCmd) Run(g *Globals) error {
+	opts := cmd.Options()
+	opts.ExcludeArchived = !cmd.All
+	projects := g.Cat.Filter(opts)
+
+	if cmd.Output == "" {
+		return exporter.Write(g.Out, exporter.Format(cmd.To), projects)
+	}
+
+	f, err := os.Create(cmd.Output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", cmd.Output, err)
+	}
+	if err := exporter.Write(f, exporter.Format(cmd.To), projects); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to export catalog: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", cmd.Output, err)
+	}
+
+	fmt.Fprintf(g.Out, "Exported %d projects to %s\n", len(projects), cmd.Output)
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"pj/internal/exporter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCmd_Run(t *testing.T) {
+	t.Run("writes filtered projects to stdout", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "api-server")
+		createTestProject(t, g, "api-client")
+		createTestProject(t, g, "dotfiles")
+		out.Reset()
+
+		cmd := ExportCmd{To: "json", FilterFlags: FilterFlags{Query: "api", Sort: "name"}}
+		err := cmd.Run(g)
+
+		require.NoError(t, err)
+		var doc exporter.Document
+		require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+		require.Len(t, doc.Projects, 2)
+		assert.Equal(t, "api-client", doc.Projects[0].Name)
+		assert.Equal(t, "api-server", doc.Projects[1].Name)
+	})
+
+	t.Run("leaves out archived projects unless --all", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		createTestProject(t, g, "old-api")
+		require.NoError(t, (&ArchiveCmd{Name: "old-api"}).Run(g))
+		out.Reset()
+
+		require.NoError(t, (&ExportCmd{To: "json"}).Run(g))
+		var doc exporter.Document
+		require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+		require.Len(t, doc.Projects, 1)
+		assert.Equal(t, "api", doc.Projects[0].Name)
+
+		out.Reset()
+		require.NoError(t, (&ExportCmd{To: "json", All: true}).Run(g))
+		require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+		assert.Len(t, doc.Projects, 2)
+	})
+
+	t.Run("writes to output file", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		out.Reset()
+		dest := filepath.Join(t.TempDir(), "projects.md")
+
+		cmd := ExportCmd{To: "markdown", Output: dest}
+		err := cmd.Run(g)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "| api |")
+		assert.Contains(t, out.String(), "Exported 1 projects to "+dest)
+	})
+
+	t.Run("round-trips through import", func(t *testing.T) {
+		src, _ := newTestGlobals(t)
+		createTestProject(t, src, "api")
+		dest := filepath.Join(t.TempDir(), "catalog.yaml")
+		require.NoError(t, (&ExportCmd{To: "yaml", Output: dest}).Run(src))
+
+		dst, _ := newTestGlobals(t)
+		require.NoError(t, (&ImportCmd{From: "yaml", File: dest}).Run(dst))
+
+		want := src.Cat.List()
+		got := dst.Cat.List()
+		require.Len(t, got, 1)
+		assert.Equal(t, want[0].ID, got[0].ID)
+		assert.True(t, want[0].AddedAt.Equal(got[0].AddedAt))
+	})
+}
+
+func TestListCmd_Filters(t *testing.T) {
+	t.Run("filters by query", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		createTestProject(t, g, "web")
+		out.Reset()
+
+		cmd := ListCmd{Names: true, FilterFlags: FilterFlags{Query: "api"}}
+		err := cmd.Run(g)
+
+		require.NoError(t, err)
+		assert.Equal(t, "api\n", out.String())
+	})
+
+	t.Run("sorts by requested field", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "beta")
+		createTestProject(t, g, "alpha")
+		out.Reset()
+
+		cmd := ListCmd{Names: true, FilterFlags: FilterFlags{Sort: "name", Desc: true}}
+		err := cmd.Run(g)
+
+		require.NoError(t, err)
+		assert.Equal(t, "beta\nalpha\n", out.String())
+	})
+
+	t.Run("filters by tag", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		createTestProject(t, g, "web")
+		require.NoError(t, (&EditCmd{Name: "web", Tag: []string{"frontend"}}).Run(g))
+		out.Reset()
+
+		cmd := ListCmd{Names: true, FilterFlags: FilterFlags{Tag: "frontend"}}
+		err := cmd.Run(g)
+
+		require.NoError(t, err)
+		assert.Equal(t, "web\n", out.String())
+	})
+}
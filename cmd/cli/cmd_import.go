@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"pj/internal/importer"
+)
+
+type ImportCmd struct {
+	From string `required:"" enum:"vscode,projectile,zoxide,ghq,plain,json,yaml" help:"Source format (vscode, projectile, zoxide, ghq, plain, json, yaml)"`
+	File string `arg:"" help:"Bookmark file, ghq root directory, or - for stdin"`
+}
+
+func (cmd *ImportCmd) Run(g *Globals) error {
+	entries, err := importer.Read(importer.Source(cmd.From), cmd.File, os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read %s bookmarks: %w", cmd.From, err)
+	}
+
+	report, err := importer.Apply(g.Cat.Mutate, entries)
+	if err != nil {
+		return fmt.Errorf("failed to import: %w", err)
+	}
+
+	writeImportReport(g, report)
+	return nil
+}
+
+func writeImportReport(g *Globals, report importer.Report) {
+	for _, res := range report.Results {
+		switch res.Outcome {
+		case importer.OutcomeImported:
+			fmt.Fprintf(g.Out, "  + %s (%s)\n", res.Entry.Name, res.Entry.Path)
+		case importer.OutcomeSkipped:
+			fmt.Fprintf(g.Out, "  = %s: %s\n", res.Entry.Path, res.Reason)
+		case importer.OutcomeInvalid:
+			fmt.Fprintf(g.Out, "  ! %s: %s\n", res.Entry.Path, res.Reason)
+		}
+	}
+	fmt.Fprintf(g.Out, "Imported: %d, skipped: %d, invalid: %d\n",
+		report.Count(importer.OutcomeImported),
+		report.Count(importer.OutcomeSkipped),
+		report.Count(importer.OutcomeInvalid))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCmd_Run(t *testing.T) {
+	t.Run("imports plain path list and prints report", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		existing := createTestProject(t, g, "existing")
+		fresh := t.TempDir()
+		list := filepath.Join(t.TempDir(), "paths.txt")
+		content := strings.Join([]string{fresh, existing, "/nonexistent/path"}, "\n")
+		require.NoError(t, os.WriteFile(list, []byte(content), 0o644))
+		out.Reset()
+
+		err := (&ImportCmd{From: "plain", File: list}).Run(g)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, g.Cat.Count())
+		output := out.String()
+		assert.Contains(t, output, "+ "+filepath.Base(fresh))
+		assert.Contains(t, output, "= "+existing)
+		assert.Contains(t, output, "! /nonexistent/path")
+		assert.Contains(t, output, "Imported: 1, skipped: 1, invalid: 1")
+	})
+
+	t.Run("persists imported projects", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "github.com", "acme", "api", ".git"), 0o755))
+
+		err := (&ImportCmd{From: "ghq", File: root}).Run(g)
+
+		require.NoError(t, err)
+		projects := openTestCatalog(t, g.CatalogPath).List()
+		require.Len(t, projects, 1)
+		assert.Equal(t, "api", projects[0].Name)
+	})
+
+	t.Run("returns error for unreadable file", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+
+		err := (&ImportCmd{From: "vscode", File: "/nonexistent/projects.json"}).Run(g)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read vscode bookmarks")
+	})
+}
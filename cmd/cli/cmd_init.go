@@ -1,49 +1,74 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
-type InitCmd struct{}
+type InitCmd struct {
+	Heartbeat bool `help:"Log the shell's working directory at each prompt for 'pj report'"`
+}
 
 func (cmd *InitCmd) Run(g *Globals) error { //nolint:unparam // error required by kong interface
 	fmt.Fprint(g.Out, shellScript)
+	if cmd.Heartbeat {
+		// The script's printf format would trip vet's check on Fprint.
+		_, _ = io.WriteString(g.Out, heartbeatScript)
+	}
 	return nil
 }
 
 const shellScript = `# pj shell integration
 # Add to ~/.bashrc or ~/.zshrc: eval "$(pj init)"
+# Use 'pj init --heartbeat' to also log activity for 'pj report'.
+
+# Identifies this shell so 'pj cd -' returns to its previous project.
+__PJ_SESSION="${__PJ_SESSION:-$$-$RANDOM}"
 
+# Any subcommand can change the shell's directory by writing a path to
+# $__PJ_CD_FILE.
 pj() {
-    case "$1" in
-        cd)
-            if [ -z "$2" ]; then
-                echo "Usage: pj cd <project>" >&2
-                return 1
-            fi
-            dir="$(command pj show "$2" --path 2>&1)"
-            if [ $? -ne 0 ]; then
-                echo "pj: $dir" >&2
-                return 1
-            fi
-            if [ ! -d "$dir" ]; then
-                echo "pj: path no longer exists: $dir" >&2
-                return 1
-            fi
-            builtin cd -- "$dir"
-            ;;
-        create|new)
-            local cdfile
-            cdfile="$(mktemp "${TMPDIR:-/tmp}/pj-cd.XXXXXX")"
-            __PJ_CD_FILE="$cdfile" command pj create "${@:2}"
-            local rc=$?
-            if [ $rc -eq 0 ] && [ -s "$cdfile" ]; then
-                builtin cd -- "$(cat "$cdfile")"
-            fi
-            rm -f "$cdfile"
-            return $rc
-            ;;
-        *)
-            command pj "$@"
-            ;;
-    esac
+    local cdfile rc
+    cdfile="$(mktemp "${TMPDIR:-/tmp}/pj-cd.XXXXXX")" || return 1
+    __PJ_SESSION="$__PJ_SESSION" __PJ_CD_FILE="$cdfile" command pj "$@"
+    rc=$?
+    if [ $rc -eq 0 ] && [ -s "$cdfile" ]; then
+        builtin cd -- "$(cat "$cdfile")"
+        rc=$?
+    fi
+    rm -f "$cdfile"
+    return $rc
 }
 `
+
+// heartbeatScript logs the working directory whenever a prompt is shown, at
+// most once a minute while it stays the same, for 'pj report'. It is opt-in
+// since it records where the user works; 'pj report' prunes the log. Only
+// shells too old for $EPOCHSECONDS start a process at the prompt to do so.
+const heartbeatScript = `
+__PJ_HEARTBEAT_LOG="${__PJ_HEARTBEAT_LOG:-${XDG_STATE_HOME:-$HOME/.local/state}/pj/heartbeats.log}"
+mkdir -p -- "${__PJ_HEARTBEAT_LOG%/*}" 2>/dev/null
+
+__pj_heartbeat() {
+    local now="${EPOCHSECONDS:-}"
+    [ -n "$now" ] || now="$(date +%s)"
+    if [ "$PWD" = "${__PJ_LAST_PWD:-}" ] && [ $((now - ${__PJ_LAST_BEAT:-0})) -lt 60 ]; then
+        return 0
+    fi
+    __PJ_LAST_PWD="$PWD"
+    __PJ_LAST_BEAT="$now"
+    printf '%s\t%s\n' "$now" "$PWD" >> "$__PJ_HEARTBEAT_LOG" 2>/dev/null
+    return 0
+}
+
+if [ -n "${ZSH_VERSION:-}" ]; then
+    zmodload zsh/datetime 2>/dev/null
+    autoload -Uz add-zsh-hook
+    add-zsh-hook precmd __pj_heartbeat
+elif [ -n "${BASH_VERSION:-}" ]; then
+    case ";${PROMPT_COMMAND:-};" in
+        *";__pj_heartbeat;"*) ;;
+        *) PROMPT_COMMAND="__pj_heartbeat${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+    esac
+fi
+`
@@ -4,20 +4,27 @@ import (
 	"fmt"
 	"os"
 	"pj/cmd/cli/render"
+	"pj/internal/catalog"
 	"slices"
 	"time"
 )
 
 type ListCmd struct {
+	FilterFlags `embed:""`
+
 	Names bool `short:"n" help:"Output only project names (one per line)"`
+	All   bool `short:"a" help:"Include archived projects"`
 }
 
 func (cmd *ListCmd) Run(g *Globals) error {
-	projects := g.Cat.List()
+	opts := cmd.Options()
+	opts.ExcludeArchived = !cmd.All
+	projects := g.Cat.Filter(opts)
 
 	if cmd.Names {
+		all := g.Cat.List()
 		for _, p := range projects {
-			fmt.Fprintln(g.Out, p.Name)
+			fmt.Fprintln(g.Out, qualifiedName(all, p))
 		}
 		return nil
 	}
@@ -29,18 +36,75 @@ func (cmd *ListCmd) Run(g *Globals) error {
 			Path:        p.Path,
 			Description: p.Description,
 			Timestamp:   getMtime(p.Path),
+			Unavailable: p.Unavailable,
+			Languages:   p.Languages(),
+			Status:      string(p.Status),
 		}
 	}
-	slices.SortFunc(items, func(a, b render.ProjectListItem) int {
-		return b.Timestamp.Compare(a.Timestamp)
-	})
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	if cmd.Sort == "" {
+		slices.SortStableFunc(order, func(a, b int) int {
+			return items[b].Timestamp.Compare(items[a].Timestamp)
+		})
+	}
 
-	view := render.ProjectListView{Items: items}
+	view := render.ProjectListView{Items: nestItems(projects, items, order)}
 	output := g.Render.RenderProjectList(view)
 	_, err := fmt.Fprint(g.Out, output)
 	return err
 }
 
+// nestItems lists each sub-project right after its parent, indented, when
+// both are listed. order is the listing order of the top-level items.
+func nestItems(projects []catalog.Project, items []render.ProjectListItem, order []int) []render.ProjectListItem {
+	listed := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		listed[p.ID] = true
+	}
+	children := make(map[string][]int)
+	var roots []int
+	for _, i := range order {
+		if parent := projects[i].Parent; parent != "" && listed[parent] {
+			children[parent] = append(children[parent], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	nested := make([]render.ProjectListItem, 0, len(items))
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		item := items[i]
+		item.Depth = depth
+		nested = append(nested, item)
+		for _, c := range children[projects[i].ID] {
+			walk(c, depth+1)
+		}
+	}
+	for _, i := range roots {
+		walk(i, 0)
+	}
+	return nested
+}
+
+// qualifiedName prefixes a sub-project's name with its parents', as in
+// "mono/api", which resolves back to it unambiguously.
+func qualifiedName(all []catalog.Project, p catalog.Project) string {
+	name := p.Name
+	for seen := 0; p.Parent != "" && seen < len(all); seen++ {
+		i := slices.IndexFunc(all, func(c catalog.Project) bool { return c.ID == p.Parent })
+		if i < 0 {
+			break
+		}
+		p = all[i]
+		name = p.Name + "/" + name
+	}
+	return name
+}
+
 func getMtime(path string) time.Time {
 	if info, err := os.Stat(path); err == nil {
 		return info.ModTime()
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/notes"
+	"strings"
+	"time"
+)
+
+// showNotes is how many of the latest notes `pj show` prints.
+const showNotes = 3
+
+type NoteCmd struct {
+	Name string   `arg:"" help:"Project name" completion:"pj list -n"`
+	Text []string `arg:"" optional:"" help:"Note to append (default: print the project's notes)"`
+	Edit bool     `short:"e" help:"Edit the project's notes file in $EDITOR"`
+}
+
+func (cmd *NoteCmd) Run(g *Globals) error {
+	project, err := findProject(g.Cat, cmd.Name)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+	store := notes.ForCatalog(g.CatalogPath)
+
+	if len(cmd.Text) > 0 {
+		if err := store.Append(project.ID, time.Now(), strings.Join(cmd.Text, " ")); err != nil {
+			return err
+		}
+		if !cmd.Edit {
+			fmt.Fprintf(g.Out, "Noted: %s\n", project.Name)
+			return nil
+		}
+	}
+	if cmd.Edit {
+		return editNotes(g, store, project)
+	}
+
+	list, err := store.Read(project.ID)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Fprintf(g.Out, "No notes for %s. Add one with: pj note %s <text>\n", project.Name, project.Name)
+		return nil
+	}
+	for i, n := range list {
+		if i > 0 {
+			fmt.Fprintln(g.Out)
+		}
+		fmt.Fprintln(g.Out, n.Time.Format("2006-01-02 15:04"))
+		writeIndented(g.Out, "  ", n.Text)
+	}
+	return nil
+}
+
+// editNotes opens the project's notes file in $EDITOR, starting it with a
+// title when there is none yet.
+func editNotes(g *Globals, store *notes.Store, project catalog.Project) error {
+	path := store.Path(project.ID)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create notes directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte("# "+project.Name+"\n\n"), 0o644); err != nil {
+			return fmt.Errorf("failed to create notes: %w", err)
+		}
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	args := splitCommand(editor)
+	runCmd := g.RunCmd
+	if runCmd == nil {
+		runCmd = defaultRunCmd
+	}
+	return runCmd(args[0], append(args[1:], path)...)
+}
+
+type NotesCmd struct {
+	Since string `default:"1w" help:"Only notes since an age (3d, 2w), a date, today, yesterday or a weekday"`
+}
+
+// Run prints a journal of every project's notes, grouped by day.
+func (cmd *NotesCmd) Run(g *Globals) error {
+	since, err := parseSince(cmd.Since, time.Now())
+	if err != nil {
+		return err
+	}
+	list, err := notes.ForCatalog(g.CatalogPath).Since(since)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Fprintln(g.Out, "No notes.")
+		return nil
+	}
+
+	day := ""
+	for _, n := range list {
+		if d := n.Time.Format("2006-01-02 Mon"); d != day {
+			if day != "" {
+				fmt.Fprintln(g.Out)
+			}
+			fmt.Fprintln(g.Out, d)
+			day = d
+		}
+		name := "(removed project)"
+		if p, err := g.Cat.Get(n.ProjectID); err == nil {
+			name = p.Name
+		}
+		fmt.Fprintf(g.Out, "  %s  %s\n", n.Time.Format("15:04"), name)
+		writeIndented(g.Out, "    ", n.Text)
+	}
+	return nil
+}
+
+func writeIndented(w io.Writer, indent, text string) {
+	for line := range strings.Lines(text) {
+		if line != "\n" {
+			line = indent + line
+		}
+		fmt.Fprint(w, line)
+	}
+	if !strings.HasSuffix(text, "\n") {
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"pj/internal/catalog"
+	"pj/internal/notes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoteCmd_Run(t *testing.T) {
+	t.Run("appends and prints notes", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		out.Reset()
+
+		require.NoError(t, (&NoteCmd{Name: "api", Text: []string{"switched", "to", "pgx"}}).Run(g))
+		assert.Equal(t, "Noted: api\n", out.String())
+
+		out.Reset()
+		require.NoError(t, (&NoteCmd{Name: "api"}).Run(g))
+		assert.Regexp(t, `^\d{4}-\d\d-\d\d \d\d:\d\d\n  switched to pgx\n$`, out.String())
+	})
+
+	t.Run("keeps notes across renames", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		require.NoError(t, (&NoteCmd{Name: "api", Text: []string{"hello"}}).Run(g))
+		p, err := findProject(g.Cat, "api")
+		require.NoError(t, err)
+		p.Name = "backend"
+		mutateCatalog(t, g, func(tx catalog.Tx) error { return tx.Update(p) })
+		out.Reset()
+
+		require.NoError(t, (&NoteCmd{Name: "backend"}).Run(g))
+		assert.Contains(t, out.String(), "hello")
+	})
+
+	t.Run("edit opens the notes file in EDITOR", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		t.Setenv("EDITOR", "nvim -u NONE")
+		createTestProject(t, g, "api")
+		var ran []string
+		g.RunCmd = func(name string, args ...string) error {
+			ran = append([]string{name}, args...)
+			return nil
+		}
+
+		require.NoError(t, (&NoteCmd{Name: "api", Edit: true}).Run(g))
+
+		p, err := findProject(g.Cat, "api")
+		require.NoError(t, err)
+		path := notes.ForCatalog(g.CatalogPath).Path(p.ID)
+		assert.Equal(t, []string{"nvim", "-u", "NONE", path}, ran)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "# api\n\n", string(data))
+	})
+}
+
+func TestShowCmd_Notes(t *testing.T) {
+	g, out := newTestGlobals(t)
+	createTestProject(t, g, "api")
+	p, err := findProject(g.Cat, "api")
+	require.NoError(t, err)
+	store := notes.ForCatalog(g.CatalogPath)
+	for i, text := range []string{"one", "two", "three", "four\nmore"} {
+		require.NoError(t, store.Append(p.ID, time.Date(2026, 10, 1+i, 9, 0, 0, 0, time.Local), text))
+	}
+	out.Reset()
+
+	require.NoError(t, (&ShowCmd{Name: "api"}).Run(g))
+
+	assert.Contains(t, out.String(), "Notes:  4, latest first\n"+
+		"  2026-10-04 09:00  four\n"+
+		"  2026-10-03 09:00  three\n"+
+		"  2026-10-02 09:00  two\n")
+	assert.NotContains(t, out.String(), "one")
+}
+
+func TestNotesCmd_Run(t *testing.T) {
+	g, out := newTestGlobals(t)
+	createTestProject(t, g, "api")
+	createTestProject(t, g, "web")
+	api, _ := findProject(g.Cat, "api")
+	web, _ := findProject(g.Cat, "web")
+	store := notes.ForCatalog(g.CatalogPath)
+	today := time.Now()
+	require.NoError(t, store.Append(api.ID, today.AddDate(0, 0, -30), "ancient"))
+	require.NoError(t, store.Append(api.ID, today.Add(-2*time.Minute), "api work\nsecond line"))
+	require.NoError(t, store.Append(web.ID, today.Add(-time.Minute), "web work"))
+	require.NoError(t, store.Append("gone", today.Add(-time.Minute), "orphan"))
+	out.Reset()
+
+	require.NoError(t, (&NotesCmd{Since: "1w"}).Run(g))
+
+	output := out.String()
+	assert.NotContains(t, output, "ancient")
+	assert.Contains(t, output, "api\n    api work\n    second line\n")
+	assert.Contains(t, output, "web\n    web work\n")
+	assert.Contains(t, output, "(removed project)\n    orphan\n")
+	assert.Less(t, strings.Index(output, "api work"), strings.Index(output, "web work"))
+
+	require.Error(t, (&NotesCmd{Since: "soon"}).Run(g))
+}
+
+func TestParseSince(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"90m", now.Add(-90 * time.Minute)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"3d", time.Date(2026, 10, 11, 15, 30, 0, 0, time.Local)},
+		{"1w", time.Date(2026, 10, 7, 15, 30, 0, 0, time.Local)},
+		{"today", time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)},
+		{"yesterday", time.Date(2026, 10, 13, 0, 0, 0, 0, time.Local)},
+		{"Monday", time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)},
+		{"wed", time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)},
+		{"thursday", time.Date(2026, 10, 8, 0, 0, 0, 0, time.Local)},
+		{"2026-09-30", time.Date(2026, 9, 30, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSince(tt.in, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, bad := range []string{"", "soon", "-3d", "d"} {
+		_, err := parseSince(bad, now)
+		assert.Error(t, err, bad)
+	}
+}
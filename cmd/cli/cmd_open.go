@@ -1,16 +1,21 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"pj/internal/catalog"
 )
 
 type OpenCmd struct {
-	Name string `arg:"" help:"Project name or partial match" completion:"pj list -n"`
+	Name string `arg:"" help:"Project name or partial match, or project@worktree" completion:"pj list -n"`
+	File string `arg:"" optional:"" help:"File to open, relative to the project, as file[:line[:column]]"`
+	With string `help:"Editor profile or command to use instead of the configured one" placeholder:"PROFILE"`
+	Last bool   `help:"Reopen the file last opened in this project"`
 }
 
 func (cmd *OpenCmd) Run(g *Globals) error {
-	project, err := findProject(g.Cat, cmd.Name)
+	project, dir, err := findProjectDir(g.Cat, cmd.Name)
 	if err != nil {
 		if handleFindError(g.Out, err) {
 			return nil
@@ -18,27 +23,63 @@ func (cmd *OpenCmd) Run(g *Globals) error {
 		return err
 	}
 
-	if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+	var target fileTarget
+	switch {
+	case cmd.Last && cmd.File != "":
+		return errors.New("--last cannot be combined with a file")
+	case cmd.Last:
+		var ok bool
+		if target, ok = loadLastFiles()[project.ID]; !ok {
+			return fmt.Errorf("no file has been opened in %s yet", project.Name)
+		}
+	case cmd.File != "":
+		if target, err = parseFileTarget(dir, cmd.File); err != nil {
+			return err
+		}
+	}
+	return openProject(g, project, openRequest{With: cmd.With, Dir: dir, File: target})
+}
+
+// openRequest says what openProject opens. The zero value opens the
+// project directory in the configured editor.
+type openRequest struct {
+	With string
+	// Dir replaces the project path, e.g. with one of its worktrees.
+	Dir  string
+	File fileTarget
+}
+
+func openProject(g *Globals, project catalog.Project, req openRequest) error {
+	dir := req.Dir
+	if dir == "" {
+		dir = project.Path
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if dir != project.Path {
+			return fmt.Errorf("worktree path no longer exists: %s", dir)
+		}
 		return fmt.Errorf("project path no longer exists: %s\nRun 'pj rm %s' to remove from catalog",
 			project.Path, project.Name)
 	}
 
-	editor, err := resolveEditor(project)
+	editor, err := resolveEditor(g.Config.Editors, project, req.With)
 	if err != nil {
 		return err
 	}
 
-	project.Touch()
-	if err := g.Cat.Update(project); err != nil {
+	if _, err := g.Cat.Touch(project.ID); err != nil {
 		return fmt.Errorf("failed to update project %q: %w", project.Name, err)
 	}
-	if err := g.Cat.Save(); err != nil {
-		return fmt.Errorf("failed to save catalog: %w", err)
+
+	args := []string{dir}
+	if req.File.Path != "" {
+		recordLastFile(project.ID, req.File)
+		args = fileArgs(editor[0], dir, req.File)
 	}
 
 	runCmd := g.RunCmd
 	if runCmd == nil {
 		runCmd = defaultRunCmd
 	}
-	return runCmd(editor[0], append(editor[1:], project.Path)...)
+	return runCmd(editor[0], append(editor[1:], args...)...)
 }
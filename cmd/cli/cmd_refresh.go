@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"pj/internal/catalog"
+	"pj/internal/detect"
+	"pj/internal/git"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type RefreshCmd struct {
+	Name string `arg:"" optional:"" help:"Project to refresh (default: every project)" completion:"pj list -n"`
+}
+
+func (cmd *RefreshCmd) Run(g *Globals) error {
+	var target catalog.Project
+	if cmd.Name != "" {
+		project, err := findProject(g.Cat, cmd.Name)
+		if err != nil {
+			if handleFindError(g.Out, err) {
+				return nil
+			}
+			return err
+		}
+		target = project
+	}
+
+	var targets, changed []catalog.Project
+	var notes []string
+	gone := map[string]bool{}
+	err := mutate(g.Cat, func(tx catalog.Tx) error {
+		targets = tx.List()
+		if target.ID != "" {
+			p, err := tx.Get(target.ID)
+			if err != nil {
+				return err
+			}
+			targets = []catalog.Project{p}
+		}
+		for _, p := range targets {
+			if gone[p.ID] {
+				continue
+			}
+			// Projects that are not on this host keep what was detected
+			// where they are.
+			if _, err := os.Stat(p.Path); p.Unavailable || err != nil {
+				continue
+			}
+			stack := detect.Dir(p.Path)
+			remote := p.Remote
+			if u, err := git.ParseRemote(remote); err == nil {
+				remote = u.String()
+			}
+			if origin := originOf(p.Path); origin != "" {
+				remote = origin
+			}
+			worktrees := p.Worktrees
+			if detected, ok := detectWorktrees(p.Path); ok {
+				worktrees = detected
+			}
+			added, removed, err := syncSubprojects(tx, g.Config, p)
+			if err != nil {
+				return err
+			}
+			for _, c := range added {
+				notes = append(notes, fmt.Sprintf("Added sub-project: %s/%s (%s)", p.Name, c.Name, c.Path))
+			}
+			for _, c := range removed {
+				gone[c.ID] = true
+				notes = append(notes, fmt.Sprintf("Removed sub-project: %s/%s", p.Name, c.Name))
+			}
+			if reflect.DeepEqual(stack, p.Stack) && remote == p.Remote && slices.Equal(worktrees, p.Worktrees) {
+				continue
+			}
+			p = p.WithStack(stack).WithRemote(remote).WithWorktrees(worktrees)
+			if err := tx.Update(p); err != nil {
+				return err
+			}
+			changed = append(changed, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh: %w", err)
+	}
+
+	for _, p := range changed {
+		langs := strings.Join(p.Languages(), ", ")
+		if langs == "" {
+			langs = "no languages detected"
+		}
+		fmt.Fprintf(g.Out, "Updated: %s (%s)\n", p.Name, langs)
+	}
+	for _, note := range notes {
+		fmt.Fprintln(g.Out, note)
+	}
+	fmt.Fprintf(g.Out, "Refreshed %d projects, %d changed\n", len(targets), len(changed))
+	return nil
+}
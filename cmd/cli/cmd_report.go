@@ -0,0 +1,177 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pj/internal/activity"
+	"pj/internal/catalog"
+	"pj/internal/config"
+	"pj/pkg/pj"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// heartbeatEnv overrides where the shell integration logs heartbeats.
+const heartbeatEnv = "__PJ_HEARTBEAT_LOG"
+
+func heartbeatLogPath() string {
+	if path := os.Getenv(heartbeatEnv); path != "" {
+		return path
+	}
+	return filepath.Join(config.DefaultStateDir(), "heartbeats.log")
+}
+
+type ReportCmd struct {
+	Since string        `default:"monday" help:"Start of the report, at most a year ago: an age (3d, 2w), a date, today, yesterday or a weekday"`
+	By    string        `enum:"project,day,tag" default:"project" help:"Group time by project, day or tag"`
+	Idle  time.Duration `default:"15m" help:"Gaps between shell prompts longer than this count as idle"`
+	CSV   bool          `name:"csv" help:"Output CSV"`
+}
+
+// reportRow is the time spent on one project, day or tag.
+type reportRow struct {
+	Key      string
+	Duration time.Duration
+}
+
+func (cmd *ReportCmd) Run(g *Globals) error {
+	now := time.Now()
+	since, err := parseSince(cmd.Since, now)
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-activity.Retention)
+	if since.Before(cutoff) {
+		return fmt.Errorf("--since can go back at most %d days: older shell activity is not kept", int(activity.Retention.Hours()/24))
+	}
+	if cmd.Idle <= 0 {
+		return errors.New("--idle must be positive")
+	}
+
+	path := heartbeatLogPath()
+	err = activity.Prune(path, cutoff)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(g.Out, "No shell activity recorded yet. Enable it with: eval \"$(pj init --heartbeat)\"")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prune heartbeats: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read heartbeats: %w", err)
+	}
+	defer f.Close()
+	beats, err := activity.Read(f, since)
+	if err != nil {
+		return fmt.Errorf("failed to read heartbeats: %w", err)
+	}
+
+	rows := cmd.group(g.Cat, activity.Spans(beats, cmd.Idle))
+	if cmd.CSV {
+		return writeReportCSV(g, cmd.By, rows)
+	}
+	if len(rows) == 0 {
+		fmt.Fprintf(g.Out, "No time recorded in projects since %s.\n", since.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	width := len(cmd.By)
+	for _, r := range rows {
+		width = max(width, len(r.Key))
+	}
+	fmt.Fprintf(g.Out, "%-*s  %s\n", width, strings.ToUpper(cmd.By), "TIME")
+	var total time.Duration
+	for _, r := range rows {
+		fmt.Fprintf(g.Out, "%-*s  %s\n", width, r.Key, formatHours(r.Duration))
+		total += r.Duration
+	}
+	// Time in a project with several tags counts towards each of them.
+	if cmd.By != "tag" {
+		fmt.Fprintf(g.Out, "%-*s  %s\n", width, "TOTAL", formatHours(total))
+	}
+	return nil
+}
+
+// group attributes each span to the project its directory is in and adds
+// it up by project, day or tag. Time outside any project is left out.
+func (cmd *ReportCmd) group(cat pj.Store, spans []activity.Span) []reportRow {
+	projects := make(map[string]*catalog.Project)
+	totals := make(map[string]time.Duration)
+	for _, s := range spans {
+		p, seen := projects[s.Dir]
+		if !seen {
+			if found, err := pj.ResolveDir(cat, s.Dir); err == nil {
+				p = &found
+			}
+			projects[s.Dir] = p
+		}
+		if p == nil {
+			continue
+		}
+
+		switch cmd.By {
+		case "day":
+			totals[s.Start.Format("2006-01-02 Mon")] += s.Duration
+		case "tag":
+			if len(p.Tags) == 0 {
+				totals["(untagged)"] += s.Duration
+			}
+			for _, tag := range p.Tags {
+				totals[tag] += s.Duration
+			}
+		default:
+			totals[p.Name] += s.Duration
+		}
+	}
+
+	rows := make([]reportRow, 0, len(totals))
+	for key, d := range totals {
+		rows = append(rows, reportRow{Key: key, Duration: d})
+	}
+	slices.SortFunc(rows, func(a, b reportRow) int {
+		if cmd.By == "day" {
+			return strings.Compare(a.Key, b.Key)
+		}
+		return cmp.Or(cmp.Compare(b.Duration, a.Duration), strings.Compare(a.Key, b.Key))
+	})
+	return rows
+}
+
+func writeReportCSV(g *Globals, by string, rows []reportRow) error {
+	cw := csv.NewWriter(g.Out)
+	if err := cw.Write([]string{by, "seconds", "hours"}); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		key := r.Key
+		if by == "day" {
+			key, _, _ = strings.Cut(key, " ")
+		}
+		err := cw.Write([]string{
+			key,
+			strconv.FormatInt(int64(r.Duration/time.Second), 10),
+			strconv.FormatFloat(r.Duration.Hours(), 'f', 2, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatHours renders d to the minute, as in "3h 05m".
+func formatHours(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"pj/internal/activity"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHeartbeats logs a heartbeat in dirs[i] every minute from start.
+func writeHeartbeats(t *testing.T, start time.Time, dirs ...string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "heartbeats.log")
+	t.Setenv(heartbeatEnv, path)
+	var sb strings.Builder
+	for i, dir := range dirs {
+		fmt.Fprintf(&sb, "%d\t%s\n", start.Add(time.Duration(i)*time.Minute).Unix(), dir)
+	}
+	require.NoError(t, os.WriteFile(path, []byte(sb.String()), 0o644))
+}
+
+func TestReportCmd_Run(t *testing.T) {
+	g, out := newTestGlobals(t)
+	api := createTestProject(t, g, "api")
+	web := createTestProject(t, g, "web")
+	require.NoError(t, (&EditCmd{Name: "api", Tag: []string{"client-a"}}).Run(g))
+	// A Monday a few weeks back, well within the log's retention.
+	now := time.Now()
+	monday := time.Date(now.Year(), now.Month(), now.Day()-21-int(now.Weekday()+6)%7, 9, 0, 0, 0, time.Local)
+	tuesday := monday.AddDate(0, 0, 1)
+	sunday := monday.AddDate(0, 0, -1).Format("2006-01-02")
+	writeHeartbeats(t, monday, api, api+"/internal", api, web, web, "/tmp", "/tmp")
+	log, err := os.ReadFile(os.Getenv(heartbeatEnv))
+	require.NoError(t, err)
+	// A second session the next day, after a long idle gap.
+	more := fmt.Sprintf("%d\t%s\n%d\t%s\n", tuesday.Unix(), web, tuesday.Add(10*time.Minute).Unix(), web)
+	require.NoError(t, os.WriteFile(os.Getenv(heartbeatEnv), append(log, more...), 0o644))
+	out.Reset()
+
+	t.Run("by project", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, (&ReportCmd{Since: sunday, By: "project", Idle: 15 * time.Minute}).Run(g))
+		assert.Equal(t, "PROJECT  TIME\n"+
+			"web      12m\n"+
+			"api      3m\n"+
+			"TOTAL    15m\n", out.String())
+	})
+
+	t.Run("by day", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, (&ReportCmd{Since: sunday, By: "day", Idle: 15 * time.Minute}).Run(g))
+		assert.Equal(t, "DAY             TIME\n"+
+			monday.Format("2006-01-02 Mon")+"  5m\n"+
+			tuesday.Format("2006-01-02 Mon")+"  10m\n"+
+			"TOTAL           15m\n", out.String())
+	})
+
+	t.Run("by tag", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, (&ReportCmd{Since: sunday, By: "tag", Idle: 15 * time.Minute}).Run(g))
+		assert.Equal(t, "TAG         TIME\n"+
+			"(untagged)  12m\n"+
+			"client-a    3m\n", out.String())
+	})
+
+	t.Run("a shorter idle gap drops the long span", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, (&ReportCmd{Since: sunday, By: "project", Idle: 5 * time.Minute, CSV: true}).Run(g))
+		assert.Equal(t, "project,seconds,hours\n"+
+			"api,180,0.05\n"+
+			"web,120,0.03\n", out.String())
+	})
+
+	t.Run("csv by day uses plain dates", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, (&ReportCmd{Since: tuesday.Format("2006-01-02"), By: "day", Idle: 15 * time.Minute, CSV: true}).Run(g))
+		assert.Equal(t, "day,seconds,hours\n"+tuesday.Format("2006-01-02")+",600,0.17\n", out.String())
+	})
+
+	t.Run("cannot go back further than the log is kept", func(t *testing.T) {
+		err := (&ReportCmd{Since: "60w", By: "project", Idle: 15 * time.Minute}).Run(g)
+
+		assert.ErrorContains(t, err, "--since can go back at most 366 days")
+	})
+}
+
+func TestReportCmd_PrunesOldHeartbeats(t *testing.T) {
+	g, _ := newTestGlobals(t)
+	old := time.Now().Add(-activity.Retention - time.Hour)
+	writeHeartbeats(t, old, "/src/old")
+	path := os.Getenv(heartbeatEnv)
+	recent := fmt.Sprintf("%d\t/src/new\n", time.Now().Unix())
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(recent)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, (&ReportCmd{Since: "today", By: "project", Idle: 15 * time.Minute}).Run(g))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, recent, string(data))
+}
+
+func TestReportCmd_NoLog(t *testing.T) {
+	g, out := newTestGlobals(t)
+	t.Setenv(heartbeatEnv, filepath.Join(t.TempDir(), "missing.log"))
+
+	require.NoError(t, (&ReportCmd{Since: "monday", By: "project", Idle: 15 * time.Minute}).Run(g))
+
+	assert.Contains(t, out.String(), "No shell activity recorded yet")
+}
+
+func TestFormatHours(t *testing.T) {
+	assert.Equal(t, "0m", formatHours(20*time.Second))
+	assert.Equal(t, "59m", formatHours(59*time.Minute))
+	assert.Equal(t, "3h 05m", formatHours(3*time.Hour+5*time.Minute))
+}
+
+func TestInitCmd_Heartbeat(t *testing.T) {
+	t.Run("is off by default", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		require.NoError(t, (&InitCmd{}).Run(g))
+		assert.NotContains(t, out.String(), "__pj_heartbeat")
+	})
+
+	t.Run("is added by --heartbeat", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		require.NoError(t, (&InitCmd{Heartbeat: true}).Run(g))
+		assert.Contains(t, out.String(), "__pj_heartbeat")
+	})
+
+	t.Run("logs the directory at most once a minute", func(t *testing.T) {
+		bash, err := exec.LookPath("bash")
+		if err != nil {
+			t.Skip("bash not installed")
+		}
+		log := filepath.Join(t.TempDir(), "state", "heartbeats.log")
+		a, b := t.TempDir(), t.TempDir()
+
+		script := heartbeatScript + "cd " + a + " && __pj_heartbeat && __pj_heartbeat && cd " + b + " && __pj_heartbeat\n" +
+			`case "$PROMPT_COMMAND" in __pj_heartbeat*) ;; *) exit 1 ;; esac` + "\n"
+		cmd := exec.Command(bash, "-c", script)
+		cmd.Env = append(os.Environ(), heartbeatEnv+"="+log)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		data, err := os.ReadFile(log)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2)
+		assert.Regexp(t, `^\d+\t`+regexp.QuoteMeta(a)+`$`, lines[0])
+		assert.Regexp(t, `^\d+\t`+regexp.QuoteMeta(b)+`$`, lines[1])
+	})
+}
@@ -1,6 +1,9 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"pj/internal/catalog"
+)
 
 type RmCmd struct {
 	Name string `arg:"" help:"Project name or path to remove" completion:"pj list -n"`
@@ -15,14 +18,24 @@ func (cmd *RmCmd) Run(g *Globals) error {
 		return err
 	}
 
-	if err := g.Cat.Remove(project.ID); err != nil {
-		return fmt.Errorf("failed to remove project %q: %w", project.Name, err)
-	}
-
-	if err := g.Cat.Save(); err != nil {
-		return fmt.Errorf("failed to save catalog: %w", err)
+	// Sub-projects only exist as part of their monorepo.
+	var children []catalog.Project
+	err = mutate(g.Cat, func(tx catalog.Tx) error {
+		children = descendants(tx, project.ID)
+		for _, p := range append(children, project) {
+			if err := tx.Remove(p.ID); err != nil {
+				return fmt.Errorf("failed to remove project %q: %w", p.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	fmt.Fprintf(g.Out, "Removed: %s\n", project.Name)
+	if len(children) > 0 {
+		fmt.Fprintf(g.Out, "Removed %d sub-projects\n", len(children))
+	}
 	return nil
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"pj/cmd/cli/render"
+	"pj/internal/config"
+	"pj/internal/server"
+	"pj/pkg/pj"
+	"syscall"
+	"time"
+)
+
+const serveShutdownTimeout = 5 * time.Second
+
+type ServeCmd struct {
+	Listen    string `default:"127.0.0.1:7777" help:"Address to listen on: host:port or unix:/path/to.sock"`
+	TokenFile string `type:"path" help:"Require the bearer token stored in this file; without one the API is read-only and only listens on loopback"`
+}
+
+func (cmd *ServeCmd) Run(g *Globals) error {
+	var token string
+	if cmd.TokenFile != "" {
+		var err error
+		if token, err = server.ReadToken(cmd.TokenFile); err != nil {
+			return fmt.Errorf("failed to read token: %w", err)
+		}
+	}
+
+	ln, err := server.Listen(cmd.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cmd.Listen, err)
+	}
+	// Clients on other hosts must present the token, so without one there
+	// is no reason to listen beyond loopback.
+	if addr, ok := ln.Addr().(*net.TCPAddr); ok && !addr.IP.IsLoopback() && token == "" {
+		_ = ln.Close()
+		return fmt.Errorf("listening on %s, which is not a loopback address, needs --token-file", cmd.Listen)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Watching keeps reads in step with changes made by the CLI.
+	go func() {
+		for range g.Cat.Watch(ctx) {
+		}
+	}()
+
+	handler := server.New(g.Cat, token).
+		WithOpener(editorStarter(g.Config.Editors)).
+		WithStaleAfter(render.StaleThreshold)
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- srv.Serve(ln) }()
+	fmt.Fprintf(g.Out, "Serving catalog on %s\n", cmd.Listen)
+	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
+		fmt.Fprintf(g.Out, "Dashboard: %s\n", dashboardURL(addr, token))
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// dashboardURL passes the token in the fragment, which the dashboard keeps
+// for the session and browsers never send to the server.
+func dashboardURL(addr *net.TCPAddr, token string) string {
+	u := "http://" + addr.String() + "/"
+	if token != "" {
+		u += "#token=" + url.QueryEscape(token)
+	}
+	return u
+}
+
+// editorStarter launches the project's editor without waiting for it, so
+// the dashboard is usable with GUI editors.
+func editorStarter(cfg config.EditorConfig) func(pj.Project) error {
+	return func(p pj.Project) error {
+		editor, err := resolveEditor(cfg, p, "")
+		if err != nil {
+			return err
+		}
+		cmd := exec.Command(editor[0], append(editor[1:], p.Path)...)
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("failed to start %s: %w", editor[0], err)
+		}
+		go func() { _ = cmd.Wait() }()
+		return nil
+	}
+}
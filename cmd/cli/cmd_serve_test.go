@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeCmd_Run(t *testing.T) {
+	t.Run("rejects a world-readable token file", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("abc"), 0o644))
+
+		err := (&ServeCmd{Listen: "127.0.0.1:0", TokenFile: path}).Run(g)
+
+		assert.ErrorContains(t, err, "failed to read token")
+	})
+
+	t.Run("passes the token to the dashboard in the fragment", func(t *testing.T) {
+		addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7777}
+
+		assert.Equal(t, "http://127.0.0.1:7777/", dashboardURL(addr, ""))
+		assert.Equal(t, "http://127.0.0.1:7777/#token=a+b%26c", dashboardURL(addr, "a b&c"))
+	})
+
+	t.Run("refuses a non-loopback address without a token", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+
+		err := (&ServeCmd{Listen: "0.0.0.0:0"}).Run(g)
+
+		assert.ErrorContains(t, err, "needs --token-file")
+	})
+
+	t.Run("reports listen errors", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+
+		err := (&ServeCmd{Listen: "256.0.0.1:1"}).Run(g)
+
+		assert.ErrorContains(t, err, "failed to listen on 256.0.0.1:1")
+	})
+}
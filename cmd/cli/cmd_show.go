@@ -1,6 +1,11 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"pj/internal/git"
+	"pj/internal/notes"
+	"strings"
+)
 
 type ShowCmd struct {
 	Name string `arg:"" help:"Project name" completion:"pj list -n"`
@@ -8,7 +13,7 @@ type ShowCmd struct {
 }
 
 func (cmd *ShowCmd) Run(g *Globals) error {
-	project, err := findProject(g.Cat, cmd.Name)
+	project, dir, err := findProjectDir(g.Cat, cmd.Name)
 	if err != nil {
 		if handleFindError(g.Out, err) {
 			return nil
@@ -17,14 +22,57 @@ func (cmd *ShowCmd) Run(g *Globals) error {
 	}
 
 	if cmd.Path {
-		fmt.Fprintln(g.Out, project.Path)
+		fmt.Fprintln(g.Out, dir)
 		return nil
 	}
 
 	fmt.Fprintf(g.Out, "Name:   %s\n", project.Name)
 	fmt.Fprintf(g.Out, "Path:   %s\n", project.Path)
-	if project.Editor != "" {
-		fmt.Fprintf(g.Out, "Editor: %s\n", project.Editor)
+	if project.Unavailable {
+		fmt.Fprintln(g.Out, "Host:   not available")
+	}
+	if len(project.Tags) > 0 {
+		fmt.Fprintf(g.Out, "Tags:   %s\n", strings.Join(project.Tags, ", "))
+	}
+	if stack := project.Stack; stack != nil {
+		fmt.Fprintf(g.Out, "Stack:  %s\n", strings.Join(stack.Summary(), ", "))
+	}
+	if project.Remote != "" {
+		remote := project.Remote
+		if u, err := git.ParseRemote(remote); err == nil {
+			remote = u.String()
+		}
+		fmt.Fprintf(g.Out, "Remote: %s\n", remote)
+	}
+	if len(project.Worktrees) > 0 {
+		fmt.Fprintln(g.Out, "Worktrees:")
+		for _, w := range project.Worktrees {
+			fmt.Fprintf(g.Out, "  %s@%s  %s\n", project.Name, w.Name(), w.Path)
+		}
+	}
+	if project.Status != "" {
+		fmt.Fprintf(g.Out, "Status: %s\n", project.Status)
+	}
+	if project.ArchivePath != "" {
+		fmt.Fprintf(g.Out, "Archive: %s\n", project.ArchivePath)
+	}
+	if parent, err := g.Cat.Get(project.Parent); project.Parent != "" && err == nil {
+		fmt.Fprintf(g.Out, "Parent: %s\n", parent.Name)
+	}
+	if children := subprojects(g.Cat, project.ID); len(children) > 0 {
+		fmt.Fprintln(g.Out, "Sub-projects:")
+		for _, c := range children {
+			fmt.Fprintf(g.Out, "  %s/%s  %s\n", project.Name, c.Name, c.Path)
+		}
+	}
+	editor := chooseEditor(g.Config.Editors, project, "")
+	fmt.Fprintf(g.Out, "Editor: %s (%s)\n", editor.Command, editor.Source)
+	if list, err := notes.ForCatalog(g.CatalogPath).Read(project.ID); err == nil && len(list) > 0 {
+		fmt.Fprintf(g.Out, "Notes:  %d, latest first\n", len(list))
+		for i := len(list) - 1; i >= max(0, len(list)-showNotes); i-- {
+			first, _, _ := strings.Cut(list[i].Text, "\n")
+			fmt.Fprintf(g.Out, "  %s  %s\n", list[i].Time.Format("2006-01-02 15:04"), first)
+		}
 	}
 	return nil
 }
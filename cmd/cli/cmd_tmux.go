@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"pj/internal/catalog"
+	"strings"
+)
+
+type TmuxCmd struct {
+	Name string `arg:"" help:"Project name or partial match" completion:"pj list -n"`
+}
+
+func (cmd *TmuxCmd) Run(g *Globals) error {
+	project, err := findProject(g.Cat, cmd.Name)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+
+	if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+		return fmt.Errorf("project path no longer exists: %s\nRun 'pj rm %s' to remove from catalog",
+			project.Path, project.Name)
+	}
+
+	runCmd := g.RunCmd
+	if runCmd == nil {
+		runCmd = defaultRunCmd
+	}
+
+	session := tmuxSessionName(project.Name)
+	target := "=" + session
+	if err := runCmd("tmux", "has-session", "-t", target); err != nil {
+		for _, args := range tmuxCreateCommands(session, project) {
+			if err := runCmd("tmux", args...); err != nil {
+				return fmt.Errorf("creating tmux session %q: %w", session, err)
+			}
+		}
+	}
+
+	if _, err := g.Cat.Touch(project.ID); err != nil {
+		return fmt.Errorf("failed to update project %q: %w", project.Name, err)
+	}
+
+	if os.Getenv("TMUX") != "" {
+		return runCmd("tmux", "switch-client", "-t", target)
+	}
+	return runCmd("tmux", "attach-session", "-t", target)
+}
+
+// tmuxSessionName maps a project name onto the characters tmux accepts in
+// session names; '.' and ':' are target separators.
+func tmuxSessionName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
+
+func tmuxCreateCommands(session string, project catalog.Project) [][]string {
+	windows := []catalog.TmuxWindow{{}}
+	if project.Tmux != nil && len(project.Tmux.Windows) > 0 {
+		windows = project.Tmux.Windows
+	}
+
+	var cmds [][]string
+	for i, w := range windows {
+		if i == 0 {
+			args := []string{"new-session", "-d", "-s", session, "-c", project.Path}
+			if w.Name != "" {
+				args = append(args, "-n", w.Name)
+			}
+			cmds = append(cmds, args)
+		} else {
+			args := []string{"new-window", "-t", session + ":", "-c", project.Path}
+			if w.Name != "" {
+				args = append(args, "-n", w.Name)
+			}
+			cmds = append(cmds, args)
+		}
+
+		target := session + ":"
+		if w.Name != "" {
+			target = session + ":=" + w.Name
+		}
+		for j, pane := range w.Panes {
+			if j > 0 {
+				cmds = append(cmds, []string{"split-window", "-t", target, "-c", project.Path})
+			}
+			if pane != "" {
+				cmds = append(cmds, []string{"send-keys", "-t", target, pane, "Enter"})
+			}
+		}
+		if w.Layout != "" {
+			cmds = append(cmds, []string{"select-layout", "-t", target, w.Layout})
+		}
+	}
+
+	if len(windows) > 1 {
+		first := session + ":^"
+		if windows[0].Name != "" {
+			first = session + ":=" + windows[0].Name
+		}
+		cmds = append(cmds, []string{"select-window", "-t", first})
+	}
+	return cmds
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"pj/internal/catalog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeTmux(g *Globals, sessionExists bool) *[][]string {
+	var calls [][]string
+	g.RunCmd = func(name string, args ...string) error {
+		calls = append(calls, append([]string{name}, args...))
+		if len(args) > 0 && args[0] == "has-session" && !sessionExists {
+			return errors.New("exit status 1")
+		}
+		return nil
+	}
+	return &calls
+}
+
+func TestTmuxCmd_Run(t *testing.T) {
+	t.Run("creates session in project dir and attaches", func(t *testing.T) {
+		t.Setenv("TMUX", "")
+		g, _ := newTestGlobals(t)
+		projectDir := createTestProject(t, g, "api")
+		calls := fakeTmux(g, false)
+
+		err := (&TmuxCmd{Name: "api"}).Run(g)
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"tmux", "has-session", "-t", "=api"},
+			{"tmux", "new-session", "-d", "-s", "api", "-c", projectDir},
+			{"tmux", "attach-session", "-t", "=api"},
+		}, *calls)
+	})
+
+	t.Run("attaches to existing session without recreating it", func(t *testing.T) {
+		t.Setenv("TMUX", "")
+		g, _ := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		calls := fakeTmux(g, true)
+
+		err := (&TmuxCmd{Name: "api"}).Run(g)
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"tmux", "has-session", "-t", "=api"},
+			{"tmux", "attach-session", "-t", "=api"},
+		}, *calls)
+	})
+
+	t.Run("switches client when already inside tmux", func(t *testing.T) {
+		t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+		g, _ := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		calls := fakeTmux(g, true)
+
+		err := (&TmuxCmd{Name: "api"}).Run(g)
+
+		require.NoError(t, err)
+		last := (*calls)[len(*calls)-1]
+		assert.Equal(t, []string{"tmux", "switch-client", "-t", "=api"}, last)
+	})
+
+	t.Run("returns error for nonexistent project", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		calls := fakeTmux(g, false)
+
+		err := (&TmuxCmd{Name: "nonexistent"}).Run(g)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no project found matching")
+		assert.Empty(t, *calls)
+	})
+}
+
+func TestTmuxSessionName(t *testing.T) {
+	assert.Equal(t, "api", tmuxSessionName("api"))
+	assert.Equal(t, "my_app_v2", tmuxSessionName("my.app:v2"))
+}
+
+func TestTmuxCreateCommands(t *testing.T) {
+	t.Run("builds windows and panes from layout", func(t *testing.T) {
+		p := catalog.Project{Name: "api", Path: "/src/api"}.WithTmuxLayout(&catalog.TmuxLayout{
+			Windows: []catalog.TmuxWindow{
+				{Name: "dev", Layout: "main-vertical", Panes: []string{"nvim .", "", "pj run dev"}},
+				{Name: "logs", Panes: []string{"tail -f log.txt"}},
+			},
+		})
+
+		cmds := tmuxCreateCommands("api", p)
+
+		assert.Equal(t, [][]string{
+			{"new-session", "-d", "-s", "api", "-c", "/src/api", "-n", "dev"},
+			{"send-keys", "-t", "api:=dev", "nvim .", "Enter"},
+			{"split-window", "-t", "api:=dev", "-c", "/src/api"},
+			{"split-window", "-t", "api:=dev", "-c", "/src/api"},
+			{"send-keys", "-t", "api:=dev", "pj run dev", "Enter"},
+			{"select-layout", "-t", "api:=dev", "main-vertical"},
+			{"new-window", "-t", "api:", "-c", "/src/api", "-n", "logs"},
+			{"send-keys", "-t", "api:=logs", "tail -f log.txt", "Enter"},
+			{"select-window", "-t", "api:=dev"},
+		}, cmds)
+	})
+}
+
+func TestEditCmd_TmuxPane(t *testing.T) {
+	g, _ := newTestGlobals(t)
+	createTestProject(t, g, "api")
+
+	cmd := EditCmd{Name: "api", TmuxPane: []string{"nvim .", ""}}
+	require.NoError(t, cmd.Run(g))
+
+	projects := g.Cat.Search("api")
+	require.Len(t, projects, 1)
+	require.NotNil(t, projects[0].Tmux)
+	require.Len(t, projects[0].Tmux.Windows, 1)
+	assert.Equal(t, []string{"nvim .", ""}, projects[0].Tmux.Windows[0].Panes)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"pj/internal/ui"
+)
+
+type TuiCmd struct{}
+
+func (cmd *TuiCmd) Run(g *Globals) error {
+	result, err := ui.RunDashboard(context.Background(), g.Cat)
+	if err != nil {
+		return err
+	}
+
+	switch result.Action {
+	case ui.ActionOpen:
+		project, err := g.Cat.Get(result.Project.ID)
+		if err != nil {
+			return err
+		}
+		return openProject(g, project, openRequest{})
+	case ui.ActionCd:
+		changeDir(g, result.Project.Path)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/config"
+	"slices"
+)
+
+type WsCmd struct {
+	Create WsCreateCmd `cmd:"" aliases:"new" help:"Create a workspace"`
+	Add    WsAddCmd    `cmd:"" help:"Add projects to a workspace"`
+	Rm     WsRmCmd     `cmd:"" help:"Remove projects from a workspace, or the workspace itself"`
+	Ls     WsLsCmd     `cmd:"" aliases:"list" help:"List workspaces"`
+	Open   WsOpenCmd   `cmd:"" aliases:"o" help:"Open every project in a workspace"`
+}
+
+type WsCreateCmd struct {
+	Name     string   `arg:"" help:"Workspace name"`
+	Projects []string `arg:"" optional:"" help:"Projects to include, in order" completion:"pj list -n"`
+	Editor   string   `help:"Editor override for the whole workspace"`
+}
+
+func (cmd *WsCreateCmd) Run(g *Globals) error {
+	ids, err := findProjectIDs(g, cmd.Projects)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+
+	w := catalog.NewWorkspace(cmd.Name, ids...).WithEditor(cmd.Editor)
+	if err := mutate(g.Cat, func(tx catalog.Tx) error { return tx.AddWorkspace(w) }); err != nil {
+		return fmt.Errorf("failed to create workspace %q: %w", cmd.Name, err)
+	}
+
+	fmt.Fprintf(g.Out, "Created workspace: %s (%d projects)\n", w.Name, len(w.ProjectIDs))
+	return nil
+}
+
+type WsAddCmd struct {
+	Name     string   `arg:"" help:"Workspace name" completion:"pj ws ls -n"`
+	Projects []string `arg:"" help:"Projects to append" completion:"pj list -n"`
+}
+
+func (cmd *WsAddCmd) Run(g *Globals) error {
+	w, err := g.Cat.GetWorkspace(cmd.Name)
+	if err != nil {
+		return fmt.Errorf("workspace %q: %w", cmd.Name, err)
+	}
+
+	ids, err := findProjectIDs(g, cmd.Projects)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+	err = mutate(g.Cat, func(tx catalog.Tx) error {
+		if w, err = tx.GetWorkspace(w.Name); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			w = w.WithProject(id)
+		}
+		return tx.UpdateWorkspace(w)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update workspace %q: %w", w.Name, err)
+	}
+
+	fmt.Fprintf(g.Out, "Updated workspace: %s (%d projects)\n", w.Name, len(w.ProjectIDs))
+	return nil
+}
+
+type WsRmCmd struct {
+	Name     string   `arg:"" help:"Workspace name" completion:"pj ws ls -n"`
+	Projects []string `arg:"" optional:"" help:"Projects to drop; omit to delete the workspace" completion:"pj list -n"`
+}
+
+func (cmd *WsRmCmd) Run(g *Globals) error {
+	w, err := g.Cat.GetWorkspace(cmd.Name)
+	if err != nil {
+		return fmt.Errorf("workspace %q: %w", cmd.Name, err)
+	}
+
+	if len(cmd.Projects) == 0 {
+		if err := mutate(g.Cat, func(tx catalog.Tx) error { return tx.RemoveWorkspace(w.Name) }); err != nil {
+			return fmt.Errorf("failed to remove workspace %q: %w", w.Name, err)
+		}
+		fmt.Fprintf(g.Out, "Removed workspace: %s\n", w.Name)
+		return nil
+	}
+
+	ids, err := findProjectIDs(g, cmd.Projects)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+	err = mutate(g.Cat, func(tx catalog.Tx) error {
+		if w, err = tx.GetWorkspace(w.Name); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			w = w.WithoutProject(id)
+		}
+		return tx.UpdateWorkspace(w)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update workspace %q: %w", w.Name, err)
+	}
+
+	fmt.Fprintf(g.Out, "Updated workspace: %s (%d projects)\n", w.Name, len(w.ProjectIDs))
+	return nil
+}
+
+type WsLsCmd struct {
+	Names bool `short:"n" help:"Output only workspace names (one per line)"`
+}
+
+func (cmd *WsLsCmd) Run(g *Globals) error {
+	workspaces := g.Cat.Workspaces()
+
+	if cmd.Names {
+		for _, w := range workspaces {
+			fmt.Fprintln(g.Out, w.Name)
+		}
+		return nil
+	}
+
+	if len(workspaces) == 0 {
+		fmt.Fprintln(g.Out, "No workspaces found.")
+		return nil
+	}
+
+	for _, w := range workspaces {
+		fmt.Fprint(g.Out, w.Name)
+		if w.Editor != "" {
+			fmt.Fprintf(g.Out, " (editor: %s)", w.Editor)
+		}
+		fmt.Fprintln(g.Out)
+		for _, id := range w.ProjectIDs {
+			p, err := g.Cat.Get(id)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(g.Out, "  - %s (%s)\n", p.Name, p.Path)
+		}
+	}
+	return nil
+}
+
+type WsOpenCmd struct {
+	Name string `arg:"" help:"Workspace name" completion:"pj ws ls -n"`
+}
+
+func (cmd *WsOpenCmd) Run(g *Globals) error {
+	w, err := g.Cat.GetWorkspace(cmd.Name)
+	if err != nil {
+		return fmt.Errorf("workspace %q: %w", cmd.Name, err)
+	}
+	if len(w.ProjectIDs) == 0 {
+		return fmt.Errorf("workspace %q has no projects", w.Name)
+	}
+
+	projects := make([]catalog.Project, 0, len(w.ProjectIDs))
+	for _, id := range w.ProjectIDs {
+		p, err := g.Cat.Get(id)
+		if err != nil {
+			return fmt.Errorf("workspace %q: %w", w.Name, err)
+		}
+		if _, err := os.Stat(p.Path); os.IsNotExist(err) {
+			return fmt.Errorf("project path no longer exists: %s\nRun 'pj rm %s' to remove from catalog",
+				p.Path, p.Name)
+		}
+		projects = append(projects, p)
+	}
+
+	launches, err := planWorkspaceLaunches(g.Config.Editors, w, projects)
+	if err != nil {
+		return err
+	}
+
+	err = mutate(g.Cat, func(tx catalog.Tx) error {
+		for _, p := range projects {
+			current, err := tx.Get(p.ID)
+			if err != nil {
+				return err
+			}
+			current.Touch()
+			if err := tx.Update(current); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update projects of workspace %q: %w", w.Name, err)
+	}
+
+	runCmd := g.RunCmd
+	if runCmd == nil {
+		runCmd = defaultRunCmd
+	}
+	var errs []error
+	for _, l := range launches {
+		if err := runCmd(l[0], l[1:]...); err != nil {
+			errs = append(errs, fmt.Errorf("running %s: %w", l[0], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// multiFolderEditors accept several folders in one invocation and open
+// them together in a single window.
+var multiFolderEditors = []string{
+	"code",
+	"code-insiders",
+	"codium",
+	"cursor",
+	"windsurf",
+	"subl",
+	"zed",
+}
+
+func planWorkspaceLaunches(cfg config.EditorConfig, w catalog.Workspace, projects []catalog.Project) ([][]string, error) {
+	editors := make([][]string, len(projects))
+	for i, p := range projects {
+		if w.Editor != "" {
+			p = p.WithEditor(w.Editor)
+		}
+		editor, err := resolveEditor(cfg, p, "")
+		if err != nil {
+			return nil, err
+		}
+		editors[i] = editor
+	}
+
+	shared := editors[0]
+	sameEditor := !slices.ContainsFunc(editors, func(e []string) bool {
+		return !slices.Equal(e, shared)
+	})
+	if sameEditor && slices.Contains(multiFolderEditors, filepath.Base(shared[0])) {
+		launch := slices.Clone(shared)
+		for _, p := range projects {
+			launch = append(launch, p.Path)
+		}
+		return [][]string{launch}, nil
+	}
+
+	launches := make([][]string, len(projects))
+	for i, p := range projects {
+		launches[i] = append(slices.Clone(editors[i]), p.Path)
+	}
+	return launches, nil
+}
+
+func findProjectIDs(g *Globals, queries []string) ([]string, error) {
+	ids := make([]string, 0, len(queries))
+	for _, q := range queries {
+		p, err := findProject(g.Cat, q)
+		if err != nil {
+			return nil, err
+		}
+		// Two queries can name the same project, e.g. "api" and "./api".
+		if !slices.Contains(ids, p.ID) {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func installFakeEditor(t *testing.T, name string) {
+	t.Helper()
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, name), []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+type recordedCall struct {
+	name string
+	args []string
+}
+
+func recordRunCmd(g *Globals) *[]recordedCall {
+	var calls []recordedCall
+	g.RunCmd = func(name string, args ...string) error {
+		calls = append(calls, recordedCall{name: name, args: args})
+		return nil
+	}
+	return &calls
+}
+
+func TestWsCreateCmd_Run(t *testing.T) {
+	t.Run("creates workspace with projects in order", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "web")
+		createTestProject(t, g, "api")
+
+		cmd := WsCreateCmd{Name: "stack", Projects: []string{"web", "api"}}
+		err := cmd.Run(g)
+
+		require.NoError(t, err)
+		w, err := g.Cat.GetWorkspace("stack")
+		require.NoError(t, err)
+		require.Len(t, w.ProjectIDs, 2)
+		first, _ := g.Cat.Get(w.ProjectIDs[0])
+		assert.Equal(t, "web", first.Name)
+		assert.Contains(t, out.String(), "Created workspace: stack")
+	})
+
+	t.Run("lists a project named twice once", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "api")
+
+		cmd := WsCreateCmd{Name: "stack", Projects: []string{"api", "api"}}
+		require.NoError(t, cmd.Run(g))
+
+		w, err := g.Cat.GetWorkspace("stack")
+		require.NoError(t, err)
+		assert.Len(t, w.ProjectIDs, 1)
+		assert.Contains(t, out.String(), "Created workspace: stack (1 projects)")
+	})
+
+	t.Run("returns error for unknown project", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+
+		cmd := WsCreateCmd{Name: "stack", Projects: []string{"nonexistent"}}
+		err := cmd.Run(g)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no project found matching")
+		assert.Empty(t, g.Cat.Workspaces())
+	})
+}
+
+func TestWsAddRmCmd_Run(t *testing.T) {
+	t.Run("adds and removes projects", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		createTestProject(t, g, "web")
+		createTestProject(t, g, "api")
+		require.NoError(t, (&WsCreateCmd{Name: "stack", Projects: []string{"web"}}).Run(g))
+
+		require.NoError(t, (&WsAddCmd{Name: "stack", Projects: []string{"api"}}).Run(g))
+		w, _ := g.Cat.GetWorkspace("stack")
+		assert.Len(t, w.ProjectIDs, 2)
+
+		require.NoError(t, (&WsRmCmd{Name: "stack", Projects: []string{"web"}}).Run(g))
+		w, _ = g.Cat.GetWorkspace("stack")
+		require.Len(t, w.ProjectIDs, 1)
+		p, _ := g.Cat.Get(w.ProjectIDs[0])
+		assert.Equal(t, "api", p.Name)
+	})
+
+	t.Run("rm without projects deletes the workspace", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		require.NoError(t, (&WsCreateCmd{Name: "stack"}).Run(g))
+
+		err := (&WsRmCmd{Name: "stack"}).Run(g)
+
+		require.NoError(t, err)
+		assert.Empty(t, g.Cat.Workspaces())
+		assert.Contains(t, out.String(), "Removed workspace: stack")
+	})
+
+	t.Run("add returns error for unknown workspace", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		createTestProject(t, g, "web")
+
+		err := (&WsAddCmd{Name: "missing", Projects: []string{"web"}}).Run(g)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestWsLsCmd_Run(t *testing.T) {
+	t.Run("lists workspaces with members", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		createTestProject(t, g, "web")
+		require.NoError(t, (&WsCreateCmd{Name: "stack", Projects: []string{"web"}, Editor: "code"}).Run(g))
+		out.Reset()
+
+		err := (&WsLsCmd{}).Run(g)
+
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "stack (editor: code)")
+		assert.Contains(t, out.String(), "  - web")
+	})
+
+	t.Run("names flag outputs only names", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		require.NoError(t, (&WsCreateCmd{Name: "alpha"}).Run(g))
+		require.NoError(t, (&WsCreateCmd{Name: "beta"}).Run(g))
+		out.Reset()
+
+		err := (&WsLsCmd{Names: true}).Run(g)
+
+		require.NoError(t, err)
+		assert.Equal(t, "alpha\nbeta\n", out.String())
+	})
+}
+
+func TestWsOpenCmd_Run(t *testing.T) {
+	t.Run("launches multi-folder editor once with all paths", func(t *testing.T) {
+		installFakeEditor(t, "code")
+		g, _ := newTestGlobals(t)
+		webDir := createTestProject(t, g, "web")
+		apiDir := createTestProject(t, g, "api")
+		require.NoError(t, (&WsCreateCmd{Name: "stack", Projects: []string{"web", "api"}, Editor: "code"}).Run(g))
+		calls := recordRunCmd(g)
+
+		err := (&WsOpenCmd{Name: "stack"}).Run(g)
+
+		require.NoError(t, err)
+		require.Len(t, *calls, 1)
+		assert.Equal(t, "code", (*calls)[0].name)
+		assert.Equal(t, []string{webDir, apiDir}, (*calls)[0].args)
+	})
+
+	t.Run("launches other editors once per project", func(t *testing.T) {
+		t.Setenv("EDITOR", "true")
+		g, _ := newTestGlobals(t)
+		webDir := createTestProject(t, g, "web")
+		apiDir := createTestProject(t, g, "api")
+		require.NoError(t, (&WsCreateCmd{Name: "stack", Projects: []string{"web", "api"}}).Run(g))
+		calls := recordRunCmd(g)
+
+		err := (&WsOpenCmd{Name: "stack"}).Run(g)
+
+		require.NoError(t, err)
+		require.Len(t, *calls, 2)
+		assert.Equal(t, []string{webDir}, (*calls)[0].args)
+		assert.Equal(t, []string{apiDir}, (*calls)[1].args)
+	})
+
+	t.Run("returns error for empty workspace", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		require.NoError(t, (&WsCreateCmd{Name: "stack"}).Run(g))
+
+		err := (&WsOpenCmd{Name: "stack"}).Run(g)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no projects")
+	})
+}
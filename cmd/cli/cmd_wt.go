@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/git"
+	"strings"
+)
+
+type WtCmd struct {
+	Add WtAddCmd `cmd:"" help:"Create a worktree for a branch and change into it"`
+	Rm  WtRmCmd  `cmd:"" aliases:"remove" help:"Remove a worktree"`
+	Ls  WtLsCmd  `cmd:"" aliases:"list" help:"List worktrees"`
+}
+
+type WtAddCmd struct {
+	Project string `arg:"" help:"Project name" completion:"pj list -n"`
+	Branch  string `arg:"" help:"Branch to check out; created when it does not exist"`
+	Base    string `help:"Start a new branch from this ref instead of HEAD"`
+}
+
+func (cmd *WtAddCmd) Run(g *Globals) error {
+	project, err := findProject(g.Cat, cmd.Project)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+	ctx := context.Background()
+	if !git.IsMainWorktree(ctx, project.Path) {
+		return fmt.Errorf("%s is not the main worktree of a git repository", project.Name)
+	}
+	if w, ok := project.Worktree(cmd.Branch); ok {
+		return fmt.Errorf("worktree %s@%s already exists at %s", project.Name, w.Name(), w.Path)
+	}
+
+	dir, err := g.Config.WorktreeDir(project.Name, project.Path, cmd.Branch)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dir), err)
+	}
+	if err := git.AddWorktree(ctx, project.Path, dir, cmd.Branch, cmd.Base); err != nil {
+		return err
+	}
+	if _, err := syncWorktrees(g.Cat, project); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(g.Out, "Created worktree: %s@%s (%s)\n", project.Name, cmd.Branch, dir)
+	changeDir(g, dir)
+	return nil
+}
+
+type WtRmCmd struct {
+	Target string `arg:"" help:"Worktree to remove, as project@worktree"`
+	Force  bool   `short:"f" help:"Remove even with uncommitted changes"`
+}
+
+func (cmd *WtRmCmd) Run(g *Globals) error {
+	if !strings.Contains(cmd.Target, "@") {
+		return errors.New("name the worktree as project@worktree")
+	}
+	project, dir, err := findProjectDir(g.Cat, cmd.Target)
+	if err != nil {
+		if handleFindError(g.Out, err) {
+			return nil
+		}
+		return err
+	}
+	if dir == project.Path {
+		return fmt.Errorf("%s is the main worktree of %s", cmd.Target, project.Name)
+	}
+
+	if err := git.RemoveWorktree(context.Background(), project.Path, dir, cmd.Force); err != nil {
+		return err
+	}
+	if _, err := syncWorktrees(g.Cat, project); err != nil {
+		return err
+	}
+	fmt.Fprintf(g.Out, "Removed worktree: %s (%s)\n", cmd.Target, dir)
+	return nil
+}
+
+type WtLsCmd struct {
+	Project string `arg:"" optional:"" help:"Only this project's worktrees, re-read from git" completion:"pj list -n"`
+}
+
+func (cmd *WtLsCmd) Run(g *Globals) error {
+	projects := g.Cat.List()
+	if cmd.Project != "" {
+		project, err := findProject(g.Cat, cmd.Project)
+		if err != nil {
+			if handleFindError(g.Out, err) {
+				return nil
+			}
+			return err
+		}
+		if project, err = syncWorktrees(g.Cat, project); err != nil {
+			return err
+		}
+		projects = []catalog.Project{project}
+	}
+
+	for _, p := range projects {
+		for _, w := range p.Worktrees {
+			fmt.Fprintf(g.Out, "%s@%s\t%s\n", p.Name, w.Name(), w.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRepoProject adds a project whose directory is a git repository
+// with one commit.
+func createRepoProject(t *testing.T, g *Globals, name string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main\n"), 0o644))
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init")
+	require.NoError(t, (&AddCmd{Path: dir}).Run(g))
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestWtCmd(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(cdFileEnv, "")
+	g, out := newTestGlobals(t)
+	dir := createRepoProject(t, g, "api")
+	wtDir := filepath.Join(dir+".worktrees", "feature-x")
+
+	t.Run("add creates the worktree, records it and changes into it", func(t *testing.T) {
+		out.Reset()
+
+		require.NoError(t, (&WtAddCmd{Project: "api", Branch: "feature/x"}).Run(g))
+
+		assert.Contains(t, out.String(), "Created worktree: api@feature/x ("+wtDir+")")
+		assert.Contains(t, out.String(), "Run: cd "+wtDir)
+		assert.FileExists(t, filepath.Join(wtDir, "src", "main.go"))
+		w, ok := g.Cat.Search("api")[0].Worktree("feature/x")
+		require.True(t, ok)
+		assert.Equal(t, wtDir, w.Path)
+	})
+
+	t.Run("add refuses an existing worktree", func(t *testing.T) {
+		err := (&WtAddCmd{Project: "api", Branch: "feature/x"}).Run(g)
+		assert.ErrorContains(t, err, "already exists")
+	})
+
+	t.Run("cd resolves worktrees and their subdirectories", func(t *testing.T) {
+		got, err := resolveCdTarget(g.Cat, "api@feature/x")
+		require.NoError(t, err)
+		assert.Equal(t, wtDir, got)
+
+		got, err = resolveCdTarget(g.Cat, "api@feature/x/src")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(wtDir, "src"), got)
+
+		_, err = resolveCdTarget(g.Cat, "api@nope/src")
+		assert.ErrorIs(t, err, errNoWorktree)
+
+		assert.Equal(t, []string{"api@feature/x"}, cdCompletions(g.Cat, "api@f"))
+	})
+
+	t.Run("open uses the worktree directory", func(t *testing.T) {
+		t.Setenv("EDITOR", "true")
+		var gotArgs []string
+		g.RunCmd = func(name string, args ...string) error {
+			gotArgs = args
+			return nil
+		}
+
+		require.NoError(t, (&OpenCmd{Name: "api@feature/x"}).Run(g))
+
+		assert.Equal(t, []string{wtDir}, gotArgs)
+		assert.Equal(t, dir, g.Cat.Search("api")[0].Path, "the project path is untouched")
+	})
+
+	t.Run("worktrees created outside pj are found on demand", func(t *testing.T) {
+		external := filepath.Join(t.TempDir(), "hotfix")
+		runGit(t, dir, "worktree", "add", "-q", "-b", "hotfix", external)
+
+		got, err := resolveCdTarget(g.Cat, "api@hotfix")
+		require.NoError(t, err)
+		assert.Equal(t, external, got)
+
+		out.Reset()
+		require.NoError(t, (&WtLsCmd{}).Run(g))
+		assert.Equal(t, "api@feature/x\t"+wtDir+"\napi@hotfix\t"+external+"\n", out.String())
+	})
+
+	t.Run("adding a worktree path records it under its project", func(t *testing.T) {
+		external := filepath.Join(t.TempDir(), "spike")
+		runGit(t, dir, "worktree", "add", "-q", "-b", "spike", external)
+		out.Reset()
+
+		require.NoError(t, (&AddCmd{Path: external}).Run(g))
+
+		assert.Equal(t, "Recorded worktree: api@spike ("+external+")\n", out.String())
+		assert.Equal(t, 1, g.Cat.Count())
+	})
+
+	t.Run("rm removes the worktree and forgets it", func(t *testing.T) {
+		out.Reset()
+
+		require.NoError(t, (&WtRmCmd{Target: "api@feature/x"}).Run(g))
+
+		assert.NoDirExists(t, wtDir)
+		_, ok := g.Cat.Search("api")[0].Worktree("feature/x")
+		assert.False(t, ok)
+		assert.Contains(t, out.String(), "Removed worktree: api@feature/x")
+	})
+
+	t.Run("rm needs a worktree", func(t *testing.T) {
+		assert.Error(t, (&WtRmCmd{Target: "api"}).Run(g))
+	})
+}
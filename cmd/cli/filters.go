@@ -0,0 +1,23 @@
+package main
+
+import "pj/internal/catalog"
+
+// FilterFlags are the project filters shared by every command that prints
+// a selection of the catalog.
+type FilterFlags struct {
+	Query string `arg:"" optional:"" help:"Only include projects whose name or path contains this"`
+	Tag   string `help:"Only include projects with this tag"`
+	Lang  string `help:"Only include projects detected to use this language"`
+	Sort  string `enum:"name,path,last_accessed,added_at," default:"" placeholder:"FIELD" help:"Sort by name, path, last_accessed or added_at"`
+	Desc  bool   `help:"Reverse the sort order"`
+}
+
+func (f FilterFlags) Options() catalog.FilterOptions {
+	return catalog.FilterOptions{
+		Query:      f.Query,
+		Tag:        f.Tag,
+		Language:   f.Lang,
+		SortBy:     catalog.SortField(f.Sort),
+		Descending: f.Desc,
+	}
+}
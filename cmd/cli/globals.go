@@ -5,14 +5,17 @@ import (
 	"os"
 	"os/exec"
 	"pj/cmd/cli/render"
-	"pj/internal/catalog"
+	"pj/internal/config"
+	"pj/pkg/pj"
 )
 
 type Globals struct {
-	Cat    catalog.Catalog
-	Out    io.Writer
-	Render render.Renderer
-	RunCmd func(name string, args ...string) error
+	Cat         *pj.Catalog
+	CatalogPath string
+	Config      config.Config
+	Out         io.Writer
+	Render      render.Renderer
+	RunCmd      func(name string, args ...string) error
 }
 
 func defaultRunCmd(name string, args ...string) error {
@@ -1,25 +1,26 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"pj/internal/catalog"
+	"pj/internal/config"
+	"pj/internal/git"
+	"pj/pkg/pj"
 	"strings"
 )
 
-type AmbiguousMatchError struct {
-	Query   string
-	Matches []catalog.Project
-}
+// cdFileEnv names the file the `pj init` shell wrapper reads after every
+// command. A path written there becomes the shell's working directory.
+const cdFileEnv = "__PJ_CD_FILE"
 
-func (e *AmbiguousMatchError) Error() string {
-	return fmt.Sprintf("multiple projects match %q", e.Query)
-}
+type AmbiguousMatchError = pj.AmbiguousMatchError
 
-func (e *AmbiguousMatchError) WriteMatches(w io.Writer) {
+func writeMatches(w io.Writer, e *AmbiguousMatchError) {
 	fmt.Fprintln(w, "Multiple projects match. Please be more specific:")
 	for _, p := range e.Matches {
 		fmt.Fprintf(w, "  - %s (%s)\n", p.Name, p.Path)
@@ -28,21 +29,41 @@ func (e *AmbiguousMatchError) WriteMatches(w io.Writer) {
 
 func handleFindError(w io.Writer, err error) bool {
 	if ambErr, ok := errors.AsType[*AmbiguousMatchError](err); ok {
-		ambErr.WriteMatches(w)
+		writeMatches(w, ambErr)
 		return true
 	}
 	return false
 }
 
-func findProject(cat catalog.Catalog, query string) (catalog.Project, error) {
-	projects := cat.Search(query)
-	if len(projects) == 0 {
-		return catalog.Project{}, fmt.Errorf("no project found matching: %s", query)
+func findProject(cat pj.Store, query string) (catalog.Project, error) {
+	return pj.Resolve(cat, query)
+}
+
+// mutate applies fn's changes through the catalog lock shared with other
+// pj processes. A batch with a single failed change reports that change's
+// error alone.
+func mutate(cat *pj.Catalog, fn func(tx catalog.Tx) error) error {
+	err := cat.Mutate(fn)
+	if batchErr, ok := errors.AsType[*catalog.BatchError](err); ok && len(batchErr.Items) == 1 {
+		return batchErr.Items[0].Err
 	}
-	if len(projects) > 1 {
-		return catalog.Project{}, &AmbiguousMatchError{Query: query, Matches: projects}
+	return err
+}
+
+func shellIntegrationActive() bool {
+	return os.Getenv(cdFileEnv) != ""
+}
+
+// changeDir asks the shell wrapper to cd into path once the command exits,
+// or prints the cd command when there is no wrapper to do it.
+func changeDir(g *Globals, path string) {
+	if cdFile := os.Getenv(cdFileEnv); cdFile != "" {
+		if err := os.WriteFile(cdFile, []byte(path), 0o600); err == nil {
+			recordVisit(path)
+			return
+		}
 	}
-	return projects[0], nil
+	fmt.Fprintf(g.Out, "\nRun: cd %s\n", path)
 }
 
 func splitCommand(s string) []string {
@@ -77,14 +98,46 @@ func splitCommand(s string) []string {
 	return result
 }
 
-func resolveEditor(project catalog.Project) ([]string, error) {
-	editor := project.Editor
-	if editor == "" {
-		editor = os.Getenv("EDITOR")
+// originOf is the directory's origin remote in host/path form, so no
+// credentials in the URL reach the catalog. It is "" when there is none or
+// dir is not a git repository.
+func originOf(dir string) string {
+	origin, _ := git.Origin(context.Background(), dir)
+	return git.NormalizeURL(origin)
+}
+
+// editorChoice is the editor command picked for a project and the reason
+// it was picked, as shown by `pj show`.
+type editorChoice struct {
+	Command string
+	Source  string
+}
+
+// chooseEditor applies, in order: the --with override, the project's own
+// editor, the first matching config rule, the configured default, $EDITOR
+// and finally vim.
+func chooseEditor(cfg config.EditorConfig, project catalog.Project, with string) editorChoice {
+	if with != "" {
+		return editorChoice{cfg.Command(with), "--with " + with}
+	}
+	if project.Editor != "" {
+		return editorChoice{cfg.Command(project.Editor), "project setting"}
 	}
-	if editor == "" {
-		editor = "vim"
+	if i, ok := cfg.Match(project.Languages(), project.Tags, project.Path); ok {
+		rule := cfg.Rules[i]
+		return editorChoice{cfg.Command(rule.Editor), fmt.Sprintf("rule %d: %s", i+1, rule)}
 	}
+	if cfg.Default != "" {
+		return editorChoice{cfg.Command(cfg.Default), "config default"}
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editorChoice{editor, "$EDITOR"}
+	}
+	return editorChoice{"vim", "built-in default"}
+}
+
+func resolveEditor(cfg config.EditorConfig, project catalog.Project, with string) ([]string, error) {
+	editor := chooseEditor(cfg, project, with).Command
 
 	parts := splitCommand(editor)
 	if len(parts) == 0 {
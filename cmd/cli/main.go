@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"os"
 	"pj/cmd/cli/render"
-	"pj/internal/catalog"
 	"pj/internal/config"
+	"pj/pkg/pj"
 
 	"github.com/alecthomas/kong"
 )
@@ -19,37 +19,62 @@ var (
 type CLI struct {
 	Add        AddCmd        `cmd:"" aliases:"a" help:"Add a project to the catalog"`
 	Create     CreateCmd     `cmd:"" aliases:"new" help:"Create a new project interactively"`
+	Clone      CloneCmd      `cmd:"" help:"Clone a repository into <root>/<host>/<owner>/<repo> and add it"`
 	List       ListCmd       `cmd:"" aliases:"ls" help:"List projects in the catalog"`
 	Rm         RmCmd         `cmd:"" help:"Remove a project from the catalog"`
 	Open       OpenCmd       `cmd:"" aliases:"o" help:"Open project in editor"`
 	Edit       EditCmd       `cmd:"" aliases:"e" help:"Edit project metadata"`
+	Archive    ArchiveCmd    `cmd:"" help:"Archive a project, hiding it from lists and optionally compressing it"`
+	Unarchive  UnarchiveCmd  `cmd:"" help:"Make an archived project active again, restoring it if compressed"`
+	Refresh    RefreshCmd    `cmd:"" help:"Re-detect project languages and tooling"`
 	Show       ShowCmd       `cmd:"" help:"Show project details"`
+	Note       NoteCmd       `cmd:"" help:"Add to, print or edit a project's notes"`
+	Notes      NotesCmd      `cmd:"" help:"Show a journal of recent notes across projects"`
+	Browse     BrowseCmd     `cmd:"" help:"Open a project's repository, files, pull requests or issues in the browser"`
 	Cd         CdCmd         `cmd:"" help:"Change directory to project (requires shell integration)"`
+	Tmux       TmuxCmd       `cmd:"" help:"Attach to or create a tmux session for a project"`
+	Import     ImportCmd     `cmd:"" help:"Import projects from another tool's bookmarks"`
+	Export     ExportCmd     `cmd:"" help:"Export the catalog to another format"`
+	Catalog    CatalogCmd    `cmd:"" help:"Maintain catalog files"`
+	Serve      ServeCmd      `cmd:"" help:"Serve the catalog and a web dashboard over a local HTTP/JSON API"`
+	Tui        TuiCmd        `cmd:"" help:"Browse and manage projects in a full-screen dashboard"`
+	Ws         WsCmd         `cmd:"" aliases:"workspace" help:"Manage workspaces of projects opened together"`
+	Wt         WtCmd         `cmd:"" aliases:"worktree" help:"Manage git worktrees of projects"`
+	Report     ReportCmd     `cmd:"" help:"Report time spent in projects from shell activity"`
 	Init       InitCmd       `cmd:"" help:"Generate shell integration"`
 	Completion CompletionCmd `cmd:"" help:"Generate shell completions"`
 
 	CatalogPath string           `name:"catalog" short:"c" help:"Path to catalog file"`
+	ConfigPath  string           `name:"config" help:"Path to config file"`
 	Version     kong.VersionFlag `name:"version" short:"v" help:"Print version and exit"`
+
+	cat *pj.Catalog
 }
 
 func (c *CLI) AfterApply(ctx *kong.Context) error {
-	catalogPath := c.CatalogPath
-	if catalogPath == "" {
-		catalogPath = config.DefaultCatalogPath()
+	cat, err := pj.Open(pj.Options{CatalogPath: c.CatalogPath, ConfigPath: c.ConfigPath})
+	if err != nil {
+		return err
 	}
 
-	cat, err := catalog.NewYAMLCatalog(catalogPath)
-	if err != nil {
-		return fmt.Errorf("failed to create catalog: %w", err)
+	c.cat = cat
+
+	// pj.Open has already read and validated the same file.
+	configPath := c.ConfigPath
+	if configPath == "" {
+		configPath = config.DefaultConfigPath()
 	}
-	if err := cat.Load(); err != nil {
-		return fmt.Errorf("failed to load catalog: %w", err)
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
 	}
 
 	globals := &Globals{
-		Cat:    cat,
-		Out:    os.Stdout,
-		Render: render.NewLipglossRendererAuto(os.Stdout),
+		Cat:         cat,
+		CatalogPath: cat.Path(),
+		Config:      cfg,
+		Out:         os.Stdout,
+		Render:      render.NewLipglossRendererAuto(os.Stdout),
 	}
 	ctx.Bind(globals)
 	return nil
@@ -64,5 +89,10 @@ func main() {
 		kong.Vars{"version": fmt.Sprintf("%s (%s, %s)", Version, Commit, Date)},
 	)
 	err := ctx.Run()
+	if cli.cat != nil {
+		if closeErr := cli.cat.Close(); err == nil {
+			err = closeErr
+		}
+	}
 	ctx.FatalIfErrorf(err)
 }
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"pj/cmd/cli/render"
 	"pj/internal/catalog"
+	"pj/internal/config"
+	"pj/pkg/pj"
 	"strings"
 	"testing"
 	"time"
@@ -28,18 +31,38 @@ func newTestGlobals(t *testing.T) (*Globals, *bytes.Buffer) {
 func newTestGlobalsCore(t *testing.T) (*Globals, *bytes.Buffer, map[string]string) {
 	t.Helper()
 	dir := t.TempDir()
-	cat, err := catalog.NewYAMLCatalog(filepath.Join(dir, "catalog.yaml"))
-	require.NoError(t, err)
+	catalogPath := filepath.Join(dir, "catalog.yaml")
+	cat := openTestCatalog(t, catalogPath)
 	buf := &bytes.Buffer{}
 	pathMap := make(map[string]string)
 	return &Globals{
-		Cat:    cat,
-		Out:    buf,
-		Render: render.NewLipglossRenderer(80).WithClock(func() time.Time { return testFixedNow }),
-		RunCmd: func(name string, args ...string) error { return nil },
+		Cat:         cat,
+		CatalogPath: catalogPath,
+		Out:         buf,
+		Render:      render.NewLipglossRenderer(80).WithClock(func() time.Time { return testFixedNow }),
+		RunCmd:      func(name string, args ...string) error { return nil },
 	}, buf, pathMap
 }
 
+// openTestCatalog opens the catalog at path the way main does, with no
+// config file.
+func openTestCatalog(t *testing.T, path string) *pj.Catalog {
+	t.Helper()
+	cat, err := pj.Open(pj.Options{
+		CatalogPath: path,
+		ConfigPath:  filepath.Join(filepath.Dir(path), "config.yaml"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cat.Close() })
+	return cat
+}
+
+// mutateCatalog applies fn to g's catalog, failing the test on error.
+func mutateCatalog(t *testing.T, g *Globals, fn func(tx catalog.Tx) error) {
+	t.Helper()
+	require.NoError(t, g.Cat.Mutate(fn))
+}
+
 func createTestProject(t *testing.T, g *Globals, name string) string {
 	t.Helper()
 	projectDir := t.TempDir()
@@ -431,18 +454,13 @@ func TestCatalogPersistence(t *testing.T) {
 	dir := t.TempDir()
 	catalogPath := filepath.Join(dir, "catalog.yaml")
 
-	cat1, err := catalog.NewYAMLCatalog(catalogPath)
-	require.NoError(t, err)
-	g1 := &Globals{Cat: cat1, Out: os.Stdout}
+	g1 := &Globals{Cat: openTestCatalog(t, catalogPath), Out: os.Stdout}
 
 	projectDir := t.TempDir()
 	cmd := AddCmd{Path: projectDir, Name: "persistent-project"}
 	require.NoError(t, cmd.Run(g1))
 
-	cat2, err := catalog.NewYAMLCatalog(catalogPath)
-	require.NoError(t, err)
-	require.NoError(t, cat2.Load())
-	g2 := &Globals{Cat: cat2}
+	g2 := &Globals{Cat: openTestCatalog(t, catalogPath)}
 
 	assert.Equal(t, 1, g2.Cat.Count())
 	projects := g2.Cat.List()
@@ -451,6 +469,26 @@ func TestCatalogPersistence(t *testing.T) {
 	assert.Equal(t, projectDir, projects[0].Path)
 }
 
+func TestCatalogSharedBetweenProcesses(t *testing.T) {
+	catalogPath := filepath.Join(t.TempDir(), "catalog.yaml")
+	g1, _ := newTestGlobals(t)
+	g1.Cat, g1.CatalogPath = openTestCatalog(t, catalogPath), catalogPath
+	createTestProject(t, g1, "api")
+	g2, _ := newTestGlobals(t)
+	g2.Cat, g2.CatalogPath = openTestCatalog(t, catalogPath), catalogPath
+	createTestProject(t, g2, "web")
+
+	require.NoError(t, (&EditCmd{Name: "api", Tag: []string{"backend"}}).Run(g1))
+	require.NoError(t, (&EditCmd{Name: "api", Editor: "nvim"}).Run(g2))
+
+	cat := openTestCatalog(t, catalogPath)
+	assert.Equal(t, 2, cat.Count())
+	api, err := cat.Resolve("api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend"}, api.Tags)
+	assert.Equal(t, "nvim", api.Editor)
+}
+
 func TestCatalogPathParsing(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -568,9 +606,163 @@ func TestInitCmd_Run(t *testing.T) {
 		output := out.String()
 		assert.Contains(t, output, "pj shell integration")
 		assert.Contains(t, output, "pj()")
-		assert.Contains(t, output, "case \"$1\" in")
-		assert.Contains(t, output, "cd)")
-		assert.Contains(t, output, "command pj show")
+		assert.Contains(t, output, "__PJ_CD_FILE=\"$cdfile\" command pj \"$@\"")
+		assert.NotContains(t, output, "case \"$1\" in", "every subcommand is handled the same way")
+	})
+
+	t.Run("changes directory when the command asks to", func(t *testing.T) {
+		bash, err := exec.LookPath("bash")
+		if err != nil {
+			t.Skip("bash not installed")
+		}
+		bin := t.TempDir()
+		target := t.TempDir()
+		stub := "#!/bin/sh\n[ \"$1\" = go ] && printf %s \"$2\" > \"$__PJ_CD_FILE\"\nexit 0\n"
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "pj"), []byte(stub), 0o755))
+
+		script := shellScript + "pj go " + target + " && pwd && pj stay && pwd\n"
+		cmd := exec.Command(bash, "-c", script)
+		cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+		out, err := cmd.CombinedOutput()
+
+		require.NoError(t, err, string(out))
+		assert.Equal(t, target+"\n"+target+"\n", string(out))
+	})
+}
+
+func TestCdCmd_Run(t *testing.T) {
+	t.Run("requires shell integration", func(t *testing.T) {
+		g, out := newTestGlobals(t)
+		t.Setenv(cdFileEnv, "")
+
+		err := (&CdCmd{Name: "anything"}).Run(g)
+
+		assert.EqualError(t, err, "shell integration required")
+		assert.Contains(t, out.String(), "eval \"$(pj init)\"")
+	})
+
+	t.Run("writes the project path to the cd file", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		path := createTestProject(t, g, "test-project")
+		cdFile := filepath.Join(t.TempDir(), "cd")
+		t.Setenv(cdFileEnv, cdFile)
+
+		require.NoError(t, (&CdCmd{Name: "test-project"}).Run(g))
+
+		content, err := os.ReadFile(cdFile)
+		require.NoError(t, err)
+		assert.Equal(t, path, string(content))
+	})
+
+	t.Run("fails when the path is gone", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		path := createTestProject(t, g, "test-project")
+		require.NoError(t, os.Remove(path))
+		t.Setenv(cdFileEnv, filepath.Join(t.TempDir(), "cd"))
+
+		err := (&CdCmd{Name: "test-project"}).Run(g)
+
+		assert.ErrorContains(t, err, "path no longer exists")
+	})
+
+	t.Run("descends into a subdirectory", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		path := createTestProject(t, g, "api")
+		require.NoError(t, os.MkdirAll(filepath.Join(path, "internal", "handlers"), 0o755))
+		cdFile := filepath.Join(t.TempDir(), "cd")
+		t.Setenv(cdFileEnv, cdFile)
+
+		require.NoError(t, (&CdCmd{Name: "api/internal/handlers"}).Run(g))
+
+		content, err := os.ReadFile(cdFile)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(path, "internal", "handlers"), string(content))
+	})
+
+	t.Run("rejects bad subdirectories", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		path := createTestProject(t, g, "api")
+		require.NoError(t, os.WriteFile(filepath.Join(path, "file"), nil, 0o644))
+		t.Setenv(cdFileEnv, filepath.Join(t.TempDir(), "cd"))
+
+		assert.EqualError(t, (&CdCmd{Name: "api/missing"}).Run(g), "no directory missing in project api")
+		assert.EqualError(t, (&CdCmd{Name: "api/file"}).Run(g), "file in project api is not a directory")
+		assert.EqualError(t, (&CdCmd{Name: "api/../.."}).Run(g), "../.. is outside project api")
+	})
+
+	t.Run("returns to the previous project", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		api := createTestProject(t, g, "api")
+		web := createTestProject(t, g, "web")
+		cdFile := filepath.Join(t.TempDir(), "cd")
+		t.Setenv(cdFileEnv, cdFile)
+		t.Setenv(sessionEnv, "1234-5")
+		t.Setenv("XDG_STATE_HOME", t.TempDir())
+		cdTo := func(name string) string {
+			t.Helper()
+			require.NoError(t, (&CdCmd{Name: name}).Run(g))
+			content, err := os.ReadFile(cdFile)
+			require.NoError(t, err)
+			return string(content)
+		}
+
+		assert.EqualError(t, (&CdCmd{Name: "-"}).Run(g), "no previous project in this shell session")
+		cdTo("api")
+		cdTo("web")
+
+		assert.Equal(t, api, cdTo("-"))
+		assert.Equal(t, web, cdTo("-"))
+	})
+
+	t.Run("tracks each shell session separately", func(t *testing.T) {
+		g, _ := newTestGlobals(t)
+		createTestProject(t, g, "api")
+		createTestProject(t, g, "web")
+		t.Setenv(cdFileEnv, filepath.Join(t.TempDir(), "cd"))
+		t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+		t.Setenv(sessionEnv, "one")
+		require.NoError(t, (&CdCmd{Name: "api"}).Run(g))
+		require.NoError(t, (&CdCmd{Name: "web"}).Run(g))
+		t.Setenv(sessionEnv, "two")
+
+		assert.EqualError(t, (&CdCmd{Name: "-"}).Run(g), "no previous project in this shell session")
+	})
+}
+
+func TestCdCompletions(t *testing.T) {
+	g, _ := newTestGlobals(t)
+	path := createTestProject(t, g, "api")
+	createTestProject(t, g, "web")
+	for _, dir := range []string{"internal/handlers", "internal/store", "cmd", ".git"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(path, dir), 0o755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(path, "go.mod"), nil, 0o644))
+
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"", []string{"api", "web"}},
+		{"a", []string{"api"}},
+		{"api/", []string{"api/cmd/", "api/internal/"}},
+		{"api/.", []string{"api/.git/"}},
+		{"api/internal/h", []string{"api/internal/handlers/"}},
+		{"nope/", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			assert.Equal(t, tt.want, cdCompletions(g.Cat, tt.word))
+		})
+	}
+
+	t.Run("via the hidden flag", func(t *testing.T) {
+		g2, out := newTestGlobals(t)
+		g2.Cat = g.Cat
+
+		require.NoError(t, (&CdCmd{Name: "api/int", Complete: true}).Run(g2))
+
+		assert.Equal(t, "api/internal/\n", out.String())
 	})
 }
 
@@ -589,6 +781,18 @@ func TestEditCmd_EditorFlag(t *testing.T) {
 	})
 }
 
+func TestEditCmd_Tags(t *testing.T) {
+	g, _ := newTestGlobals(t)
+	createTestProject(t, g, "test-project")
+
+	require.NoError(t, (&EditCmd{Name: "test-project", Tag: []string{"work", "go"}}).Run(g))
+	require.NoError(t, (&EditCmd{Name: "test-project", Untag: []string{"work"}}).Run(g))
+
+	projects := g.Cat.Search("test-project")
+	require.Len(t, projects, 1)
+	assert.Equal(t, []string{"go"}, projects[0].Tags)
+}
+
 func TestOpenCmd_UsesProjectEditor(t *testing.T) {
 	t.Run("uses project-specific editor", func(t *testing.T) {
 		g, _ := newTestGlobals(t)
@@ -598,7 +802,7 @@ func TestOpenCmd_UsesProjectEditor(t *testing.T) {
 		require.Len(t, projects, 1)
 		p := projects[0]
 		p.Editor = "true"
-		require.NoError(t, g.Cat.Update(p))
+		mutateCatalog(t, g, func(tx catalog.Tx) error { return tx.Update(p) })
 
 		var editorUsed string
 		g.RunCmd = func(name string, args ...string) error {
@@ -622,7 +826,7 @@ func TestOpenCmd_UsesProjectEditor(t *testing.T) {
 		require.Len(t, projects, 1)
 		p := projects[0]
 		p.Editor = "true -v -x"
-		require.NoError(t, g.Cat.Update(p))
+		mutateCatalog(t, g, func(tx catalog.Tx) error { return tx.Update(p) })
 
 		var capturedName string
 		var capturedArgs []string
@@ -687,14 +891,14 @@ func TestSplitCommand(t *testing.T) {
 func TestResolveEditor(t *testing.T) {
 	t.Run("uses project editor first", func(t *testing.T) {
 		p := catalog.Project{Editor: "true"}
-		editor, err := resolveEditor(p)
+		editor, err := resolveEditor(config.EditorConfig{}, p, "")
 		require.NoError(t, err)
 		assert.Equal(t, []string{"true"}, editor)
 	})
 
 	t.Run("parses editor with arguments", func(t *testing.T) {
 		p := catalog.Project{Editor: "true -v"}
-		editor, err := resolveEditor(p)
+		editor, err := resolveEditor(config.EditorConfig{}, p, "")
 		require.NoError(t, err)
 		assert.Equal(t, []string{"true", "-v"}, editor)
 	})
@@ -702,7 +906,7 @@ func TestResolveEditor(t *testing.T) {
 	t.Run("falls back to EDITOR env var", func(t *testing.T) {
 		t.Setenv("EDITOR", "true")
 		p := catalog.Project{}
-		editor, err := resolveEditor(p)
+		editor, err := resolveEditor(config.EditorConfig{}, p, "")
 		require.NoError(t, err)
 		assert.Equal(t, []string{"true"}, editor)
 	})
@@ -710,7 +914,7 @@ func TestResolveEditor(t *testing.T) {
 	t.Run("falls back to vim", func(t *testing.T) {
 		t.Setenv("EDITOR", "")
 		p := catalog.Project{}
-		editor, err := resolveEditor(p)
+		editor, err := resolveEditor(config.EditorConfig{}, p, "")
 		if err != nil {
 			assert.Contains(t, err.Error(), "not found in PATH")
 		} else {
@@ -720,7 +924,7 @@ func TestResolveEditor(t *testing.T) {
 
 	t.Run("returns error for missing editor", func(t *testing.T) {
 		p := catalog.Project{Editor: "nonexistent-editor-12345"}
-		_, err := resolveEditor(p)
+		_, err := resolveEditor(config.EditorConfig{}, p, "")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not found in PATH")
 	})
@@ -728,11 +932,85 @@ func TestResolveEditor(t *testing.T) {
 	t.Run("returns error for empty editor", func(t *testing.T) {
 		t.Setenv("EDITOR", "   ")
 		p := catalog.Project{Editor: "   "}
-		_, err := resolveEditor(p)
+		_, err := resolveEditor(config.EditorConfig{}, p, "")
 		assert.Error(t, err)
 	})
 }
 
+func TestChooseEditor(t *testing.T) {
+	t.Setenv("EDITOR", "nano")
+	cfg := config.EditorConfig{
+		Default:  "nvim",
+		Profiles: map[string]string{"goland": "goland --wait", "jupyter": "jupyter lab"},
+		Rules: []config.EditorRule{
+			{Language: "go", Editor: "goland"},
+			{Path: "/srv/notebooks", Editor: "jupyter"},
+			{Tag: "scratch", Editor: "true"},
+		},
+	}
+	goProject := catalog.NewProject("api", "/srv/api").WithStack(&catalog.Stack{Languages: []string{"go"}})
+
+	tests := []struct {
+		name    string
+		cfg     config.EditorConfig
+		project catalog.Project
+		with    string
+		want    editorChoice
+	}{
+		{"with flag wins", cfg, goProject.WithEditor("code"), "jupyter", editorChoice{"jupyter lab", "--with jupyter"}},
+		{"project setting beats rules", cfg, goProject.WithEditor("code"), "", editorChoice{"code", "project setting"}},
+		{"language rule", cfg, goProject, "", editorChoice{"goland --wait", "rule 1: language go"}},
+		{"path rule covers subdirectories", cfg, catalog.NewProject("nb", "/srv/notebooks/ml"), "", editorChoice{"jupyter lab", "rule 2: path /srv/notebooks"}},
+		{"tag rule", cfg, catalog.NewProject("x", "/tmp/x").WithTags("scratch"), "", editorChoice{"true", "rule 3: tag scratch"}},
+		{"config default", cfg, catalog.NewProject("x", "/tmp/x"), "", editorChoice{"nvim", "config default"}},
+		{"EDITOR without config", config.EditorConfig{}, goProject, "", editorChoice{"nano", "$EDITOR"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, chooseEditor(tc.cfg, tc.project, tc.with))
+		})
+	}
+
+	t.Run("falls back to vim", func(t *testing.T) {
+		t.Setenv("EDITOR", "")
+		assert.Equal(t, editorChoice{"vim", "built-in default"}, chooseEditor(config.EditorConfig{}, goProject, ""))
+	})
+}
+
+func TestOpenCmd_With(t *testing.T) {
+	g, _ := newTestGlobals(t)
+	g.Config.Editors = config.EditorConfig{
+		Default:  "false",
+		Profiles: map[string]string{"quick": "true --quick"},
+	}
+	projectDir := createTestProject(t, g, "test-project")
+	var gotName string
+	var gotArgs []string
+	g.RunCmd = func(name string, args ...string) error {
+		gotName, gotArgs = name, args
+		return nil
+	}
+
+	require.NoError(t, (&OpenCmd{Name: "test-project", With: "quick"}).Run(g))
+
+	assert.Equal(t, "true", gotName)
+	assert.Equal(t, []string{"--quick", projectDir}, gotArgs)
+}
+
+func TestShowCmd_ExplainsEditor(t *testing.T) {
+	g, out := newTestGlobals(t)
+	g.Config.Editors = config.EditorConfig{
+		Rules: []config.EditorRule{{Tag: "work", Editor: "code"}},
+	}
+	createTestProject(t, g, "test-project")
+	require.NoError(t, (&EditCmd{Name: "test-project", Tag: []string{"work"}}).Run(g))
+	out.Reset()
+
+	require.NoError(t, (&ShowCmd{Name: "test-project"}).Run(g))
+
+	assert.Contains(t, out.String(), "Editor: code (rule 1: tag work)\n")
+}
+
 func TestFindProject(t *testing.T) {
 	t.Run("returns exact match", func(t *testing.T) {
 		g, _ := newTestGlobals(t)
@@ -885,7 +1163,7 @@ func addProjectWithTime(t *testing.T, g *Globals, pathMap map[string]string, nam
 	p := catalog.NewProject(name, projectDir)
 	p.Description = description
 	p.LastAccessed = mtime
-	require.NoError(t, g.Cat.Add(p))
+	mutateCatalog(t, g, func(tx catalog.Tx) error { return tx.Add(p) })
 	pathMap[projectDir] = "/home/user/projects/" + name
 }
 
@@ -896,3 +1174,31 @@ func normalizePaths(output string, pathMap map[string]string) string {
 	}
 	return result
 }
+
+func TestUnavailableProjects(t *testing.T) {
+	newGlobalsWithMissingProject := func(t *testing.T) (*Globals, *bytes.Buffer) {
+		t.Helper()
+		catalogPath := filepath.Join(t.TempDir(), "catalog.yaml")
+		content := "version: 1\nprojects:\n  - id: p1\n    name: laptop-only\n    path: ${work}/api\n"
+		require.NoError(t, os.WriteFile(catalogPath, []byte(content), 0o644))
+		g, out := newTestGlobals(t)
+		g.Cat = openTestCatalog(t, catalogPath)
+		return g, out
+	}
+
+	t.Run("list marks project as not on this host", func(t *testing.T) {
+		g, out := newGlobalsWithMissingProject(t)
+
+		require.NoError(t, (&ListCmd{}).Run(g))
+
+		assert.Contains(t, out.String(), "(not on this host)")
+	})
+
+	t.Run("show reports the project is not available", func(t *testing.T) {
+		g, out := newGlobalsWithMissingProject(t)
+
+		require.NoError(t, (&ShowCmd{Name: "laptop-only"}).Run(g))
+
+		assert.Contains(t, out.String(), "Host:   not available")
+	})
+}
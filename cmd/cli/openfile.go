@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pj/internal/config"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// fileTarget is a file inside a project, optionally at a line and column.
+// The zero value opens the project itself.
+type fileTarget struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+func (t fileTarget) String() string {
+	s := t.Path
+	if t.Line > 0 {
+		s += ":" + strconv.Itoa(t.Line)
+	}
+	if t.Column > 0 {
+		s += ":" + strconv.Itoa(t.Column)
+	}
+	return s
+}
+
+// parseFileTarget parses "file[:line[:column]]" with file relative to
+// projectDir or absolute inside it.
+func parseFileTarget(projectDir, arg string) (fileTarget, error) {
+	var t fileTarget
+	file := arg
+	var nums []int
+	for range 2 {
+		rest, last, ok := cutLast(file, ":")
+		if !ok {
+			break
+		}
+		n, err := strconv.Atoi(last)
+		if err != nil || n < 1 {
+			break
+		}
+		nums = append(nums, n)
+		file = rest
+	}
+	slices.Reverse(nums)
+	if len(nums) > 0 {
+		t.Line = nums[0]
+	}
+	if len(nums) > 1 {
+		t.Column = nums[1]
+	}
+
+	if file == "" {
+		return t, fmt.Errorf("no file in %q", arg)
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(projectDir, file)
+	}
+	rel, err := filepath.Rel(projectDir, filepath.Clean(file))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return t, fmt.Errorf("%s is not a file inside %s", arg, projectDir)
+	}
+	t.Path = rel
+	return t, nil
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+// editorStyle is how an editor takes a file position on its command line.
+type editorStyle int
+
+const (
+	stylePlain     editorStyle = iota // file
+	stylePlusLine                     // +line file
+	stylePlusColon                    // +line:column file
+	stylePlusComma                    // +line,column file
+	styleGoto                         // project -g file:line:column
+	styleColon                        // project file:line:column
+	styleColonOnly                    // file:line:column
+	styleJetBrains                    // project --line line --column column file
+)
+
+var editorStyles = map[string]editorStyle{
+	"vi":            stylePlusLine,
+	"vim":           stylePlusLine,
+	"nvim":          stylePlusLine,
+	"gvim":          stylePlusLine,
+	"mvim":          stylePlusLine,
+	"emacs":         stylePlusColon,
+	"emacsclient":   stylePlusColon,
+	"kak":           stylePlusColon,
+	"micro":         stylePlusColon,
+	"nano":          stylePlusComma,
+	"code":          styleGoto,
+	"code-insiders": styleGoto,
+	"codium":        styleGoto,
+	"cursor":        styleGoto,
+	"windsurf":      styleGoto,
+	"subl":          styleColon,
+	"zed":           styleColon,
+	"hx":            styleColonOnly,
+	"helix":         styleColonOnly,
+	"idea":          styleJetBrains,
+	"goland":        styleJetBrains,
+	"pycharm":       styleJetBrains,
+	"webstorm":      styleJetBrains,
+	"clion":         styleJetBrains,
+	"rubymine":      styleJetBrains,
+	"phpstorm":      styleJetBrains,
+	"rider":         styleJetBrains,
+	"rustrover":     styleJetBrains,
+}
+
+// fileArgs returns the arguments that make editor open t inside
+// projectDir, following the editor's own conventions.
+func fileArgs(editor, projectDir string, t fileTarget) []string {
+	file := filepath.Join(projectDir, t.Path)
+	position := strconv.Itoa(t.Line)
+	colonPos := file
+	if t.Line > 0 {
+		colonPos += ":" + position
+		if t.Column > 0 {
+			colonPos += ":" + strconv.Itoa(t.Column)
+		}
+	}
+
+	style := editorStyles[strings.TrimSuffix(filepath.Base(editor), ".exe")]
+	switch style {
+	case stylePlusLine, stylePlusColon, stylePlusComma:
+		if t.Line == 0 {
+			return []string{file}
+		}
+		if t.Column > 0 && style == stylePlusColon {
+			position += ":" + strconv.Itoa(t.Column)
+		} else if t.Column > 0 && style == stylePlusComma {
+			position += "," + strconv.Itoa(t.Column)
+		}
+		return []string{"+" + position, file}
+	case styleGoto:
+		return []string{projectDir, "-g", colonPos}
+	case styleColon:
+		return []string{projectDir, colonPos}
+	case styleColonOnly:
+		return []string{colonPos}
+	case styleJetBrains:
+		args := []string{projectDir}
+		if t.Line > 0 {
+			args = append(args, "--line", position)
+			if t.Column > 0 {
+				args = append(args, "--column", strconv.Itoa(t.Column))
+			}
+		}
+		return append(args, file)
+	default:
+		return []string{file}
+	}
+}
+
+// lastFilesPath stores, per project ID, the file `pj open --last` reopens.
+func lastFilesPath() string {
+	return filepath.Join(config.DefaultStateDir(), "last-files.json")
+}
+
+func loadLastFiles() map[string]fileTarget {
+	files := map[string]fileTarget{}
+	data, err := os.ReadFile(lastFilesPath())
+	if err != nil {
+		return files
+	}
+	_ = json.Unmarshal(data, &files)
+	return files
+}
+
+// recordLastFile is best effort: losing the record only disables --last.
+func recordLastFile(projectID string, t fileTarget) {
+	files := loadLastFiles()
+	files[projectID] = t
+	data, err := json.Marshal(files)
+	if err != nil {
+		return
+	}
+	path := lastFilesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o600)
+}
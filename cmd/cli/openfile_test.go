@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileTarget(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want fileTarget
+	}{
+		{"main.go", fileTarget{Path: "main.go"}},
+		{"cmd/server/main.go:42", fileTarget{Path: "cmd/server/main.go", Line: 42}},
+		{"cmd/server/main.go:42:7", fileTarget{Path: "cmd/server/main.go", Line: 42, Column: 7}},
+		{"/srv/api/go.mod:3", fileTarget{Path: "go.mod", Line: 3}},
+		{"notes:todo.md", fileTarget{Path: "notes:todo.md"}},
+		{"./docs/../README.md", fileTarget{Path: "README.md"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.arg, func(t *testing.T) {
+			got, err := parseFileTarget("/srv/api", tc.arg)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	for _, arg := range []string{"../web/main.go", "/etc/passwd", ".", ":42"} {
+		t.Run("rejects "+arg, func(t *testing.T) {
+			_, err := parseFileTarget("/srv/api", arg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFileArgs(t *testing.T) {
+	at := fileTarget{Path: "main.go", Line: 42, Column: 7}
+	tests := []struct {
+		editor string
+		target fileTarget
+		want   []string
+	}{
+		{"nvim", at, []string{"+42", "/p/main.go"}},
+		{"/usr/bin/vim", fileTarget{Path: "main.go"}, []string{"/p/main.go"}},
+		{"emacsclient", at, []string{"+42:7", "/p/main.go"}},
+		{"nano", at, []string{"+42,7", "/p/main.go"}},
+		{"code", at, []string{"/p", "-g", "/p/main.go:42:7"}},
+		{"cursor", fileTarget{Path: "main.go", Line: 42}, []string{"/p", "-g", "/p/main.go:42"}},
+		{"zed", at, []string{"/p", "/p/main.go:42:7"}},
+		{"hx", at, []string{"/p/main.go:42:7"}},
+		{"goland", at, []string{"/p", "--line", "42", "--column", "7", "/p/main.go"}},
+		{"ed", at, []string{"/p/main.go"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.editor, func(t *testing.T) {
+			assert.Equal(t, tc.want, fileArgs(tc.editor, "/p", tc.target))
+		})
+	}
+}
+
+func TestOpenCmd_File(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	bin := t.TempDir()
+	truePath, err := exec.LookPath("true")
+	require.NoError(t, err)
+	require.NoError(t, os.Symlink(truePath, filepath.Join(bin, "nvim")))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("EDITOR", "nvim")
+
+	g, _ := newTestGlobals(t)
+	projectDir := createTestProject(t, g, "api")
+	var gotArgs []string
+	g.RunCmd = func(name string, args ...string) error {
+		gotArgs = args
+		return nil
+	}
+	mainGo := filepath.Join(projectDir, "cmd", "main.go")
+
+	t.Run("last fails before any file was opened", func(t *testing.T) {
+		err := (&OpenCmd{Name: "api", Last: true}).Run(g)
+		assert.EqualError(t, err, "no file has been opened in api yet")
+	})
+
+	t.Run("opens the file at a line", func(t *testing.T) {
+		require.NoError(t, (&OpenCmd{Name: "api", File: "cmd/main.go:12"}).Run(g))
+		assert.Equal(t, []string{"+12", mainGo}, gotArgs)
+	})
+
+	t.Run("last reopens the recorded file", func(t *testing.T) {
+		gotArgs = nil
+		require.NoError(t, (&OpenCmd{Name: "api", Last: true}).Run(g))
+		assert.Equal(t, []string{"+12", mainGo}, gotArgs)
+	})
+
+	t.Run("opening the project keeps the record", func(t *testing.T) {
+		require.NoError(t, (&OpenCmd{Name: "api"}).Run(g))
+		assert.Equal(t, []string{projectDir}, gotArgs)
+
+		require.NoError(t, (&OpenCmd{Name: "api", Last: true}).Run(g))
+		assert.Equal(t, []string{"+12", mainGo}, gotArgs)
+	})
+
+	t.Run("last cannot be combined with a file", func(t *testing.T) {
+		assert.Error(t, (&OpenCmd{Name: "api", Last: true, File: "x"}).Run(g))
+	})
+}
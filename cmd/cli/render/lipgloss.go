@@ -10,7 +10,25 @@ import (
 	"github.com/charmbracelet/x/term"
 )
 
-const staleThreshold = 30 * 24 * time.Hour
+// StaleThreshold is how long a project can go unmodified before it is shown
+// as stale.
+const StaleThreshold = 30 * 24 * time.Hour
+
+// languageAbbrevs keeps the language badge short for common languages.
+var languageAbbrevs = map[string]string{
+	"javascript": "js",
+	"typescript": "ts",
+	"python":     "py",
+	"rust":       "rs",
+	"ruby":       "rb",
+	"kotlin":     "kt",
+	"haskell":    "hs",
+	"elixir":     "ex",
+	"clojure":    "clj",
+	"c++":        "cpp",
+	"c#":         "cs",
+	"f#":         "fs",
+}
 
 type LipglossRenderer struct {
 	width int
@@ -22,6 +40,7 @@ type LipglossRenderer struct {
 	timeStyle       lipgloss.Style
 	staleStyle      lipgloss.Style
 	recentTimeStyle lipgloss.Style
+	badgeStyle      lipgloss.Style
 }
 
 func NewLipglossRenderer(width int) *LipglossRenderer {
@@ -34,6 +53,7 @@ func NewLipglossRenderer(width int) *LipglossRenderer {
 		timeStyle:       lipgloss.NewStyle().Faint(true),
 		recentTimeStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
 		staleStyle:      lipgloss.NewStyle().Faint(true),
+		badgeStyle:      lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
 	}
 }
 
@@ -67,27 +87,40 @@ func (r *LipglossRenderer) RenderProjectList(view ProjectListView) string {
 
 func (r *LipglossRenderer) renderItem(item ProjectListItem, now time.Time, last bool) string {
 	age := now.Sub(item.Timestamp)
-	isStale := age > staleThreshold
+	isStale := age > StaleThreshold
 	timeStr := r.formatTime(item.Timestamp, now)
 
 	nameStyle := r.nameStyle
 	pathStyle := r.pathStyle
 	descStyle := r.descStyle
 	timeStyle := r.timeStyle
+	badgeStyle := r.badgeStyle
 	if isStale {
 		nameStyle = r.staleStyle.Bold(true)
 		pathStyle = r.staleStyle
 		descStyle = r.staleStyle
 		timeStyle = r.staleStyle
+		badgeStyle = r.staleStyle
 	} else if age < 1*time.Hour {
 		timeStyle = r.recentTimeStyle
 	}
 
 	name := nameStyle.Render(item.Name)
-	path := pathStyle.Render("  " + config.ShortenPath(item.Path))
+	if badge := languageBadge(item.Languages); badge != "" {
+		name += " " + badgeStyle.Render(badge)
+	}
+	if item.Status != "" && item.Status != "active" {
+		name += " " + r.staleStyle.Render("("+item.Status+")")
+	}
+	pathStr := "  " + config.ShortenPath(item.Path)
+	if item.Unavailable {
+		pathStr += "  (not on this host)"
+	}
+	path := pathStyle.Render(pathStr)
 	timeEl := timeStyle.Render(timeStr)
 
-	padding := max(1, r.width-lipgloss.Width(name)-lipgloss.Width(timeEl))
+	indent := strings.Repeat("  ", item.Depth)
+	padding := max(1, r.width-len(indent)-lipgloss.Width(name)-lipgloss.Width(timeEl))
 	headerLine := name + strings.Repeat(" ", padding) + timeEl
 
 	var lines []string
@@ -97,6 +130,9 @@ func (r *LipglossRenderer) renderItem(item ProjectListItem, now time.Time, last
 		desc := descStyle.Render("  " + item.Description)
 		lines = append(lines, desc)
 	}
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
 	if !last {
 		lines = append(lines, "", "")
 	}
@@ -104,6 +140,17 @@ func (r *LipglossRenderer) renderItem(item ProjectListItem, now time.Time, last
 	return strings.Join(lines, "\n")
 }
 
+func languageBadge(languages []string) string {
+	short := make([]string, len(languages))
+	for i, l := range languages {
+		if abbrev, ok := languageAbbrevs[l]; ok {
+			l = abbrev
+		}
+		short[i] = l
+	}
+	return strings.Join(short, "·")
+}
+
 func (r *LipglossRenderer) formatTime(t, now time.Time) string {
 	if t.IsZero() {
 		return "Unknown"
@@ -15,6 +15,12 @@ type ProjectListItem struct {
 	Path        string
 	Description string
 	Timestamp   time.Time
+	Unavailable bool
+	Languages   []string
+	// Status is shown for projects that are not active.
+	Status string
+	// Depth nests sub-projects under their parent.
+	Depth int
 }
 
 func (v ProjectListView) IsEmpty() bool {
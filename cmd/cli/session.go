@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/config"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// sessionEnv names the per-shell id set by the `pj init` wrapper. It keys
+// the history behind `pj cd -`.
+const sessionEnv = "__PJ_SESSION"
+
+// sessionTTL is how long an untouched session file survives; shells that
+// have exited never clean up after themselves.
+const sessionTTL = 30 * 24 * time.Hour
+
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// shellSession records the directories the shell last changed into through
+// pj.
+type shellSession struct {
+	Current  string
+	Previous string
+}
+
+func sessionDir() string {
+	return filepath.Join(config.DefaultStateDir(), "sessions")
+}
+
+func sessionID() (string, bool) {
+	id := os.Getenv(sessionEnv)
+	if !sessionIDPattern.MatchString(id) || strings.Trim(id, ".") == "" {
+		return "", false
+	}
+	return id, true
+}
+
+func loadSession(id string) shellSession {
+	data, err := os.ReadFile(filepath.Join(sessionDir(), id))
+	if err != nil {
+		return shellSession{}
+	}
+	current, previous, _ := strings.Cut(strings.TrimRight(string(data), "\n"), "\n")
+	return shellSession{Current: current, Previous: previous}
+}
+
+func (s shellSession) save(id string) error {
+	dir := sessionDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	pruneSessions(dir)
+	return os.WriteFile(filepath.Join(dir, id), []byte(s.Current+"\n"+s.Previous+"\n"), 0o600)
+}
+
+func pruneSessions(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > sessionTTL {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+// recordVisit notes that the shell is changing into dir. It is best effort:
+// without a session `pj cd -` is simply unavailable.
+func recordVisit(dir string) {
+	id, ok := sessionID()
+	if !ok {
+		return
+	}
+	s := loadSession(id)
+	if s.Current == dir {
+		return
+	}
+	_ = shellSession{Current: dir, Previous: s.Current}.save(id)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/config"
+	"pj/internal/detect"
+	"slices"
+	"strings"
+)
+
+// syncSubprojects makes the packages of the monorepo at parent's path its
+// sub-projects. Existing top-level projects at those paths are adopted,
+// and sub-projects that are no longer declared are removed.
+func syncSubprojects(tx catalog.Tx, cfg config.Config, parent catalog.Project) (added, removed []catalog.Project, err error) {
+	members := detect.Members(parent.Path, cfg.Monorepos[parent.Name])
+
+	for _, dir := range members {
+		p, err := tx.GetByPath(dir)
+		switch {
+		case errors.Is(err, catalog.ErrNotFound):
+			p = catalog.NewProject(filepath.Base(dir), dir).
+				WithStack(detect.Dir(dir)).
+				WithParent(parent.ID)
+			if err := tx.Add(p); err != nil {
+				return added, removed, err
+			}
+			added = append(added, p)
+		case err != nil:
+			return added, removed, err
+		case p.Parent == "" && p.ID != parent.ID:
+			p = p.WithParent(parent.ID)
+			if err := tx.Update(p); err != nil {
+				return added, removed, err
+			}
+			added = append(added, p)
+		}
+	}
+
+	for _, p := range subprojects(tx, parent.ID) {
+		if slices.Contains(members, p.Path) {
+			continue
+		}
+		for _, gone := range append(descendants(tx, p.ID), p) {
+			if err := tx.Remove(gone.ID); err != nil {
+				return added, removed, err
+			}
+			removed = append(removed, gone)
+		}
+	}
+	return added, removed, nil
+}
+
+// lister is what sub-projects are looked up in: the catalog, or a
+// transaction on it.
+type lister interface {
+	List() []catalog.Project
+}
+
+func subprojects(cat lister, parentID string) []catalog.Project {
+	var children []catalog.Project
+	for _, p := range cat.List() {
+		if p.Parent == parentID {
+			children = append(children, p)
+		}
+	}
+	return children
+}
+
+// descendants lists the sub-projects of id, their sub-projects and so on.
+func descendants(cat lister, id string) []catalog.Project {
+	var all []catalog.Project
+	for _, p := range subprojects(cat, id) {
+		all = append(all, p)
+		all = append(all, descendants(cat, p.ID)...)
+	}
+	return all
+}
+
+// descendSubprojects follows leading segments of sub that name
+// sub-projects of p, so "api/src" inside a monorepo starts in its api
+// package. It returns the innermost project and what is left of sub.
+func descendSubprojects(cat lister, p catalog.Project, sub string) (catalog.Project, string) {
+	for sub != "" {
+		segment, rest, _ := strings.Cut(sub, "/")
+		children := subprojects(cat, p.ID)
+		i := slices.IndexFunc(children, func(c catalog.Project) bool {
+			return c.Name == segment
+		})
+		if i < 0 {
+			break
+		}
+		p, sub = children[i], rest
+	}
+	return p, sub
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createMonorepo adds a pnpm monorepo named mono with packages a and b.
+func createMonorepo(t *testing.T, g *Globals) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "mono")
+	for _, pkg := range []string{"a", "b"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "packages", "pkg-"+pkg, "src"), 0o755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pnpm-workspace.yaml"), []byte("packages:\n  - packages/*\n"), 0o644))
+	require.NoError(t, (&AddCmd{Path: dir}).Run(g))
+	return dir
+}
+
+func TestAddCmd_Subprojects(t *testing.T) {
+	g, out := newTestGlobals(t)
+	dir := createMonorepo(t, g)
+
+	assert.Equal(t, "Added: mono ("+dir+")\n"+
+		"Added sub-project: mono/pkg-a ("+filepath.Join(dir, "packages", "pkg-a")+")\n"+
+		"Added sub-project: mono/pkg-b ("+filepath.Join(dir, "packages", "pkg-b")+")\n", out.String())
+
+	mono, err := findProject(g.Cat, "mono")
+	require.NoError(t, err)
+	child, err := findProject(g.Cat, "mono/pkg-a")
+	require.NoError(t, err)
+	assert.Equal(t, mono.ID, child.Parent)
+}
+
+func TestAddCmd_ConfiguredSubprojects(t *testing.T) {
+	g, out := newTestGlobals(t)
+	dir := filepath.Join(t.TempDir(), "infra")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "stacks", "dns"), 0o755))
+	g.Config.Monorepos = map[string][]string{"infra": {"stacks/*"}}
+
+	require.NoError(t, (&AddCmd{Path: dir}).Run(g))
+
+	assert.Contains(t, out.String(), "Added sub-project: infra/dns")
+}
+
+func TestSyncSubprojects_AdoptsExistingProjects(t *testing.T) {
+	g, _ := newTestGlobals(t)
+	dir := filepath.Join(t.TempDir(), "mono")
+	pkg := filepath.Join(dir, "packages", "pkg-a")
+	require.NoError(t, os.MkdirAll(pkg, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pnpm-workspace.yaml"), []byte("packages:\n  - packages/*\n"), 0o644))
+	require.NoError(t, (&AddCmd{Path: pkg}).Run(g))
+	mono := catalog.NewProject("mono", dir)
+
+	var added []catalog.Project
+	mutateCatalog(t, g, func(tx catalog.Tx) error {
+		if err := tx.Add(mono); err != nil {
+			return err
+		}
+		var err error
+		added, _, err = syncSubprojects(tx, g.Config, mono)
+		return err
+	})
+
+	require.Len(t, added, 1)
+	assert.Equal(t, pkg, added[0].Path)
+	assert.Equal(t, mono.ID, added[0].Parent)
+}
+
+func TestListCmd_NestsSubprojects(t *testing.T) {
+	g, out := newTestGlobals(t)
+	createMonorepo(t, g)
+	createTestProject(t, g, "zeta")
+	out.Reset()
+
+	require.NoError(t, (&ListCmd{FilterFlags: FilterFlags{Sort: "name"}}).Run(g))
+	output := out.String()
+	assert.Contains(t, output, "\n  \x1b[1m")
+	assert.Less(t, strings.Index(output, "mono"), strings.Index(output, "pkg-a"))
+	assert.Less(t, strings.Index(output, "pkg-b"), strings.Index(output, "zeta"))
+
+	out.Reset()
+	require.NoError(t, (&ListCmd{Names: true, FilterFlags: FilterFlags{Sort: "name"}}).Run(g))
+	assert.Equal(t, "mono\nmono/pkg-a\nmono/pkg-b\nzeta\n", out.String())
+}
+
+func TestCdCmd_Subprojects(t *testing.T) {
+	g, _ := newTestGlobals(t)
+	dir := createMonorepo(t, g)
+	pkgA := filepath.Join(dir, "packages", "pkg-a")
+
+	got, err := resolveCdTarget(g.Cat, "mono/pkg-a")
+	require.NoError(t, err)
+	assert.Equal(t, pkgA, got)
+
+	got, err = resolveCdTarget(g.Cat, "mono/pkg-a/src")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(pkgA, "src"), got)
+
+	got, err = resolveCdTarget(g.Cat, "mono/packages")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "packages"), got)
+
+	assert.Equal(t, []string{"mono/packages/", "mono/pkg-a/", "mono/pkg-b/"}, cdCompletions(g.Cat, "mono/p"))
+	assert.Equal(t, []string{"mono/pkg-a/src/"}, cdCompletions(g.Cat, "mono/pkg-a/"))
+}
+
+func TestRefreshCmd_Subprojects(t *testing.T) {
+	g, out := newTestGlobals(t)
+	dir := createMonorepo(t, g)
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "packages", "pkg-b")))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "packages", "pkg-c"), 0o755))
+	out.Reset()
+
+	require.NoError(t, (&RefreshCmd{Name: "mono"}).Run(g))
+
+	assert.Contains(t, out.String(), "Added sub-project: mono/pkg-c")
+	assert.Contains(t, out.String(), "Removed sub-project: mono/pkg-b")
+	_, err := findProject(g.Cat, "pkg-b")
+	assert.Error(t, err)
+}
+
+func TestRmCmd_RemovesSubprojects(t *testing.T) {
+	g, out := newTestGlobals(t)
+	createMonorepo(t, g)
+	createTestProject(t, g, "other")
+	out.Reset()
+
+	require.NoError(t, (&RmCmd{Name: "mono"}).Run(g))
+
+	assert.Equal(t, "Removed: mono\nRemoved 2 sub-projects\n", out.String())
+	assert.Equal(t, 1, g.Cat.Count())
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pj/internal/catalog"
+	"pj/internal/git"
+	"pj/pkg/pj"
+	"slices"
+)
+
+var errNoWorktree = errors.New("no such worktree")
+
+// detectWorktrees lists the linked worktrees of the repository whose main
+// worktree is dir. It reports false when dir is not one.
+func detectWorktrees(dir string) ([]catalog.Worktree, bool) {
+	ctx := context.Background()
+	if !git.IsMainWorktree(ctx, dir) {
+		return nil, false
+	}
+	list, err := git.Worktrees(ctx, dir)
+	if err != nil {
+		return nil, false
+	}
+	var worktrees []catalog.Worktree
+	for _, w := range list[1:] {
+		if w.Bare || w.Prunable {
+			continue
+		}
+		worktrees = append(worktrees, catalog.Worktree{Branch: w.Branch, Path: w.Path})
+	}
+	return worktrees, true
+}
+
+// syncWorktrees stores the project's current worktrees in the catalog.
+func syncWorktrees(cat *pj.Catalog, p catalog.Project) (catalog.Project, error) {
+	worktrees, ok := detectWorktrees(p.Path)
+	if !ok || slices.Equal(worktrees, p.Worktrees) {
+		return p, nil
+	}
+	err := mutate(cat, func(tx catalog.Tx) error {
+		current, err := tx.Get(p.ID)
+		if err != nil {
+			return err
+		}
+		p = current.WithWorktrees(worktrees)
+		return tx.Update(p)
+	})
+	if err != nil {
+		return p, fmt.Errorf("failed to update project %q: %w", p.Name, err)
+	}
+	return p, nil
+}
+
+// findProjectDir resolves "name" or "name@worktree" to the project and the
+// directory to work in. Worktrees created outside pj are picked up on the
+// first miss.
+func findProjectDir(cat *pj.Catalog, query string) (catalog.Project, string, error) {
+	name, worktree, found := cutLast(query, "@")
+	if !found || name == "" || worktree == "" {
+		p, err := findProject(cat, query)
+		return p, p.Path, err
+	}
+
+	p, err := findProject(cat, name)
+	if errors.Is(err, pj.ErrNoMatch) {
+		// Names such as "@scope/pkg" contain an @ of their own.
+		p, err = findProject(cat, query)
+		return p, p.Path, err
+	}
+	if err != nil {
+		return p, "", err
+	}
+
+	w, ok := p.Worktree(worktree)
+	if !ok {
+		if p, err = syncWorktrees(cat, p); err != nil {
+			return p, "", err
+		}
+		w, ok = p.Worktree(worktree)
+	}
+	if !ok {
+		return p, "", fmt.Errorf("%w %q in project %s", errNoWorktree, worktree, p.Name)
+	}
+	return p, w.Path, nil
+}
+
+// mainProjectOf finds the cataloged project whose linked worktree is dir.
+func mainProjectOf(cat *pj.Catalog, dir string) (catalog.Project, bool) {
+	main, ok := git.MainOfLinked(context.Background(), dir)
+	if !ok {
+		return catalog.Project{}, false
+	}
+	p, err := cat.GetByPath(main)
+	return p, err == nil
+}
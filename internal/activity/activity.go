@@ -0,0 +1,120 @@
+// Package activity reads the heartbeats the `pj init` shell integration
+// logs and turns them into time spent in each directory.
+//
+// The log has one "<unix seconds>\t<directory>" line per heartbeat. Shells
+// write a heartbeat when they show a prompt, at most once a minute while
+// they stay in the same directory. Heartbeats older than Retention are
+// pruned so the log does not grow without bound.
+package activity
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention is how long heartbeats are kept, and so how far back a report
+// can go.
+const Retention = 366 * 24 * time.Hour
+
+// Heartbeat says a shell was active in Dir at Time.
+type Heartbeat struct {
+	Time time.Time
+	Dir  string
+}
+
+// Span is time attributed to the directory of the heartbeat it starts at.
+type Span struct {
+	Start    time.Time
+	Duration time.Duration
+	Dir      string
+}
+
+// Read returns the heartbeats in r at or after since, oldest first.
+// Malformed lines, such as a partial last line, are skipped.
+func Read(r io.Reader, since time.Time) ([]Heartbeat, error) {
+	var beats []Heartbeat
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		ts, dir, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok || dir == "" {
+			continue
+		}
+		secs, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			continue
+		}
+		t := time.Unix(secs, 0)
+		if t.Before(since) {
+			continue
+		}
+		beats = append(beats, Heartbeat{Time: t, Dir: dir})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// Several shells append to the same log, so lines can be out of order.
+	slices.SortStableFunc(beats, func(a, b Heartbeat) int { return a.Time.Compare(b.Time) })
+	return beats, nil
+}
+
+// Spans credits the time between consecutive heartbeats to the first of
+// them. A gap longer than idle means nobody was at the keyboard, so it
+// counts for nothing.
+func Spans(beats []Heartbeat, idle time.Duration) []Span {
+	var spans []Span
+	for i := 0; i+1 < len(beats); i++ {
+		gap := beats[i+1].Time.Sub(beats[i].Time)
+		if gap <= 0 || gap > idle {
+			continue
+		}
+		spans = append(spans, Span{Start: beats[i].Time, Duration: gap, Dir: beats[i].Dir})
+	}
+	return spans
+}
+
+// Prune drops the heartbeats logged at path before cutoff, leaving the
+// file untouched when there are none. Shells append without a lock, so a
+// heartbeat written while the log is replaced can be lost; that costs a
+// report at most a minute.
+func Prune(path string, cutoff time.Time) (err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var kept []byte
+	for line := range bytes.Lines(data) {
+		ts, _, _ := bytes.Cut(line, []byte("\t"))
+		if secs, err := strconv.ParseInt(string(ts), 10, 64); err == nil && time.Unix(secs, 0).Before(cutoff) {
+			continue
+		}
+		kept = append(kept, line...)
+	}
+	if len(kept) == len(data) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".heartbeats-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(kept); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
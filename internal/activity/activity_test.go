@@ -0,0 +1,62 @@
+package activity_test
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/activity"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	log := "1700000120\t/src/api\n" +
+		"garbage\n" +
+		"1700000000\t/src/old\n" +
+		"1700000060\t/src/web\n" +
+		"17000001"
+
+	beats, err := activity.Read(strings.NewReader(log), time.Unix(1700000030, 0))
+
+	require.NoError(t, err)
+	assert.Equal(t, []activity.Heartbeat{
+		{Time: time.Unix(1700000060, 0), Dir: "/src/web"},
+		{Time: time.Unix(1700000120, 0), Dir: "/src/api"},
+	}, beats)
+}
+
+func TestSpans(t *testing.T) {
+	at := func(min int) time.Time { return time.Unix(1700000000+int64(min)*60, 0) }
+	beats := []activity.Heartbeat{
+		{Time: at(0), Dir: "/a"},
+		{Time: at(5), Dir: "/a"},
+		{Time: at(8), Dir: "/b"},
+		// Lunch.
+		{Time: at(70), Dir: "/b"},
+		{Time: at(71), Dir: "/a"},
+	}
+
+	assert.Equal(t, []activity.Span{
+		{Start: at(0), Duration: 5 * time.Minute, Dir: "/a"},
+		{Start: at(5), Duration: 3 * time.Minute, Dir: "/a"},
+		{Start: at(70), Duration: time.Minute, Dir: "/b"},
+	}, activity.Spans(beats, 15*time.Minute))
+}
+
+func TestPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heartbeats.log")
+	log := "1700000000\t/src/old\n" +
+		"1700000120\t/src/api\n" +
+		"garbage\n" +
+		"1700000060\t/src/web\n"
+	require.NoError(t, os.WriteFile(path, []byte(log), 0o644))
+
+	require.NoError(t, activity.Prune(path, time.Unix(1700000060, 0)))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "1700000120\t/src/api\ngarbage\n1700000060\t/src/web\n", string(data))
+}
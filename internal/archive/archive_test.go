@@ -0,0 +1,110 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"pj/internal/archive"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateExtract(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "api")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "cmd", "empty"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "go.mod"), []byte("module api\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "cmd", "run.sh"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.Symlink("cmd/run.sh", filepath.Join(src, "run")))
+
+	dest := filepath.Join(t.TempDir(), "archive", "api.tar.gz")
+	require.NoError(t, archive.Create(src, dest))
+	entries, err := os.ReadDir(filepath.Dir(dest))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	restored := filepath.Join(t.TempDir(), "restored", "api")
+	require.NoError(t, archive.Extract(dest, restored))
+
+	data, err := os.ReadFile(filepath.Join(restored, "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, "module api\n", string(data))
+	info, err := os.Stat(filepath.Join(restored, "cmd", "run.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	assert.DirExists(t, filepath.Join(restored, "cmd", "empty"))
+	link, err := os.Readlink(filepath.Join(restored, "run"))
+	require.NoError(t, err)
+	assert.Equal(t, "cmd/run.sh", link)
+}
+
+func TestExtract(t *testing.T) {
+	t.Run("refuses an existing directory", func(t *testing.T) {
+		src := t.TempDir()
+		dest := filepath.Join(t.TempDir(), "a.tar.gz")
+		require.NoError(t, archive.Create(src, dest))
+
+		assert.Error(t, archive.Extract(dest, t.TempDir()))
+	})
+
+	t.Run("refuses entries outside the directory", func(t *testing.T) {
+		path := writeTarball(t, tarEntry{&tar.Header{Name: "../escape", Mode: 0o644, Typeflag: tar.TypeReg}, "x"})
+
+		parent := t.TempDir()
+		err := archive.Extract(path, filepath.Join(parent, "project"))
+		assert.ErrorContains(t, err, "outside the project")
+		entries, err := os.ReadDir(parent)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	for _, target := range []string{"..", "/"} {
+		t.Run("refuses writing through a symlink to "+target, func(t *testing.T) {
+			outside := t.TempDir()
+			if target == "/" {
+				target = outside
+			}
+			path := writeTarball(t,
+				tarEntry{&tar.Header{Name: "link", Linkname: target, Typeflag: tar.TypeSymlink}, ""},
+				tarEntry{&tar.Header{Name: "link/pwned", Mode: 0o644, Typeflag: tar.TypeReg}, "x"},
+			)
+
+			parent := t.TempDir()
+			err := archive.Extract(path, filepath.Join(parent, "project"))
+
+			assert.Error(t, err)
+			assert.NoFileExists(t, filepath.Join(outside, "pwned"))
+			assert.NoFileExists(t, filepath.Join(parent, "pwned"))
+			entries, err := os.ReadDir(parent)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+type tarEntry struct {
+	hdr  *tar.Header
+	body string
+}
+
+// writeTarball writes entries, as crafted by hand, to a .tar.gz file.
+func writeTarball(t *testing.T, entries ...tarEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "evil.tar.gz")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		e.hdr.Size = int64(len(e.body))
+		require.NoError(t, tw.WriteHeader(e.hdr))
+		_, err = tw.Write([]byte(e.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+	return path
+}
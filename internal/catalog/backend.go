@@ -0,0 +1,93 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"pj/internal/config"
+	"strings"
+)
+
+type Backend string
+
+const (
+	BackendYAML   Backend = "yaml"
+	BackendSQLite Backend = "sqlite"
+)
+
+var ErrUnknownBackend = errors.New("unknown catalog backend")
+
+// BackendForPath picks the backend from the catalog file's extension,
+// falling back to fallback (or YAML) for anything else.
+func BackendForPath(path string, fallback Backend) Backend {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return BackendSQLite
+	case ".yaml", ".yml":
+		return BackendYAML
+	}
+	if fallback == "" {
+		return BackendYAML
+	}
+	return fallback
+}
+
+// PathForBackend swaps the extension of path for the one backend uses by
+// default.
+func PathForBackend(path string, backend Backend) string {
+	ext := ".yaml"
+	if backend == BackendSQLite {
+		ext = ".db"
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ext
+}
+
+// Open creates and loads the catalog at path using backend.
+func Open(path string, backend Backend, m config.PathMapper) (Catalog, error) {
+	var cat Catalog
+	switch backend {
+	case BackendYAML:
+		c, err := NewYAMLCatalog(path)
+		if err != nil {
+			return nil, err
+		}
+		cat = c.WithPathMapper(m)
+	case BackendSQLite:
+		c, err := NewSQLiteCatalog(path)
+		if err != nil {
+			return nil, err
+		}
+		cat = c.WithPathMapper(m)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, backend)
+	}
+
+	if err := cat.Load(); err != nil {
+		return nil, err
+	}
+	return cat, nil
+}
+
+// ReadSnapshot reads the catalog at path as stored, without resolving paths.
+func ReadSnapshot(path string, backend Backend) (Snapshot, error) {
+	switch backend {
+	case BackendYAML:
+		return ReadFile(path)
+	case BackendSQLite:
+		return readSQLiteFile(path)
+	default:
+		return Snapshot{}, fmt.Errorf("%w: %s", ErrUnknownBackend, backend)
+	}
+}
+
+// WriteSnapshot replaces the catalog at path with snap.
+func WriteSnapshot(path string, backend Backend, snap Snapshot) error {
+	switch backend {
+	case BackendYAML:
+		return WriteFile(path, snap)
+	case BackendSQLite:
+		return writeSQLiteFile(path, snap)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownBackend, backend)
+	}
+}
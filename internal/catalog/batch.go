@@ -0,0 +1,93 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tx is the view of a catalog inside Catalog.Batch. Reads see the batch's
+// own pending changes.
+type Tx interface {
+	Add(p Project) error
+	Update(p Project) error
+	Remove(id string) error
+	Get(id string) (Project, error)
+	GetByPath(path string) (Project, error)
+	List() []Project
+
+	AddWorkspace(w Workspace) error
+	GetWorkspace(name string) (Workspace, error)
+	UpdateWorkspace(w Workspace) error
+	RemoveWorkspace(name string) error
+	ListWorkspaces() []Workspace
+}
+
+// ItemError is a failed mutation inside a batch. Index counts the batch's
+// mutations from zero.
+type ItemError struct {
+	Index int
+	Op    string
+	Item  string
+	Err   error
+}
+
+func (e ItemError) Error() string {
+	return fmt.Sprintf("item %d (%s %s): %v", e.Index, e.Op, e.Item, e.Err)
+}
+
+func (e ItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError reports every mutation that failed in a rejected batch.
+type BatchError struct {
+	Items []ItemError
+}
+
+func (e *BatchError) Error() string {
+	lines := make([]string, 0, len(e.Items)+1)
+	lines = append(lines, fmt.Sprintf("batch rejected, %d invalid items:", len(e.Items)))
+	for _, item := range e.Items {
+		lines = append(lines, "  "+item.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Items))
+	for i, item := range e.Items {
+		errs[i] = item
+	}
+	return errs
+}
+
+// batchRecorder numbers a batch's mutations and collects their failures.
+type batchRecorder struct {
+	next   int
+	failed []ItemError
+}
+
+func (r *batchRecorder) record(op, item string, err error) error {
+	index := r.next
+	r.next++
+	if err != nil {
+		r.failed = append(r.failed, ItemError{Index: index, Op: op, Item: item, Err: err})
+	}
+	return err
+}
+
+// finish decides a batch's outcome: any failed mutation rejects it, and
+// otherwise the callback's own error does.
+func (r *batchRecorder) finish(fnErr error) error {
+	if len(r.failed) > 0 {
+		return &BatchError{Items: r.failed}
+	}
+	return fnErr
+}
+
+func projectLabel(p Project) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
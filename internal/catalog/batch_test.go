@@ -0,0 +1,163 @@
+package catalog_test
+
+import (
+	"errors"
+	"path/filepath"
+	"pj/internal/catalog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchBackends opens each backend fresh; reopen reads back what was
+// persisted.
+var batchBackends = []struct {
+	name string
+	open func(t *testing.T) (cat catalog.Catalog, reopen func() catalog.Catalog)
+}{
+	{"yaml", func(t *testing.T) (catalog.Catalog, func() catalog.Catalog) {
+		path := filepath.Join(t.TempDir(), "catalog.yaml")
+		cat, err := catalog.NewYAMLCatalog(path)
+		require.NoError(t, err)
+		return cat, func() catalog.Catalog {
+			reopened, err := catalog.NewYAMLCatalog(path)
+			require.NoError(t, err)
+			require.NoError(t, reopened.Load())
+			return reopened
+		}
+	}},
+	{"sqlite", func(t *testing.T) (catalog.Catalog, func() catalog.Catalog) {
+		cat, path := newTestSQLiteCatalog(t)
+		return cat, func() catalog.Catalog {
+			reopened, err := catalog.NewSQLiteCatalog(path)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = reopened.Close() })
+			return reopened
+		}
+	}},
+}
+
+func TestCatalog_Batch(t *testing.T) {
+	for _, backend := range batchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			t.Run("applies and persists all mutations", func(t *testing.T) {
+				cat, reopen := backend.open(t)
+				old := catalog.NewProject("old", newTestDir(t))
+				require.NoError(t, cat.Add(old))
+				require.NoError(t, cat.Save())
+				a := catalog.NewProject("a", newTestDir(t))
+				b := catalog.NewProject("b", newTestDir(t))
+
+				err := cat.Batch(func(tx catalog.Tx) error {
+					if err := tx.Add(a); err != nil {
+						return err
+					}
+					if err := tx.Add(b); err != nil {
+						return err
+					}
+					if _, err := tx.GetByPath(a.Path); err != nil {
+						return err
+					}
+					return tx.Remove(old.ID)
+				})
+
+				require.NoError(t, err)
+				assert.Equal(t, 2, cat.Count())
+				assert.Equal(t, 2, reopen().Count())
+			})
+
+			t.Run("rejects everything when an item is invalid", func(t *testing.T) {
+				cat, reopen := backend.open(t)
+				dir := newTestDir(t)
+				good := catalog.NewProject("good", newTestDir(t))
+
+				err := cat.Batch(func(tx catalog.Tx) error {
+					_ = tx.Add(good)
+					_ = tx.Add(catalog.NewProject("", dir))
+					_ = tx.Add(catalog.NewProject("missing", filepath.Join(dir, "nope")))
+					_ = tx.Remove("unknown")
+					return nil
+				})
+
+				var batchErr *catalog.BatchError
+				require.ErrorAs(t, err, &batchErr)
+				require.Len(t, batchErr.Items, 3)
+				assert.Equal(t, 1, batchErr.Items[0].Index)
+				assert.ErrorIs(t, batchErr.Items[0], catalog.ErrEmptyName)
+				assert.Equal(t, "missing", batchErr.Items[1].Item)
+				assert.ErrorIs(t, batchErr.Items[1], catalog.ErrPathNotExist)
+				assert.Equal(t, "remove", batchErr.Items[2].Op)
+				assert.ErrorIs(t, err, catalog.ErrNotFound)
+				assert.Equal(t, 0, cat.Count())
+				assert.Equal(t, 0, reopen().Count())
+			})
+
+			t.Run("rolls back when callback fails", func(t *testing.T) {
+				cat, _ := backend.open(t)
+				boom := errors.New("boom")
+
+				err := cat.Batch(func(tx catalog.Tx) error {
+					require.NoError(t, tx.Add(catalog.NewProject("a", newTestDir(t))))
+					return boom
+				})
+
+				assert.ErrorIs(t, err, boom)
+				assert.Equal(t, 0, cat.Count())
+			})
+
+			t.Run("changes workspaces with projects", func(t *testing.T) {
+				cat, reopen := backend.open(t)
+				p := catalog.NewProject("api", newTestDir(t))
+
+				err := cat.Batch(func(tx catalog.Tx) error {
+					if err := tx.Add(p); err != nil {
+						return err
+					}
+					if err := tx.AddWorkspace(catalog.NewWorkspace("work", p.ID)); err != nil {
+						return err
+					}
+					w, err := tx.GetWorkspace("work")
+					if err != nil {
+						return err
+					}
+					w.Editor = "vim"
+					return tx.UpdateWorkspace(w)
+				})
+
+				require.NoError(t, err)
+				w, err := reopen().GetWorkspace("work")
+				require.NoError(t, err)
+				assert.Equal(t, []string{p.ID}, w.ProjectIDs)
+				assert.Equal(t, "vim", w.Editor)
+			})
+
+			t.Run("rejects workspaces with unknown projects", func(t *testing.T) {
+				cat, reopen := backend.open(t)
+
+				err := cat.Batch(func(tx catalog.Tx) error {
+					_ = tx.AddWorkspace(catalog.NewWorkspace("work", "unknown"))
+					return nil
+				})
+
+				var batchErr *catalog.BatchError
+				require.ErrorAs(t, err, &batchErr)
+				assert.Equal(t, "add workspace", batchErr.Items[0].Op)
+				assert.Empty(t, reopen().ListWorkspaces())
+			})
+
+			t.Run("sees its own duplicates", func(t *testing.T) {
+				cat, _ := backend.open(t)
+				dir := newTestDir(t)
+
+				err := cat.Batch(func(tx catalog.Tx) error {
+					_ = tx.Add(catalog.NewProject("a", dir))
+					return tx.Add(catalog.NewProject("b", dir))
+				})
+
+				assert.ErrorIs(t, err, catalog.ErrAlreadyExists)
+				assert.Equal(t, 0, cat.Count())
+			})
+		})
+	}
+}
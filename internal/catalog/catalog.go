@@ -1,6 +1,9 @@
 package catalog
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 var (
 	ErrNotFound      = errors.New("project not found")
@@ -19,12 +22,32 @@ type Catalog interface {
 	Count() int
 	Save() error
 	Load() error
+
+	// Batch runs fn and applies and persists all of its mutations together,
+	// or none of them. Any failed mutation rejects the whole batch with a
+	// *BatchError listing each failure. fn must use only tx: the catalog
+	// itself is locked until Batch returns.
+	Batch(fn func(tx Tx) error) error
+
+	// Watch streams changes to projects, whether made through this catalog
+	// or by another process, until ctx is done.
+	Watch(ctx context.Context) <-chan Event
+
+	AddWorkspace(w Workspace) error
+	GetWorkspace(name string) (Workspace, error)
+	UpdateWorkspace(w Workspace) error
+	RemoveWorkspace(name string) error
+	ListWorkspaces() []Workspace
 }
 
 type FilterOptions struct {
-	Query      string
-	SortBy     SortField
-	Descending bool
+	Query    string
+	Tag      string
+	Language string
+	// ExcludeArchived hides archived projects.
+	ExcludeArchived bool
+	SortBy          SortField
+	Descending      bool
 }
 
 type SortField string
@@ -35,3 +58,6 @@ const (
 	SortByLastAccessed SortField = "last_accessed"
 	SortByAddedAt      SortField = "added_at"
 )
+
+// SortFields lists every supported sort order.
+var SortFields = []SortField{SortByName, SortByPath, SortByLastAccessed, SortByAddedAt}
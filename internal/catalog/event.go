@@ -0,0 +1,158 @@
+package catalog
+
+import (
+	"context"
+	"sync"
+)
+
+type EventKind string
+
+const (
+	EventAdded   EventKind = "added"
+	EventUpdated EventKind = "updated"
+	EventRemoved EventKind = "removed"
+)
+
+// Event describes a change to one project. Old is the zero Project for
+// EventAdded and New is the zero Project for EventRemoved.
+type Event struct {
+	Kind EventKind
+	Old  Project
+	New  Project
+}
+
+// diffProjects returns the events that turn old into new, ordered by ID.
+func diffProjects(old, new map[string]Project) []Event {
+	var events []Event
+	for _, id := range unionKeys(old, new) {
+		o, inOld := old[id]
+		n, inNew := new[id]
+		switch {
+		case !inOld:
+			events = append(events, Event{Kind: EventAdded, New: n})
+		case !inNew:
+			events = append(events, Event{Kind: EventRemoved, Old: o})
+		case !sameProject(o, n):
+			events = append(events, Event{Kind: EventUpdated, Old: o, New: n})
+		}
+	}
+	return events
+}
+
+// eventHub fans events out to Watch subscribers. Each subscriber has an
+// unbounded queue so publishing never blocks a mutation on a slow reader.
+// The file watcher runs only while someone is subscribed.
+type eventHub struct {
+	mu        sync.Mutex
+	subs      map[*subscriber]struct{}
+	stopWatch func()
+}
+
+type subscriber struct {
+	mu    sync.Mutex
+	queue []Event
+	wake  chan struct{}
+}
+
+// subscribe returns a channel of events that is closed when ctx is done.
+// startWatch is called when the first subscriber arrives and the function
+// it returns when the last one leaves.
+func (h *eventHub) subscribe(ctx context.Context, startWatch func() (stop func())) <-chan Event {
+	s := &subscriber{wake: make(chan struct{}, 1)}
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[*subscriber]struct{})
+	}
+	h.subs[s] = struct{}{}
+	if len(h.subs) == 1 && startWatch != nil {
+		h.stopWatch = startWatch()
+	}
+	h.mu.Unlock()
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		defer h.unsubscribe(s)
+		for {
+			ev, ok := s.pop()
+			if !ok {
+				select {
+				case <-s.wake:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (h *eventHub) unsubscribe(s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subs, s)
+	if len(h.subs) == 0 && h.stopWatch != nil {
+		h.stopWatch()
+		h.stopWatch = nil
+	}
+}
+
+func (h *eventHub) active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs) > 0
+}
+
+func (h *eventHub) publish(events ...Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subs {
+		s.push(events)
+	}
+}
+
+func (s *subscriber) push(events []Event) {
+	s.mu.Lock()
+	s.queue = append(s.queue, events...)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscriber) pop() (Event, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return Event{}, false
+	}
+	ev := s.queue[0]
+	s.queue = s.queue[1:]
+	return ev, true
+}
+
+// startFileWatch watches path until the returned stop function is called.
+// Failing to watch only disables external change detection.
+func startFileWatch(path string, onChange func()) func() {
+	stop, err := watchFile(path, onChange)
+	if err != nil {
+		return func() {}
+	}
+	return stop
+}
@@ -0,0 +1,222 @@
+package catalog_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"path/filepath"
+	"pj/internal/catalog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const eventTimeout = 5 * time.Second
+
+func nextEvent(t *testing.T, events <-chan catalog.Event) catalog.Event {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		require.True(t, ok, "event channel closed")
+		return ev
+	case <-time.After(eventTimeout):
+		require.FailNow(t, "timed out waiting for event")
+		return catalog.Event{}
+	}
+}
+
+func assertNoEvent(t *testing.T, events <-chan catalog.Event) {
+	t.Helper()
+	select {
+	case ev := <-events:
+		assert.Failf(t, "unexpected event", "%s %s", ev.Kind, ev.New.Name)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestCatalog_Watch_InProcess(t *testing.T) {
+	for _, backend := range batchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			cat, _ := backend.open(t)
+			events := cat.Watch(t.Context())
+			p := catalog.NewProject("api", newTestDir(t))
+
+			require.NoError(t, cat.Add(p))
+			ev := nextEvent(t, events)
+			assert.Equal(t, catalog.EventAdded, ev.Kind)
+			assert.Equal(t, p.ID, ev.New.ID)
+
+			require.NoError(t, cat.Update(p.WithDescription("REST")))
+			ev = nextEvent(t, events)
+			assert.Equal(t, catalog.EventUpdated, ev.Kind)
+			assert.Empty(t, ev.Old.Description)
+			assert.Equal(t, "REST", ev.New.Description)
+
+			require.NoError(t, cat.Remove(p.ID))
+			ev = nextEvent(t, events)
+			assert.Equal(t, catalog.EventRemoved, ev.Kind)
+			assert.Equal(t, p.ID, ev.Old.ID)
+		})
+	}
+}
+
+func TestCatalog_Watch_Batch(t *testing.T) {
+	for _, backend := range batchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			cat, _ := backend.open(t)
+			events := cat.Watch(t.Context())
+
+			err := cat.Batch(func(tx catalog.Tx) error {
+				_ = tx.Add(catalog.NewProject("a", newTestDir(t)))
+				return tx.Add(catalog.NewProject("b", newTestDir(t)))
+			})
+			require.NoError(t, err)
+
+			names := []string{nextEvent(t, events).New.Name, nextEvent(t, events).New.Name}
+			assert.ElementsMatch(t, []string{"a", "b"}, names)
+
+			_ = cat.Batch(func(tx catalog.Tx) error {
+				return tx.Add(catalog.NewProject("", newTestDir(t)))
+			})
+			assertNoEvent(t, events)
+		})
+	}
+}
+
+func TestCatalog_Watch_ExternalChanges(t *testing.T) {
+	for _, backend := range batchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			cat, reopen := backend.open(t)
+			require.NoError(t, cat.Save())
+			events := cat.Watch(t.Context())
+
+			other := reopen()
+			p := catalog.NewProject("api", newTestDir(t))
+			require.NoError(t, other.Add(p))
+			require.NoError(t, other.Save())
+
+			ev := nextEvent(t, events)
+			assert.Equal(t, catalog.EventAdded, ev.Kind)
+			assert.Equal(t, p.ID, ev.New.ID)
+			got, err := cat.Get(p.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "api", got.Name)
+		})
+	}
+}
+
+func TestYAMLCatalog_Watch_IgnoresOwnSaves(t *testing.T) {
+	cat, err := catalog.NewYAMLCatalog(filepath.Join(t.TempDir(), "catalog.yaml"))
+	require.NoError(t, err)
+	events := cat.Watch(t.Context())
+
+	require.NoError(t, cat.Add(catalog.NewProject("api", newTestDir(t))))
+	require.NoError(t, cat.Save())
+
+	assert.Equal(t, catalog.EventAdded, nextEvent(t, events).Kind)
+	assertNoEvent(t, events)
+}
+
+func TestYAMLCatalog_Watch_KeepsUnsavedChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	cat, err := catalog.NewYAMLCatalog(path)
+	require.NoError(t, err)
+	api := catalog.NewProject("api", newTestDir(t))
+	require.NoError(t, cat.Add(api))
+	require.NoError(t, cat.Save())
+	events := cat.Watch(t.Context())
+	require.NoError(t, cat.Update(api.WithDescription("unsaved")))
+	assert.Equal(t, catalog.EventUpdated, nextEvent(t, events).Kind)
+
+	other, err := catalog.NewYAMLCatalog(path)
+	require.NoError(t, err)
+	require.NoError(t, other.Load())
+	web := catalog.NewProject("web", newTestDir(t))
+	require.NoError(t, other.Add(web))
+	require.NoError(t, other.Save())
+
+	ev := nextEvent(t, events)
+	assert.Equal(t, catalog.EventAdded, ev.Kind)
+	assert.Equal(t, web.ID, ev.New.ID)
+	got, err := cat.Get(api.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "unsaved", got.Description)
+
+	require.NoError(t, cat.Save())
+	require.NoError(t, other.Load())
+	assert.Equal(t, 2, other.Count())
+	got, err = other.Get(api.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "unsaved", got.Description)
+}
+
+func TestCatalog_Watch_ClosesOnCancel(t *testing.T) {
+	cat, _ := newTestSQLiteCatalog(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	events := cat.Watch(ctx)
+
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(eventTimeout):
+		t.Fatal("channel not closed after cancel")
+	}
+}
+
+func TestCatalog_Watch_SubscribeWhileChanging(t *testing.T) {
+	for _, backend := range batchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			cat, _ := backend.open(t)
+			p := catalog.NewProject("api", newTestDir(t))
+			require.NoError(t, cat.Add(p))
+
+			// Every subscription is the only one, so starting the watch
+			// races with publishing the concurrent changes.
+			stop := make(chan struct{})
+			watching := make(chan struct{})
+			go func() {
+				defer close(watching)
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					ctx, cancel := context.WithCancel(t.Context())
+					events := cat.Watch(ctx)
+					time.Sleep(time.Duration(rand.IntN(500)) * time.Microsecond)
+					cancel()
+					for range events {
+					}
+				}
+			}()
+			changing := make(chan error, 1)
+			go func() {
+				defer close(stop)
+				for i := range 100 {
+					if err := cat.Update(p.WithDescription(strconv.Itoa(i))); err != nil {
+						changing <- err
+						return
+					}
+				}
+				changing <- nil
+			}()
+
+			select {
+			case err := <-changing:
+				require.NoError(t, err)
+			case <-time.After(eventTimeout):
+				t.Fatal("changes deadlocked with a concurrent Watch")
+			}
+			select {
+			case <-watching:
+			case <-time.After(eventTimeout):
+				t.Fatal("Watch deadlocked with a concurrent change")
+			}
+		})
+	}
+}
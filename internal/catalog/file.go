@@ -0,0 +1,92 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const fileVersion = 1
+
+type catalogFile struct {
+	Version    int         `yaml:"version"`
+	Projects   []Project   `yaml:"projects"`
+	Workspaces []Workspace `yaml:"workspaces,omitempty"`
+}
+
+// Snapshot is the content of a catalog file exactly as stored, with
+// portable paths left unresolved.
+type Snapshot struct {
+	Projects   []Project
+	Workspaces []Workspace
+}
+
+// ReadFile reads a catalog file without resolving its paths. A missing file
+// yields an empty snapshot.
+func ReadFile(path string) (Snapshot, error) {
+	snap, _, err := readFile(path)
+	return snap, err
+}
+
+// readFile is ReadFile that also returns the raw content.
+func readFile(path string) (Snapshot, []byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Snapshot{}, nil, nil
+	}
+	if err != nil {
+		return Snapshot{}, nil, fmt.Errorf("failed to read catalog file: %w", err)
+	}
+
+	snap, err := decodeFile(path, data)
+	return snap, data, err
+}
+
+func decodeFile(path string, data []byte) (Snapshot, error) {
+	var file catalogFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse catalog file %q: %w", path, err)
+	}
+
+	return Snapshot{Projects: file.Projects, Workspaces: file.Workspaces}, nil
+}
+
+// WriteFile atomically replaces the catalog file at path with snap.
+func WriteFile(path string, snap Snapshot) error {
+	_, err := writeFile(path, snap)
+	return err
+}
+
+// writeFile is WriteFile that also returns the content written.
+func writeFile(path string, snap Snapshot) ([]byte, error) {
+	file := catalogFile{
+		Version:    fileVersion,
+		Projects:   slices.Clone(snap.Projects),
+		Workspaces: slices.Clone(snap.Workspaces),
+	}
+	if file.Projects == nil {
+		file.Projects = []Project{}
+	}
+
+	slices.SortStableFunc(file.Projects, func(a, b Project) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.SortFunc(file.Workspaces, func(a, b Workspace) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return nil, err
+	}
+
+	return data, os.Rename(tmpPath, path)
+}
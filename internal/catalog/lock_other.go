@@ -0,0 +1,8 @@
+//go:build !unix
+
+package catalog
+
+// LockFile is a no-op where advisory file locks are unavailable.
+func LockFile(path string) (unlock func() error, err error) {
+	return func() error { return nil }, nil
+}
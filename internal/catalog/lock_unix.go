@@ -0,0 +1,26 @@
+//go:build unix
+
+package catalog
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// LockFile takes an exclusive advisory lock shared by every process using
+// the catalog at path, blocking until it is free.
+func LockFile(path string) (unlock func() error, err error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() error {
+		defer f.Close()
+		return unix.Flock(int(f.Fd()), unix.LOCK_UN)
+	}, nil
+}
@@ -0,0 +1,390 @@
+package catalog
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
+
+type ConflictKind string
+
+const ConflictDuplicatePath ConflictKind = "duplicate_path"
+
+// MergeConflict describes something Merge could not resolve on its own. The
+// merged snapshot still holds a usable catalog: Kept names the project that
+// was retained.
+type MergeConflict struct {
+	Kind    ConflictKind `json:"kind"`
+	Path    string       `json:"path"`
+	IDs     []string     `json:"ids"`
+	Kept    string       `json:"kept"`
+	Message string       `json:"message"`
+}
+
+type MergeResult struct {
+	Snapshot  Snapshot
+	Conflicts []MergeConflict
+}
+
+// Merge performs a three-way merge of catalog snapshots keyed by project ID.
+// Fields changed on only one side take that side's value; fields changed on
+// both take the value that changed most recently, going by FieldUpdatedAt.
+// Tags and worktrees are merged as sets, so additions from either side
+// survive.
+func Merge(base, ours, theirs Snapshot) MergeResult {
+	baseByID := indexProjects(base.Projects)
+	oursByID := indexProjects(ours.Projects)
+	theirsByID := indexProjects(theirs.Projects)
+
+	var merged []Project
+	for _, id := range unionKeys(baseByID, oursByID, theirsByID) {
+		b, inBase := baseByID[id]
+		o, inOurs := oursByID[id]
+		t, inTheirs := theirsByID[id]
+
+		switch {
+		case inOurs && inTheirs:
+			merged = append(merged, mergeProject(b, o, t))
+		case inOurs:
+			if !inBase || !sameProject(b, o) {
+				merged = append(merged, o)
+			}
+		case inTheirs:
+			if !inBase || !sameProject(b, t) {
+				merged = append(merged, t)
+			}
+		}
+	}
+
+	merged, conflicts := resolveDuplicatePaths(merged, baseByID, oursByID)
+
+	known := indexProjects(merged)
+	workspaces := mergeWorkspaces(base.Workspaces, ours.Workspaces, theirs.Workspaces)
+	for i, w := range workspaces {
+		w.ProjectIDs = slices.DeleteFunc(w.ProjectIDs, func(id string) bool {
+			_, ok := known[id]
+			return !ok
+		})
+		workspaces[i] = w
+	}
+
+	return MergeResult{
+		Snapshot:  Snapshot{Projects: merged, Workspaces: workspaces},
+		Conflicts: conflicts,
+	}
+}
+
+func indexProjects(projects []Project) map[string]Project {
+	m := make(map[string]Project, len(projects))
+	for _, p := range projects {
+		m[p.ID] = p
+	}
+	return m
+}
+
+func unionKeys[V any](maps ...map[string]V) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// mergeField is a project field merged last-writer-wins.
+type mergeField struct {
+	name  string
+	equal func(a, b Project) bool
+	copy  func(dst *Project, src Project)
+}
+
+func fieldOf[T any](name string, field func(*Project) *T) mergeField {
+	return mergeField{
+		name:  name,
+		equal: func(a, b Project) bool { return reflect.DeepEqual(*field(&a), *field(&b)) },
+		copy:  func(dst *Project, src Project) { *field(dst) = *field(&src) },
+	}
+}
+
+// mergeFields are the fields whose changes are timestamped in
+// FieldUpdatedAt. Tags and worktrees are merged as sets instead.
+var mergeFields = []mergeField{
+	fieldOf("name", func(p *Project) *string { return &p.Name }),
+	fieldOf("path", func(p *Project) *string { return &p.Path }),
+	fieldOf("description", func(p *Project) *string { return &p.Description }),
+	fieldOf("editor", func(p *Project) *string { return &p.Editor }),
+	fieldOf("tmux", func(p *Project) **TmuxLayout { return &p.Tmux }),
+	fieldOf("stack", func(p *Project) **Stack { return &p.Stack }),
+	fieldOf("remote", func(p *Project) *string { return &p.Remote }),
+	fieldOf("parent", func(p *Project) *string { return &p.Parent }),
+	fieldOf("status", func(p *Project) *Status { return &p.Status }),
+	fieldOf("archive_path", func(p *Project) *string { return &p.ArchivePath }),
+	fieldOf("status_before_archive", func(p *Project) *Status { return &p.StatusBeforeArchive }),
+}
+
+func mergeProject(b, o, t Project) Project {
+	m := o
+	m.FieldUpdatedAt = nil
+	for _, f := range mergeFields {
+		// Only times actually recorded are carried over.
+		oAt, tAt := o.FieldUpdatedAt[f.name], t.FieldUpdatedAt[f.name]
+		at := oAt
+		switch {
+		case f.equal(o, t):
+			at = latest(oAt, tAt)
+		case f.equal(t, b):
+			// Only ours changed it.
+		case f.equal(o, b), fieldTime(t, f.name).After(fieldTime(o, f.name)):
+			f.copy(&m, t)
+			at = tAt
+		}
+		m.stampField(f.name, at)
+	}
+	m.Tags = mergeSet(b.Tags, o.Tags, t.Tags)
+	m.Worktrees = mergeWorktrees(b.Worktrees, o.Worktrees, t.Worktrees)
+	m.AddedAt = earliest(o.AddedAt, t.AddedAt)
+	m.LastAccessed = latest(o.LastAccessed, t.LastAccessed)
+	m.UpdatedAt = latest(o.UpdatedAt, t.UpdatedAt)
+	return m
+}
+
+// fieldTime is when the named field last changed. Catalogs written before
+// fields were timestamped fall back to the project's own times.
+func fieldTime(p Project, name string) time.Time {
+	if at, ok := p.FieldUpdatedAt[name]; ok {
+		return at
+	}
+	return latest(p.UpdatedAt, p.AddedAt)
+}
+
+func (p *Project) stampField(name string, at time.Time) {
+	if at.IsZero() {
+		return
+	}
+	if p.FieldUpdatedAt == nil {
+		p.FieldUpdatedAt = make(map[string]time.Time)
+	}
+	p.FieldUpdatedAt[name] = at
+}
+
+// stampChanges records now as the change time of every field that differs
+// from old, and reports whether anything besides the access time changed.
+func (p *Project) stampChanges(old Project, now time.Time) bool {
+	p.FieldUpdatedAt = maps.Clone(old.FieldUpdatedAt)
+	changed := false
+	for _, f := range mergeFields {
+		if !f.equal(old, *p) {
+			p.stampField(f.name, now)
+			changed = true
+		}
+	}
+	return changed || !slices.Equal(old.Tags, p.Tags) || !slices.Equal(old.Worktrees, p.Worktrees)
+}
+
+func pick[T comparable](b, o, t T) T {
+	switch {
+	case o == t, t == b:
+		return o
+	case o == b:
+		return t
+	default:
+		return o
+	}
+}
+
+// mergeSet keeps an element if both sides have it or if one side added it;
+// an element removed on either side stays removed.
+func mergeSet(b, o, t []string) []string {
+	var out []string
+	for _, v := range unionSorted(o, t) {
+		inO, inT := slices.Contains(o, v), slices.Contains(t, v)
+		if (inO && inT) || !slices.Contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// mergeWorktrees is mergeSet for worktrees, keyed by path. A worktree on
+// both sides keeps ours' branch.
+func mergeWorktrees(b, o, t []Worktree) []Worktree {
+	has := func(wts []Worktree, path string) bool {
+		return slices.ContainsFunc(wts, func(w Worktree) bool { return w.Path == path })
+	}
+	var out []Worktree
+	for _, w := range slices.Concat(o, t) {
+		if has(out, w.Path) {
+			continue
+		}
+		if (has(o, w.Path) && has(t, w.Path)) || !has(b, w.Path) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func unionSorted(a, b []string) []string {
+	out := slices.Concat(a, b)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+func earliest(a, b time.Time) time.Time {
+	switch {
+	case a.IsZero():
+		return b
+	case b.IsZero() || a.Before(b):
+		return a
+	default:
+		return b
+	}
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func sameProject(a, b Project) bool {
+	return a.ID == b.ID &&
+		a.Name == b.Name &&
+		a.Path == b.Path &&
+		a.Description == b.Description &&
+		a.Editor == b.Editor &&
+		slices.Equal(a.Tags, b.Tags) &&
+		reflect.DeepEqual(a.Tmux, b.Tmux) &&
+		reflect.DeepEqual(a.Stack, b.Stack) &&
+		a.Remote == b.Remote &&
+		slices.Equal(a.Worktrees, b.Worktrees) &&
+		a.Parent == b.Parent &&
+		a.Status == b.Status &&
+		a.ArchivePath == b.ArchivePath &&
+		a.StatusBeforeArchive == b.StatusBeforeArchive &&
+		a.AddedAt.Equal(b.AddedAt) &&
+		a.LastAccessed.Equal(b.LastAccessed) &&
+		a.UpdatedAt.Equal(b.UpdatedAt) &&
+		maps.EqualFunc(a.FieldUpdatedAt, b.FieldUpdatedAt, time.Time.Equal)
+}
+
+// resolveDuplicatePaths keeps one project per path, preferring the one that
+// already existed in base, then the one from ours.
+func resolveDuplicatePaths(projects []Project, base, ours map[string]Project) ([]Project, []MergeConflict) {
+	byPath := make(map[string][]Project)
+	var paths []string
+	for _, p := range projects {
+		if _, seen := byPath[p.Path]; !seen {
+			paths = append(paths, p.Path)
+		}
+		byPath[p.Path] = append(byPath[p.Path], p)
+	}
+
+	rank := func(p Project) int {
+		if _, ok := base[p.ID]; ok {
+			return 0
+		}
+		if _, ok := ours[p.ID]; ok {
+			return 1
+		}
+		return 2
+	}
+
+	var kept []Project
+	var conflicts []MergeConflict
+	for _, path := range paths {
+		claims := byPath[path]
+		if len(claims) == 1 {
+			kept = append(kept, claims[0])
+			continue
+		}
+
+		slices.SortFunc(claims, func(a, b Project) int {
+			return cmp.Or(cmp.Compare(rank(a), rank(b)), cmp.Compare(a.ID, b.ID))
+		})
+		ids := make([]string, len(claims))
+		for i, p := range claims {
+			ids[i] = p.ID
+		}
+		kept = append(kept, claims[0])
+		conflicts = append(conflicts, MergeConflict{
+			Kind:    ConflictDuplicatePath,
+			Path:    path,
+			IDs:     ids,
+			Kept:    claims[0].ID,
+			Message: fmt.Sprintf("%d projects claim %s; kept %q", len(claims), path, claims[0].Name),
+		})
+	}
+	return kept, conflicts
+}
+
+func mergeWorkspaces(base, ours, theirs []Workspace) []Workspace {
+	index := func(ws []Workspace) map[string]Workspace {
+		m := make(map[string]Workspace, len(ws))
+		for _, w := range ws {
+			m[w.Name] = w
+		}
+		return m
+	}
+	baseByName, oursByName, theirsByName := index(base), index(ours), index(theirs)
+
+	var merged []Workspace
+	for _, name := range unionKeys(baseByName, oursByName, theirsByName) {
+		b, inBase := baseByName[name]
+		o, inOurs := oursByName[name]
+		t, inTheirs := theirsByName[name]
+
+		switch {
+		case inOurs && inTheirs:
+			m := o
+			m.Editor = pick(b.Editor, o.Editor, t.Editor)
+			m.ProjectIDs = mergeOrdered(b.ProjectIDs, o.ProjectIDs, t.ProjectIDs)
+			merged = append(merged, m)
+		case inOurs:
+			if !inBase || !sameWorkspace(b, o) {
+				merged = append(merged, o)
+			}
+		case inTheirs:
+			if !inBase || !sameWorkspace(b, t) {
+				merged = append(merged, t)
+			}
+		}
+	}
+	return merged
+}
+
+// mergeOrdered merges ordered lists, keeping ours' order and appending
+// theirs' additions.
+func mergeOrdered(b, o, t []string) []string {
+	switch {
+	case slices.Equal(o, t), slices.Equal(t, b):
+		return o
+	case slices.Equal(o, b):
+		return t
+	}
+
+	var out []string
+	for _, v := range slices.Concat(o, t) {
+		if slices.Contains(out, v) {
+			continue
+		}
+		removed := slices.Contains(b, v) && (!slices.Contains(o, v) || !slices.Contains(t, v))
+		if !removed {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func sameWorkspace(a, b Workspace) bool {
+	return a.Name == b.Name && a.Editor == b.Editor && slices.Equal(a.ProjectIDs, b.ProjectIDs)
+}
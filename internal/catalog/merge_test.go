@@ -0,0 +1,307 @@
+package catalog_test
+
+import (
+	"path/filepath"
+	"pj/internal/catalog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mergeBaseTime = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func mergeProject(id, name, path string) catalog.Project {
+	return catalog.Project{ID: id, Name: name, Path: path, AddedAt: mergeBaseTime}
+}
+
+func snapshot(projects ...catalog.Project) catalog.Snapshot {
+	return catalog.Snapshot{Projects: projects}
+}
+
+func mergedByID(t *testing.T, result catalog.MergeResult, id string) catalog.Project {
+	t.Helper()
+	for _, p := range result.Snapshot.Projects {
+		if p.ID == id {
+			return p
+		}
+	}
+	require.Failf(t, "project missing from merge", "id %s", id)
+	return catalog.Project{}
+}
+
+func TestMerge_Fields(t *testing.T) {
+	t.Run("takes change made on one side", func(t *testing.T) {
+		base := mergeProject("a", "api", "/src/api")
+		theirs := base
+		theirs.Description = "REST backend"
+
+		result := catalog.Merge(snapshot(base), snapshot(base), snapshot(theirs))
+
+		assert.Empty(t, result.Conflicts)
+		assert.Equal(t, "REST backend", mergedByID(t, result, "a").Description)
+	})
+
+	t.Run("combines changes to different fields", func(t *testing.T) {
+		base := mergeProject("a", "api", "/src/api")
+		ours := base.WithEditor("nvim")
+		theirs := base.WithDescription("REST backend")
+
+		result := catalog.Merge(snapshot(base), snapshot(ours), snapshot(theirs))
+
+		got := mergedByID(t, result, "a")
+		assert.Equal(t, "nvim", got.Editor)
+		assert.Equal(t, "REST backend", got.Description)
+	})
+
+	t.Run("newer change wins when both change a field", func(t *testing.T) {
+		base := mergeProject("a", "api", "/src/api")
+		ours := base.WithDescription("ours")
+		ours.FieldUpdatedAt = map[string]time.Time{"description": mergeBaseTime.Add(time.Hour)}
+		theirs := base.WithDescription("theirs")
+		theirs.FieldUpdatedAt = map[string]time.Time{"description": mergeBaseTime.Add(2 * time.Hour)}
+
+		result := catalog.Merge(snapshot(base), snapshot(ours), snapshot(theirs))
+
+		got := mergedByID(t, result, "a")
+		assert.Equal(t, "theirs", got.Description)
+		assert.Equal(t, mergeBaseTime.Add(2*time.Hour), got.FieldUpdatedAt["description"])
+	})
+
+	t.Run("later edits to other fields do not win a conflict", func(t *testing.T) {
+		base := mergeProject("a", "api", "/src/api")
+		ours := base.WithDescription("ours").WithEditor("nvim")
+		ours.FieldUpdatedAt = map[string]time.Time{
+			"description": mergeBaseTime.Add(time.Hour),
+			"editor":      mergeBaseTime.Add(3 * time.Hour),
+		}
+		ours.UpdatedAt = mergeBaseTime.Add(3 * time.Hour)
+		theirs := base.WithDescription("theirs")
+		theirs.FieldUpdatedAt = map[string]time.Time{"description": mergeBaseTime.Add(2 * time.Hour)}
+		theirs.UpdatedAt = mergeBaseTime.Add(2 * time.Hour)
+
+		result := catalog.Merge(snapshot(base), snapshot(ours), snapshot(theirs))
+
+		got := mergedByID(t, result, "a")
+		assert.Equal(t, "theirs", got.Description)
+		assert.Equal(t, "nvim", got.Editor)
+	})
+
+	t.Run("falls back to project times for untimestamped catalogs", func(t *testing.T) {
+		base := mergeProject("a", "api", "/src/api")
+		ours := base.WithDescription("ours")
+		ours.UpdatedAt = mergeBaseTime.Add(time.Hour)
+		theirs := base.WithDescription("theirs")
+		theirs.UpdatedAt = mergeBaseTime.Add(2 * time.Hour)
+
+		result := catalog.Merge(snapshot(base), snapshot(ours), snapshot(theirs))
+
+		got := mergedByID(t, result, "a")
+		assert.Equal(t, "theirs", got.Description)
+		assert.Equal(t, theirs.UpdatedAt, got.UpdatedAt)
+	})
+
+	t.Run("records only field times that were set", func(t *testing.T) {
+		base := mergeProject("a", "api", "/src/api")
+		ours := base.WithEditor("nvim")
+		ours.FieldUpdatedAt = map[string]time.Time{"editor": mergeBaseTime.Add(time.Hour)}
+		theirs := base.WithDescription("REST backend")
+
+		result := catalog.Merge(snapshot(base), snapshot(ours), snapshot(theirs))
+
+		assert.Equal(t, ours.FieldUpdatedAt, mergedByID(t, result, "a").FieldUpdatedAt)
+	})
+
+	t.Run("keeps latest access time", func(t *testing.T) {
+		base := mergeProject("a", "api", "/src/api")
+		ours := base
+		ours.Touch()
+		theirs := base
+		theirs.LastAccessed = mergeBaseTime.Add(time.Minute)
+
+		result := catalog.Merge(snapshot(base), snapshot(ours), snapshot(theirs))
+
+		assert.Equal(t, ours.LastAccessed, mergedByID(t, result, "a").LastAccessed)
+	})
+}
+
+func TestMerge_Tags(t *testing.T) {
+	base := mergeProject("a", "api", "/src/api").WithTags("go", "old")
+	ours := base.WithTags("work").WithoutTags("old")
+	theirs := base.WithTags("backend")
+
+	result := catalog.Merge(snapshot(base), snapshot(ours), snapshot(theirs))
+
+	assert.Equal(t, []string{"backend", "go", "work"}, mergedByID(t, result, "a").Tags)
+}
+
+func TestMerge_Worktrees(t *testing.T) {
+	base := mergeProject("a", "api", "/src/api").
+		WithWorktrees([]catalog.Worktree{{Branch: "old", Path: "/src/api-old"}})
+	ours := base.WithWorktrees([]catalog.Worktree{{Branch: "fix", Path: "/src/api-fix"}})
+	theirs := base.WithWorktrees([]catalog.Worktree{
+		{Branch: "old", Path: "/src/api-old"},
+		{Branch: "feat", Path: "/src/api-feat"},
+	})
+
+	result := catalog.Merge(snapshot(base), snapshot(ours), snapshot(theirs))
+
+	assert.Equal(t, []catalog.Worktree{
+		{Branch: "fix", Path: "/src/api-fix"},
+		{Branch: "feat", Path: "/src/api-feat"},
+	}, mergedByID(t, result, "a").Worktrees)
+}
+
+func TestMerge_Deletions(t *testing.T) {
+	t.Run("drops project deleted on one side and unchanged on the other", func(t *testing.T) {
+		keep := mergeProject("a", "api", "/src/api")
+		gone := mergeProject("b", "web", "/src/web")
+
+		result := catalog.Merge(snapshot(keep, gone), snapshot(keep, gone), snapshot(keep))
+
+		require.Len(t, result.Snapshot.Projects, 1)
+		assert.Equal(t, "a", result.Snapshot.Projects[0].ID)
+	})
+
+	t.Run("keeps project modified on one side and deleted on the other", func(t *testing.T) {
+		base := mergeProject("a", "api", "/src/api")
+		ours := base.WithDescription("still here")
+
+		result := catalog.Merge(snapshot(base), snapshot(ours), snapshot())
+
+		assert.Equal(t, "still here", mergedByID(t, result, "a").Description)
+	})
+
+	t.Run("keeps projects added on either side", func(t *testing.T) {
+		result := catalog.Merge(
+			snapshot(),
+			snapshot(mergeProject("a", "api", "/src/api")),
+			snapshot(mergeProject("b", "web", "/src/web")),
+		)
+
+		assert.Len(t, result.Snapshot.Projects, 2)
+		assert.Empty(t, result.Conflicts)
+	})
+}
+
+func TestMerge_DuplicatePath(t *testing.T) {
+	ours := mergeProject("b", "api", "/src/api")
+	theirs := mergeProject("a", "api-2", "/src/api")
+
+	result := catalog.Merge(snapshot(), snapshot(ours), snapshot(theirs))
+
+	require.Len(t, result.Snapshot.Projects, 1)
+	assert.Equal(t, "b", result.Snapshot.Projects[0].ID)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, catalog.ConflictDuplicatePath, result.Conflicts[0].Kind)
+	assert.Equal(t, "/src/api", result.Conflicts[0].Path)
+	assert.Equal(t, []string{"b", "a"}, result.Conflicts[0].IDs)
+	assert.Equal(t, "b", result.Conflicts[0].Kept)
+}
+
+func TestMerge_Workspaces(t *testing.T) {
+	t.Run("merges members added on both sides", func(t *testing.T) {
+		a, b, c := mergeProject("a", "a", "/a"), mergeProject("b", "b", "/b"), mergeProject("c", "c", "/c")
+		base := catalog.Snapshot{
+			Projects:   []catalog.Project{a, b, c},
+			Workspaces: []catalog.Workspace{catalog.NewWorkspace("stack", "a")},
+		}
+		ours := base
+		ours.Workspaces = []catalog.Workspace{catalog.NewWorkspace("stack", "a", "b")}
+		theirs := base
+		theirs.Workspaces = []catalog.Workspace{catalog.NewWorkspace("stack", "a", "c")}
+
+		result := catalog.Merge(base, ours, theirs)
+
+		require.Len(t, result.Snapshot.Workspaces, 1)
+		assert.Equal(t, []string{"a", "b", "c"}, result.Snapshot.Workspaces[0].ProjectIDs)
+	})
+
+	t.Run("prunes members whose project was deleted", func(t *testing.T) {
+		a, b := mergeProject("a", "a", "/a"), mergeProject("b", "b", "/b")
+		base := catalog.Snapshot{
+			Projects:   []catalog.Project{a, b},
+			Workspaces: []catalog.Workspace{catalog.NewWorkspace("stack", "a")},
+		}
+		ours := base
+		ours.Workspaces = []catalog.Workspace{catalog.NewWorkspace("stack", "a", "b")}
+		theirs := catalog.Snapshot{
+			Projects:   []catalog.Project{a},
+			Workspaces: base.Workspaces,
+		}
+
+		result := catalog.Merge(base, ours, theirs)
+
+		require.Len(t, result.Snapshot.Workspaces, 1)
+		assert.Equal(t, []string{"a"}, result.Snapshot.Workspaces[0].ProjectIDs)
+	})
+}
+
+func TestFile_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.yaml")
+	snap := catalog.Snapshot{
+		Projects: []catalog.Project{
+			mergeProject("b", "web", "/src/web").WithTags("frontend"),
+			mergeProject("a", "api", "/src/api"),
+		},
+		Workspaces: []catalog.Workspace{catalog.NewWorkspace("stack", "a", "b")},
+	}
+
+	require.NoError(t, catalog.WriteFile(path, snap))
+	got, err := catalog.ReadFile(path)
+
+	require.NoError(t, err)
+	require.Len(t, got.Projects, 2)
+	assert.Equal(t, "api", got.Projects[0].Name)
+	assert.Equal(t, []string{"frontend"}, got.Projects[1].Tags)
+	assert.Equal(t, snap.Workspaces, got.Workspaces)
+}
+
+func TestReadFile_Missing(t *testing.T) {
+	snap, err := catalog.ReadFile(filepath.Join(t.TempDir(), "nope.yaml"))
+
+	require.NoError(t, err)
+	assert.Empty(t, snap.Projects)
+}
+
+func TestProject_WithTags(t *testing.T) {
+	p := catalog.NewProject("api", "/src/api").WithTags(" work", "go", "work", "")
+
+	assert.Equal(t, []string{"go", "work"}, p.Tags)
+	assert.True(t, p.HasTag("go"))
+	assert.Equal(t, []string{"work"}, p.WithoutTags("go").Tags)
+}
+
+func TestProject_Stack(t *testing.T) {
+	p := catalog.NewProject("api", "/src/api")
+	assert.Nil(t, p.Languages())
+	assert.False(t, p.HasLanguage("go"))
+
+	p = p.WithStack(&catalog.Stack{
+		Languages:       []string{"go", "typescript"},
+		Frameworks:      []string{"gin"},
+		PackageManagers: []string{"go", "pnpm"},
+	})
+
+	assert.True(t, p.HasLanguage("Go"))
+	assert.Equal(t, []string{"go", "typescript", "gin", "pnpm"}, p.Stack.Summary())
+}
+
+func TestProject_Worktree(t *testing.T) {
+	p := catalog.NewProject("api", "/src/api").WithWorktrees([]catalog.Worktree{
+		{Branch: "feature/x", Path: "/src/api.worktrees/feature-x"},
+		{Path: "/src/api.worktrees/bisect"},
+	})
+
+	w, ok := p.Worktree("feature/x")
+	assert.True(t, ok)
+	assert.Equal(t, "/src/api.worktrees/feature-x", w.Path)
+
+	w, ok = p.Worktree("bisect")
+	assert.True(t, ok, "detached worktrees are named after their directory")
+	assert.Empty(t, w.Branch)
+
+	_, ok = p.Worktree("main")
+	assert.False(t, ok)
+}
@@ -0,0 +1,164 @@
+package catalog_test
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCatalogFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestProject_ValidateAndNormalizeWith(t *testing.T) {
+	t.Run("resolves named root", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(root, "api"), 0o755))
+		m := config.PathMapper{Roots: map[string]string{"code": root}}
+		p := catalog.NewProject("api", "${code}/api")
+
+		err := p.ValidateAndNormalizeWith(m)
+
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, "api"), p.Path)
+		assert.Equal(t, "${code}/api", p.PortablePath)
+	})
+
+	t.Run("unknown root returns ErrUnknownRoot", func(t *testing.T) {
+		p := catalog.NewProject("api", "${code}/api")
+
+		err := p.ValidateAndNormalizeWith(config.PathMapper{})
+
+		assert.ErrorIs(t, err, config.ErrUnknownRoot)
+	})
+}
+
+func TestYAMLCatalog_PortablePaths(t *testing.T) {
+	t.Run("load resolves roots and save keeps portable form", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(root, "api"), 0o755))
+		path := writeCatalogFile(t, `version: 1
+projects:
+  - id: p1
+    name: api
+    path: ${code}/api
+`)
+		cat, err := catalog.NewYAMLCatalog(path)
+		require.NoError(t, err)
+		cat.WithPathMapper(config.PathMapper{Roots: map[string]string{"code": root}})
+
+		require.NoError(t, cat.Load())
+
+		got, err := cat.GetByPath(filepath.Join(root, "api"))
+		require.NoError(t, err)
+		assert.False(t, got.Unavailable)
+
+		require.NoError(t, cat.Save())
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "path: ${code}/api")
+	})
+
+	t.Run("marks projects missing on this host instead of failing", func(t *testing.T) {
+		path := writeCatalogFile(t, `version: 1
+projects:
+  - id: p1
+    name: api
+    path: ${work}/api
+  - id: p2
+    name: gone
+    path: /nonexistent/gone
+`)
+		cat, err := catalog.NewYAMLCatalog(path)
+		require.NoError(t, err)
+
+		require.NoError(t, cat.Load())
+
+		api, err := cat.Get("p1")
+		require.NoError(t, err)
+		assert.True(t, api.Unavailable)
+		gone, err := cat.Get("p2")
+		require.NoError(t, err)
+		assert.True(t, gone.Unavailable)
+
+		require.NoError(t, cat.Save())
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "path: ${work}/api")
+	})
+
+	t.Run("unavailable projects can still be edited", func(t *testing.T) {
+		path := writeCatalogFile(t, `version: 1
+projects:
+  - id: p1
+    name: api
+    path: ${work}/api
+`)
+		cat, err := catalog.NewYAMLCatalog(path)
+		require.NoError(t, err)
+		require.NoError(t, cat.Load())
+
+		p, err := cat.Get("p1")
+		require.NoError(t, err)
+		require.NoError(t, cat.Update(p.WithTags("synced").WithDescription("from laptop")))
+		require.NoError(t, cat.Save())
+
+		got, err := cat.Get("p1")
+		require.NoError(t, err)
+		assert.True(t, got.Unavailable)
+		assert.Equal(t, []string{"synced"}, got.Tags)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "path: ${work}/api")
+	})
+
+	t.Run("portable mode contracts new paths on save", func(t *testing.T) {
+		root := t.TempDir()
+		projectDir := filepath.Join(root, "web")
+		require.NoError(t, os.Mkdir(projectDir, 0o755))
+		path := filepath.Join(t.TempDir(), "catalog.yaml")
+		cat, err := catalog.NewYAMLCatalog(path)
+		require.NoError(t, err)
+		cat.WithPathMapper(config.PathMapper{Roots: map[string]string{"code": root}, Portable: true})
+		require.NoError(t, cat.Add(catalog.NewProject("web", projectDir)))
+
+		require.NoError(t, cat.Save())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "path: ${code}/web")
+	})
+
+	t.Run("moved project drops its stale portable form", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(root, "api"), 0o755))
+		path := writeCatalogFile(t, `version: 1
+projects:
+  - id: p1
+    name: api
+    path: ${code}/api
+`)
+		cat, err := catalog.NewYAMLCatalog(path)
+		require.NoError(t, err)
+		cat.WithPathMapper(config.PathMapper{Roots: map[string]string{"code": root}})
+		require.NoError(t, cat.Load())
+
+		p, _ := cat.Get("p1")
+		elsewhere := t.TempDir()
+		p.Path = elsewhere
+		require.NoError(t, cat.Update(p))
+		require.NoError(t, cat.Save())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "path: "+elsewhere)
+	})
+}
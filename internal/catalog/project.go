@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"pj/internal/config"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,13 +20,91 @@ var (
 )
 
 type Project struct {
-	ID           string    `yaml:"id"`
-	Name         string    `yaml:"name"`
-	Path         string    `yaml:"path"`
-	AddedAt      time.Time `yaml:"added_at"`
-	LastAccessed time.Time `yaml:"last_accessed"`
-	Description  string    `yaml:"description,omitempty"`
-	Editor       string    `yaml:"editor,omitempty"`
+	ID           string      `yaml:"id" json:"id"`
+	Name         string      `yaml:"name" json:"name"`
+	Path         string      `yaml:"path" json:"path"`
+	AddedAt      time.Time   `yaml:"added_at" json:"added_at"`
+	LastAccessed time.Time   `yaml:"last_accessed" json:"last_accessed"`
+	Description  string      `yaml:"description,omitempty" json:"description,omitempty"`
+	Editor       string      `yaml:"editor,omitempty" json:"editor,omitempty"`
+	Tags         []string    `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Tmux         *TmuxLayout `yaml:"tmux,omitempty" json:"tmux,omitempty"`
+	Stack        *Stack      `yaml:"stack,omitempty" json:"stack,omitempty"`
+	Remote       string      `yaml:"remote,omitempty" json:"remote,omitempty"`
+	Worktrees    []Worktree  `yaml:"worktrees,omitempty" json:"worktrees,omitempty"`
+	Parent       string      `yaml:"parent,omitempty" json:"parent,omitempty"`
+	Status       Status      `yaml:"status,omitempty" json:"status,omitempty"`
+	ArchivePath  string      `yaml:"archive_path,omitempty" json:"archive_path,omitempty"`
+	// StatusBeforeArchive is the status a sub-project had before its
+	// monorepo was archived, so unarchiving the monorepo can restore it.
+	StatusBeforeArchive Status    `yaml:"status_before_archive,omitempty" json:"status_before_archive,omitempty"`
+	UpdatedAt           time.Time `yaml:"updated_at,omitempty" json:"updated_at,omitzero"`
+	// FieldUpdatedAt records when each field last changed, keyed by its
+	// yaml name, so merges can pick the newest value field by field.
+	FieldUpdatedAt map[string]time.Time `yaml:"field_updated_at,omitempty" json:"field_updated_at,omitempty"`
+
+	// PortablePath is the path as written in the catalog when it was stored
+	// relative to $HOME or a named root, e.g. "${code}/service-a".
+	PortablePath string `yaml:"-" json:"-"`
+	// Unavailable marks projects whose path does not resolve or exist on
+	// this host.
+	Unavailable bool `yaml:"-" json:"-"`
+}
+
+// Status is where a project is in its lifecycle. The empty status means
+// active.
+type Status string
+
+const (
+	StatusActive   Status = "active"
+	StatusPaused   Status = "paused"
+	StatusArchived Status = "archived"
+)
+
+// Statuses lists every lifecycle status.
+var Statuses = []Status{StatusActive, StatusPaused, StatusArchived}
+
+// Stack is what was detected about a project from the files in its root.
+type Stack struct {
+	Languages       []string `yaml:"languages,omitempty" json:"languages,omitempty"`
+	Frameworks      []string `yaml:"frameworks,omitempty" json:"frameworks,omitempty"`
+	PackageManagers []string `yaml:"package_managers,omitempty" json:"package_managers,omitempty"`
+}
+
+// Summary lists everything detected once, languages first.
+func (s Stack) Summary() []string {
+	var out []string
+	for _, v := range slices.Concat(s.Languages, s.Frameworks, s.PackageManagers) {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Worktree is a linked git worktree of a project, addressed as
+// "project@name". Paths are as seen on the host that last refreshed them.
+type Worktree struct {
+	Branch string `yaml:"branch,omitempty" json:"branch,omitempty"`
+	Path   string `yaml:"path" json:"path"`
+}
+
+// Name is the branch, or the directory name for a detached worktree.
+func (w Worktree) Name() string {
+	if w.Branch != "" {
+		return w.Branch
+	}
+	return filepath.Base(w.Path)
+}
+
+type TmuxLayout struct {
+	Windows []TmuxWindow `yaml:"windows" json:"windows"`
+}
+
+type TmuxWindow struct {
+	Name   string   `yaml:"name" json:"name"`
+	Layout string   `yaml:"layout,omitempty" json:"layout,omitempty"`
+	Panes  []string `yaml:"panes,omitempty" json:"panes,omitempty"`
 }
 
 func NewProject(name, path string) Project {
@@ -50,6 +130,136 @@ func (p Project) WithEditor(editor string) Project {
 	return newP
 }
 
+func (p Project) WithTmuxLayout(layout *TmuxLayout) Project {
+	newP := p
+	newP.Tmux = layout
+	return newP
+}
+
+func (p Project) WithTags(tags ...string) Project {
+	newP := p
+	newP.Tags = normalizeTags(append(slices.Clone(p.Tags), tags...))
+	return newP
+}
+
+func (p Project) WithoutTags(tags ...string) Project {
+	newP := p
+	newP.Tags = slices.DeleteFunc(slices.Clone(p.Tags), func(t string) bool {
+		return slices.Contains(tags, t)
+	})
+	return newP
+}
+
+func (p Project) HasTag(tag string) bool {
+	return slices.Contains(p.Tags, tag)
+}
+
+func (p Project) WithRemote(remote string) Project {
+	newP := p
+	newP.Remote = remote
+	return newP
+}
+
+// WithStatus sets the project's status. A status set directly is no
+// longer restored when the project's monorepo is unarchived.
+func (p Project) WithStatus(status Status) Project {
+	newP := p
+	newP.Status = status
+	newP.StatusBeforeArchive = ""
+	return newP
+}
+
+// ArchiveWithParent archives a sub-project along with its monorepo and
+// remembers its status for RestoreWithParent. Archived projects are
+// returned unchanged.
+func (p Project) ArchiveWithParent() Project {
+	if p.Archived() {
+		return p
+	}
+	before := p.Status
+	if before == "" {
+		before = StatusActive
+	}
+	newP := p.WithStatus(StatusArchived)
+	newP.StatusBeforeArchive = before
+	return newP
+}
+
+// RestoreWithParent gives back the status ArchiveWithParent replaced.
+// Projects archived on their own are returned unchanged.
+func (p Project) RestoreWithParent() Project {
+	if !p.Archived() || p.StatusBeforeArchive == "" {
+		return p
+	}
+	if p.StatusBeforeArchive == StatusActive {
+		return p.WithStatus("")
+	}
+	return p.WithStatus(p.StatusBeforeArchive)
+}
+
+// WithArchivePath records the tarball the project's directory was
+// compressed into.
+func (p Project) WithArchivePath(path string) Project {
+	newP := p
+	newP.ArchivePath = path
+	return newP
+}
+
+// Archived reports whether the project is archived and so hidden by default.
+func (p Project) Archived() bool {
+	return p.Status == StatusArchived
+}
+
+func (p Project) WithParent(id string) Project {
+	newP := p
+	newP.Parent = id
+	return newP
+}
+
+func (p Project) WithWorktrees(worktrees []Worktree) Project {
+	newP := p
+	newP.Worktrees = worktrees
+	return newP
+}
+
+// Worktree finds a worktree by name.
+func (p Project) Worktree(name string) (Worktree, bool) {
+	i := slices.IndexFunc(p.Worktrees, func(w Worktree) bool { return w.Name() == name })
+	if i < 0 {
+		return Worktree{}, false
+	}
+	return p.Worktrees[i], true
+}
+
+func (p Project) WithStack(stack *Stack) Project {
+	newP := p
+	newP.Stack = stack
+	return newP
+}
+
+func (p Project) Languages() []string {
+	if p.Stack == nil {
+		return nil
+	}
+	return p.Stack.Languages
+}
+
+func (p Project) HasLanguage(lang string) bool {
+	return slices.ContainsFunc(p.Languages(), func(l string) bool { return strings.EqualFold(l, lang) })
+}
+
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			out = append(out, t)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
 func (p *Project) Touch() {
 	p.LastAccessed = time.Now()
 }
@@ -62,9 +272,39 @@ func ValidateName(name string) error {
 }
 
 func (p *Project) ValidateAndNormalize() error {
+	return p.ValidateAndNormalizeWith(config.PathMapper{})
+}
+
+// ValidateAndNormalizeWith resolves a portable path through m before
+// checking that it is absolute and exists.
+func (p *Project) ValidateAndNormalizeWith(m config.PathMapper) error {
 	if err := ValidateName(p.Name); err != nil {
 		return err
 	}
+	return p.normalizePath(m)
+}
+
+// validateUpdate checks p as a new version of existing. An unchanged path
+// is not checked again, so projects unavailable on this host can still be
+// edited.
+func (p *Project) validateUpdate(m config.PathMapper, existing Project) error {
+	if p.Path != existing.Path {
+		return p.ValidateAndNormalizeWith(m)
+	}
+	p.PortablePath, p.Unavailable = existing.PortablePath, existing.Unavailable
+	return ValidateName(p.Name)
+}
+
+func (p *Project) normalizePath(m config.PathMapper) error {
+	if config.IsPortablePath(p.Path) {
+		abs, err := m.Expand(p.Path)
+		if err != nil {
+			p.PortablePath = p.Path
+			return err
+		}
+		p.PortablePath = p.Path
+		p.Path = abs
+	}
 
 	if !filepath.IsAbs(p.Path) {
 		return fmt.Errorf("%w: got %q", ErrRelativePath, p.Path)
@@ -79,3 +319,7 @@ func (p *Project) ValidateAndNormalize() error {
 
 	return nil
 }
+
+func isUnavailable(err error) bool {
+	return errors.Is(err, ErrPathNotExist) || errors.Is(err, config.ErrUnknownRoot)
+}
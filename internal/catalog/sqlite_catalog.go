@@ -0,0 +1,863 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pj/internal/config"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS projects (
+	id            TEXT PRIMARY KEY,
+	name          TEXT NOT NULL,
+	path          TEXT NOT NULL UNIQUE,
+	added_at      INTEGER,
+	last_accessed INTEGER,
+	updated_at    INTEGER,
+	description   TEXT NOT NULL DEFAULT '',
+	editor        TEXT NOT NULL DEFAULT '',
+	tmux          TEXT
+);
+CREATE INDEX IF NOT EXISTS projects_name ON projects (name COLLATE NOCASE);
+
+CREATE TABLE IF NOT EXISTS project_tags (
+	project_id TEXT NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+	tag        TEXT NOT NULL,
+	PRIMARY KEY (project_id, tag)
+);
+CREATE INDEX IF NOT EXISTS project_tags_tag ON project_tags (tag);
+
+CREATE TABLE IF NOT EXISTS workspaces (
+	name   TEXT PRIMARY KEY,
+	editor TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS workspace_projects (
+	workspace  TEXT NOT NULL REFERENCES workspaces (name) ON DELETE CASCADE,
+	project_id TEXT NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+	position   INTEGER NOT NULL,
+	PRIMARY KEY (workspace, project_id)
+);
+
+PRAGMA user_version = 1;
+`
+
+// sqliteMigrations[i] upgrades a database from user_version i+1 to i+2.
+var sqliteMigrations = []string{
+	`ALTER TABLE projects ADD COLUMN stack TEXT`,
+	`ALTER TABLE projects ADD COLUMN remote TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE projects ADD COLUMN worktrees TEXT`,
+	`ALTER TABLE projects ADD COLUMN parent TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE projects ADD COLUMN status TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE projects ADD COLUMN archive_path TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE projects ADD COLUMN field_updated_at TEXT`,
+	`ALTER TABLE projects ADD COLUMN path_key TEXT NOT NULL DEFAULT '';
+	UPDATE projects SET path_key = path;
+	CREATE INDEX projects_path_key ON projects (path_key)`,
+	`ALTER TABLE projects ADD COLUMN status_before_archive TEXT NOT NULL DEFAULT ''`,
+}
+
+const projectColumns = "id, name, path, added_at, last_accessed, updated_at, description, editor, tmux, stack, remote, worktrees, parent, status, archive_path, status_before_archive, field_updated_at, path_key"
+
+// SQLiteCatalog stores the catalog in a SQLite database. Every mutation is
+// committed in its own transaction, so Save and Load have nothing to do.
+type SQLiteCatalog struct {
+	path   string
+	db     *sql.DB
+	mapper config.PathMapper
+
+	events eventHub
+	// refreshMu keeps refreshes in order, so watchers see changes in the
+	// order they were diffed.
+	refreshMu sync.Mutex
+	// known is the state last reported to watchers, diffed against the
+	// database after every change. knownMu is never held while publishing:
+	// Watch takes it from inside the event hub's lock.
+	knownMu sync.Mutex
+	known   map[string]Project
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func NewSQLiteCatalog(path string) (*SQLiteCatalog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := migrateSQLite(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize catalog database %q: %w", path, err)
+	}
+
+	return &SQLiteCatalog{path: path, db: db}, nil
+}
+
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version == 0 {
+		if _, err := db.Exec(sqliteSchema); err != nil {
+			return err
+		}
+		version = 1
+	}
+
+	for ; version <= len(sqliteMigrations); version++ {
+		err := withTx(db, func(tx *sql.Tx) error {
+			// Another process may have migrated since the version was read.
+			var current int
+			if err := tx.QueryRow("PRAGMA user_version").Scan(&current); err != nil || current > version {
+				return err
+			}
+			if _, err := tx.Exec(sqliteMigrations[version-1]); err != nil {
+				return fmt.Errorf("migrating to version %d: %w", version+1, err)
+			}
+			_, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithPathMapper sets how portable paths such as "~/src" or "${code}/api"
+// are resolved on read and written back on write.
+func (c *SQLiteCatalog) WithPathMapper(m config.PathMapper) *SQLiteCatalog {
+	c.mapper = m
+	return c
+}
+
+func (c *SQLiteCatalog) Close() error {
+	return c.db.Close()
+}
+
+func (c *SQLiteCatalog) Add(p Project) error {
+	return c.mutate(func(tx *sql.Tx) error { return c.add(tx, p) })
+}
+
+func (c *SQLiteCatalog) add(tx *sql.Tx, p Project) error {
+	if err := p.ValidateAndNormalizeWith(c.mapper); err != nil {
+		return err
+	}
+
+	if _, err := c.getByPath(tx, p.Path); err == nil {
+		return ErrAlreadyExists
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	stored := p
+	stored.Path = storedPath(c.mapper, p)
+	return insertProject(tx, stored, p.Path)
+}
+
+func (c *SQLiteCatalog) Get(id string) (Project, error) {
+	return c.get(c.db, id)
+}
+
+func (c *SQLiteCatalog) get(q querier, id string) (Project, error) {
+	projects, err := c.queryProjects(q, "WHERE id = ?", id)
+	if err != nil {
+		return Project{}, err
+	}
+	if len(projects) == 0 {
+		return Project{}, ErrNotFound
+	}
+	return projects[0], nil
+}
+
+func (c *SQLiteCatalog) GetByPath(path string) (Project, error) {
+	return c.getByPath(c.db, path)
+}
+
+func (c *SQLiteCatalog) getByPath(q querier, path string) (Project, error) {
+	projects, err := c.queryProjects(q, "WHERE path_key = ?", pathKey(c.mapper, path))
+	if err != nil {
+		return Project{}, err
+	}
+	if len(projects) == 0 {
+		return Project{}, ErrNotFound
+	}
+	return projects[0], nil
+}
+
+// pathKey is the form projects are looked up and searched by: the path as
+// it resolves on this host, or as stored when it does not resolve. Rows
+// keep it in path_key whichever form their path column holds.
+func pathKey(m config.PathMapper, path string) string {
+	if abs, err := m.Expand(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func (c *SQLiteCatalog) Update(p Project) error {
+	return c.mutate(func(tx *sql.Tx) error { return c.update(tx, p) })
+}
+
+func (c *SQLiteCatalog) update(tx *sql.Tx, p Project) error {
+	existing, err := c.get(tx, p.ID)
+	if err != nil {
+		return err
+	}
+	if err := p.validateUpdate(c.mapper, existing); err != nil {
+		return err
+	}
+
+	if existing.Path != p.Path {
+		other, err := c.getByPath(tx, p.Path)
+		if err == nil && other.ID != p.ID {
+			return ErrAlreadyExists
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	if now := time.Now(); p.stampChanges(existing, now) {
+		p.UpdatedAt = now
+	}
+	stored := p
+	stored.Path = storedPath(c.mapper, p)
+	if _, err := tx.Exec("DELETE FROM project_tags WHERE project_id = ?", p.ID); err != nil {
+		return err
+	}
+	return updateProject(tx, stored, p.Path)
+}
+
+func (c *SQLiteCatalog) Remove(id string) error {
+	return c.mutate(func(tx *sql.Tx) error { return removeProject(tx, id) })
+}
+
+func removeProject(tx execer, id string) error {
+	res, err := tx.Exec("DELETE FROM projects WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (c *SQLiteCatalog) List() []Project {
+	projects, _ := c.queryProjects(c.db, "")
+	return projects
+}
+
+// Batch runs fn inside a single database transaction.
+func (c *SQLiteCatalog) Batch(fn func(tx Tx) error) error {
+	rec := &batchRecorder{}
+	return c.mutate(func(tx *sql.Tx) error {
+		return rec.finish(fn(&sqliteTx{cat: c, tx: tx, rec: rec}))
+	})
+}
+
+type sqliteTx struct {
+	cat *SQLiteCatalog
+	tx  *sql.Tx
+	rec *batchRecorder
+}
+
+func (t *sqliteTx) Add(p Project) error {
+	return t.rec.record("add", projectLabel(p), t.cat.add(t.tx, p))
+}
+
+func (t *sqliteTx) Update(p Project) error {
+	return t.rec.record("update", projectLabel(p), t.cat.update(t.tx, p))
+}
+
+func (t *sqliteTx) Remove(id string) error {
+	return t.rec.record("remove", id, removeProject(t.tx, id))
+}
+
+func (t *sqliteTx) Get(id string) (Project, error) {
+	return t.cat.get(t.tx, id)
+}
+
+func (t *sqliteTx) GetByPath(path string) (Project, error) {
+	return t.cat.getByPath(t.tx, path)
+}
+
+func (t *sqliteTx) List() []Project {
+	projects, _ := t.cat.queryProjects(t.tx, "")
+	return projects
+}
+
+func (t *sqliteTx) AddWorkspace(w Workspace) error {
+	return t.rec.record("add workspace", w.Name, addWorkspace(t.tx, w))
+}
+
+func (t *sqliteTx) GetWorkspace(name string) (Workspace, error) {
+	return getWorkspace(t.tx, name)
+}
+
+func (t *sqliteTx) UpdateWorkspace(w Workspace) error {
+	return t.rec.record("update workspace", w.Name, updateWorkspace(t.tx, w))
+}
+
+func (t *sqliteTx) RemoveWorkspace(name string) error {
+	return t.rec.record("remove workspace", name, removeWorkspace(t.tx, name))
+}
+
+func (t *sqliteTx) ListWorkspaces() []Workspace {
+	workspaces, _ := queryWorkspaces(t.tx, "")
+	return workspaces
+}
+
+func (c *SQLiteCatalog) Search(query string) []Project {
+	where, args := filterClause(FilterOptions{Query: query})
+	projects, _ := c.queryProjects(c.db, where, args...)
+	return projects
+}
+
+func (c *SQLiteCatalog) Filter(opts FilterOptions) []Project {
+	where, args := filterClause(opts)
+	projects, _ := c.queryProjects(c.db, where, args...)
+
+	// Languages live in the stack JSON, so they are matched here.
+	if opts.Language != "" {
+		projects = slices.DeleteFunc(projects, func(p Project) bool { return !p.HasLanguage(opts.Language) })
+	}
+	sortProjects(projects, opts.SortBy, opts.Descending)
+	return projects
+}
+
+// filterClause is matchesFilter as SQL, except for the language.
+func filterClause(opts FilterOptions) (string, []any) {
+	var conds []string
+	var args []any
+	if opts.Query != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(opts.Query)) + "%"
+		conds = append(conds, `(name LIKE ? ESCAPE '\' OR path_key LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+	if opts.Tag != "" {
+		conds = append(conds, "id IN (SELECT project_id FROM project_tags WHERE tag = ?)")
+		args = append(args, opts.Tag)
+	}
+	if opts.ExcludeArchived {
+		conds = append(conds, "status != ?")
+		args = append(args, StatusArchived)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (c *SQLiteCatalog) Count() int {
+	var n int
+	_ = c.db.QueryRow("SELECT COUNT(*) FROM projects").Scan(&n)
+	return n
+}
+
+// Save is a no-op: every mutation is committed as it happens.
+func (c *SQLiteCatalog) Save() error {
+	return nil
+}
+
+// Load re-derives each project's path key, since what a portable path
+// resolves to depends on this host's roots. Reads always go to the
+// database.
+func (c *SQLiteCatalog) Load() error {
+	return c.withTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT id, path, path_key FROM projects")
+		if err != nil {
+			return err
+		}
+		stale := make(map[string]string)
+		for rows.Next() {
+			var id, path, key string
+			if err := rows.Scan(&id, &path, &key); err != nil {
+				rows.Close()
+				return err
+			}
+			if want := pathKey(c.mapper, path); want != key {
+				stale[id] = want
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for id, key := range stale {
+			if _, err := tx.Exec("UPDATE projects SET path_key = ? WHERE id = ?", key, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *SQLiteCatalog) AddWorkspace(w Workspace) error {
+	return c.withTx(func(tx *sql.Tx) error { return addWorkspace(tx, w) })
+}
+
+func addWorkspace(tx *sql.Tx, w Workspace) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	if _, err := getWorkspace(tx, w.Name); err == nil {
+		return ErrWorkspaceExists
+	} else if !errors.Is(err, ErrWorkspaceNotFound) {
+		return err
+	}
+	if err := checkWorkspaceProjects(tx, w); err != nil {
+		return err
+	}
+	return insertWorkspace(tx, w)
+}
+
+func (c *SQLiteCatalog) GetWorkspace(name string) (Workspace, error) {
+	return getWorkspace(c.db, name)
+}
+
+func (c *SQLiteCatalog) UpdateWorkspace(w Workspace) error {
+	return c.withTx(func(tx *sql.Tx) error { return updateWorkspace(tx, w) })
+}
+
+func updateWorkspace(tx *sql.Tx, w Workspace) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	if _, err := getWorkspace(tx, w.Name); err != nil {
+		return err
+	}
+	if err := checkWorkspaceProjects(tx, w); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM workspaces WHERE name = ?", w.Name); err != nil {
+		return err
+	}
+	return insertWorkspace(tx, w)
+}
+
+func (c *SQLiteCatalog) RemoveWorkspace(name string) error {
+	return removeWorkspace(c.db, name)
+}
+
+func removeWorkspace(tx execer, name string) error {
+	res, err := tx.Exec("DELETE FROM workspaces WHERE name = ?", name)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrWorkspaceNotFound
+	}
+	return nil
+}
+
+func (c *SQLiteCatalog) ListWorkspaces() []Workspace {
+	workspaces, _ := queryWorkspaces(c.db, "")
+	return workspaces
+}
+
+// Watch reports every change to the catalog's projects until ctx is done,
+// including writes to the database by other processes.
+func (c *SQLiteCatalog) Watch(ctx context.Context) <-chan Event {
+	return c.events.subscribe(ctx, func() func() {
+		c.knownMu.Lock()
+		projects, _ := c.queryProjects(c.db, "")
+		c.known = indexProjects(projects)
+		c.knownMu.Unlock()
+		return startFileWatch(c.path, c.refresh)
+	})
+}
+
+// refresh publishes whatever changed in the database since the last call.
+func (c *SQLiteCatalog) refresh() {
+	if !c.events.active() {
+		return
+	}
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	c.knownMu.Lock()
+	projects, err := c.queryProjects(c.db, "")
+	if err != nil {
+		c.knownMu.Unlock()
+		return
+	}
+	current := indexProjects(projects)
+	events := diffProjects(c.known, current)
+	c.known = current
+	c.knownMu.Unlock()
+
+	c.events.publish(events...)
+}
+
+// mutate commits fn and tells watchers about the result.
+func (c *SQLiteCatalog) mutate(fn func(tx *sql.Tx) error) error {
+	if err := c.withTx(fn); err != nil {
+		return err
+	}
+	c.refresh()
+	return nil
+}
+
+func (c *SQLiteCatalog) withTx(fn func(tx *sql.Tx) error) error {
+	return withTx(c.db, fn)
+}
+
+func withTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// queryProjects returns the projects matching where, resolved for this host.
+func (c *SQLiteCatalog) queryProjects(q querier, where string, args ...any) ([]Project, error) {
+	projects, err := queryStoredProjects(q, where, args...)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range projects {
+		projects[i] = resolveStored(c.mapper, p)
+	}
+	return projects, nil
+}
+
+// queryStoredProjects returns the projects matching where exactly as stored.
+func queryStoredProjects(q querier, where string, args ...any) ([]Project, error) {
+	rows, err := q.Query("SELECT "+projectColumns+" FROM projects "+where+" ORDER BY name, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []Project
+	index := make(map[string]int)
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		index[p.ID] = len(projects)
+		projects = append(projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, nil
+	}
+
+	tagRows, err := q.Query("SELECT project_id, tag FROM project_tags WHERE project_id IN (SELECT id FROM projects "+where+") ORDER BY tag", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer tagRows.Close()
+
+	for tagRows.Next() {
+		var id, tag string
+		if err := tagRows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		if i, ok := index[id]; ok {
+			projects[i].Tags = append(projects[i].Tags, tag)
+		}
+	}
+	return projects, tagRows.Err()
+}
+
+func scanProject(rows *sql.Rows) (Project, error) {
+	var p Project
+	var addedAt, lastAccessed, updatedAt sql.NullInt64
+	var tmux, stack, worktrees, fieldUpdatedAt sql.NullString
+	var key string
+	err := rows.Scan(&p.ID, &p.Name, &p.Path, &addedAt, &lastAccessed, &updatedAt, &p.Description, &p.Editor, &tmux, &stack, &p.Remote, &worktrees, &p.Parent, &p.Status, &p.ArchivePath, &p.StatusBeforeArchive, &fieldUpdatedAt, &key)
+	if err != nil {
+		return Project{}, err
+	}
+
+	p.AddedAt = fromUnixNano(addedAt)
+	p.LastAccessed = fromUnixNano(lastAccessed)
+	p.UpdatedAt = fromUnixNano(updatedAt)
+	if tmux.Valid {
+		p.Tmux = &TmuxLayout{}
+		if err := json.Unmarshal([]byte(tmux.String), p.Tmux); err != nil {
+			return Project{}, fmt.Errorf("invalid tmux layout for project %s: %w", p.ID, err)
+		}
+	}
+	if stack.Valid {
+		p.Stack = &Stack{}
+		if err := json.Unmarshal([]byte(stack.String), p.Stack); err != nil {
+			return Project{}, fmt.Errorf("invalid stack for project %s: %w", p.ID, err)
+		}
+	}
+	if worktrees.Valid {
+		if err := json.Unmarshal([]byte(worktrees.String), &p.Worktrees); err != nil {
+			return Project{}, fmt.Errorf("invalid worktrees for project %s: %w", p.ID, err)
+		}
+	}
+	if fieldUpdatedAt.Valid {
+		if err := json.Unmarshal([]byte(fieldUpdatedAt.String), &p.FieldUpdatedAt); err != nil {
+			return Project{}, fmt.Errorf("invalid field times for project %s: %w", p.ID, err)
+		}
+	}
+	return p, nil
+}
+
+// projectArgs are the projectColumns values for p, whose path is looked up
+// by key.
+func projectArgs(p Project, key string) ([]any, error) {
+	tmux, err := jsonColumn(p.Tmux)
+	if err != nil {
+		return nil, err
+	}
+	stack, err := jsonColumn(p.Stack)
+	if err != nil {
+		return nil, err
+	}
+	var worktrees, fieldUpdatedAt any
+	if len(p.Worktrees) > 0 {
+		if worktrees, err = jsonColumn(&p.Worktrees); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.FieldUpdatedAt) > 0 {
+		if fieldUpdatedAt, err = jsonColumn(&p.FieldUpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+	return []any{
+		p.ID, p.Name, p.Path,
+		toUnixNano(p.AddedAt), toUnixNano(p.LastAccessed), toUnixNano(p.UpdatedAt),
+		p.Description, p.Editor, tmux, stack, p.Remote, worktrees, p.Parent, p.Status, p.ArchivePath,
+		p.StatusBeforeArchive, fieldUpdatedAt, key,
+	}, nil
+}
+
+// jsonColumn encodes v for a nullable JSON column.
+func jsonColumn[T any](v *T) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func insertProject(tx execer, p Project, key string) error {
+	args, err := projectArgs(p, key)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO projects ("+projectColumns+") VALUES (?"+strings.Repeat(", ?", len(args)-1)+")", args...); err != nil {
+		return err
+	}
+	return insertTags(tx, p)
+}
+
+func updateProject(tx execer, p Project, key string) error {
+	args, err := projectArgs(p, key)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE projects SET name = ?, path = ?, added_at = ?, last_accessed = ?,
+		updated_at = ?, description = ?, editor = ?, tmux = ?, stack = ?, remote = ?, worktrees = ?, parent = ?,
+		status = ?, archive_path = ?, status_before_archive = ?, field_updated_at = ?, path_key = ? WHERE id = ?`,
+		append(args[1:], args[0])...)
+	if err != nil {
+		return err
+	}
+	return insertTags(tx, p)
+}
+
+func insertTags(tx execer, p Project) error {
+	for _, tag := range p.Tags {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO project_tags (project_id, tag) VALUES (?, ?)", p.ID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getWorkspace(q querier, name string) (Workspace, error) {
+	workspaces, err := queryWorkspaces(q, "WHERE name = ?", name)
+	if err != nil {
+		return Workspace{}, err
+	}
+	if len(workspaces) == 0 {
+		return Workspace{}, ErrWorkspaceNotFound
+	}
+	return workspaces[0], nil
+}
+
+func queryWorkspaces(q querier, where string, args ...any) ([]Workspace, error) {
+	rows, err := q.Query("SELECT name, editor FROM workspaces "+where+" ORDER BY name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workspaces []Workspace
+	index := make(map[string]int)
+	for rows.Next() {
+		var w Workspace
+		if err := rows.Scan(&w.Name, &w.Editor); err != nil {
+			return nil, err
+		}
+		index[w.Name] = len(workspaces)
+		workspaces = append(workspaces, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(workspaces) == 0 {
+		return nil, nil
+	}
+
+	memberRows, err := q.Query("SELECT workspace, project_id FROM workspace_projects WHERE workspace IN (SELECT name FROM workspaces "+where+") ORDER BY workspace, position", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer memberRows.Close()
+
+	for memberRows.Next() {
+		var name, id string
+		if err := memberRows.Scan(&name, &id); err != nil {
+			return nil, err
+		}
+		if i, ok := index[name]; ok {
+			workspaces[i].ProjectIDs = append(workspaces[i].ProjectIDs, id)
+		}
+	}
+	return workspaces, memberRows.Err()
+}
+
+func insertWorkspace(tx execer, w Workspace) error {
+	if _, err := tx.Exec("INSERT INTO workspaces (name, editor) VALUES (?, ?)", w.Name, w.Editor); err != nil {
+		return err
+	}
+	for i, id := range w.ProjectIDs {
+		_, err := tx.Exec("INSERT OR IGNORE INTO workspace_projects (workspace, project_id, position) VALUES (?, ?, ?)", w.Name, id, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkWorkspaceProjects(q querier, w Workspace) error {
+	for _, id := range w.ProjectIDs {
+		var exists int
+		err := q.QueryRow("SELECT 1 FROM projects WHERE id = ?", id).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toUnixNano(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n sql.NullInt64) time.Time {
+	if !n.Valid {
+		return time.Time{}
+	}
+	return time.Unix(0, n.Int64)
+}
+
+// readSQLiteFile reads a catalog database as stored. A missing file yields
+// an empty snapshot.
+func readSQLiteFile(path string) (Snapshot, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Snapshot{}, nil
+	}
+
+	c, err := NewSQLiteCatalog(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer c.Close()
+
+	projects, err := queryStoredProjects(c.db, "")
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read catalog database %q: %w", path, err)
+	}
+	workspaces, err := queryWorkspaces(c.db, "")
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read catalog database %q: %w", path, err)
+	}
+	return Snapshot{Projects: projects, Workspaces: workspaces}, nil
+}
+
+// writeSQLiteFile replaces the contents of a catalog database with snap in
+// a single transaction.
+func writeSQLiteFile(path string, snap Snapshot) error {
+	c, err := NewSQLiteCatalog(path)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return c.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM workspaces; DELETE FROM projects"); err != nil {
+			return err
+		}
+		for _, p := range snap.Projects {
+			if err := insertProject(tx, p, pathKey(config.PathMapper{}, p.Path)); err != nil {
+				return fmt.Errorf("project %s: %w", p.ID, err)
+			}
+		}
+		for _, w := range snap.Workspaces {
+			if err := insertWorkspace(tx, w); err != nil {
+				return fmt.Errorf("workspace %s: %w", w.Name, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,388 @@
+package catalog_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQLiteCatalog(t *testing.T) (*catalog.SQLiteCatalog, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "catalog.db")
+	cat, err := catalog.NewSQLiteCatalog(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cat.Close() })
+	return cat, path
+}
+
+func TestSQLiteCatalog_AddGet(t *testing.T) {
+	t.Run("round trips all fields", func(t *testing.T) {
+		cat, _ := newTestSQLiteCatalog(t)
+		p := catalog.NewProject("api", newTestDir(t)).
+			WithDescription("REST backend").
+			WithEditor("nvim").
+			WithTags("work", "go").
+			WithTmuxLayout(&catalog.TmuxLayout{Windows: []catalog.TmuxWindow{{Name: "main", Panes: []string{"vim"}}}}).
+			WithStack(&catalog.Stack{Languages: []string{"go"}, PackageManagers: []string{"go"}}).
+			WithRemote("git@github.com:acme/api.git").
+			WithWorktrees([]catalog.Worktree{{Branch: "feature-x", Path: "/srv/api.worktrees/feature-x"}}).
+			WithParent("mono-id").
+			ArchiveWithParent().
+			WithArchivePath("/srv/archive/api.tar.gz")
+		p.FieldUpdatedAt = map[string]time.Time{"status": time.Unix(1700000000, 0).UTC()}
+
+		require.NoError(t, cat.Add(p))
+		got, err := cat.Get(p.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, p.Name, got.Name)
+		assert.Equal(t, p.Path, got.Path)
+		assert.Equal(t, p.Description, got.Description)
+		assert.Equal(t, p.Editor, got.Editor)
+		assert.Equal(t, []string{"go", "work"}, got.Tags)
+		assert.Equal(t, p.Tmux, got.Tmux)
+		assert.Equal(t, p.Stack, got.Stack)
+		assert.Equal(t, p.Remote, got.Remote)
+		assert.Equal(t, p.Worktrees, got.Worktrees)
+		assert.Equal(t, p.Parent, got.Parent)
+		assert.Equal(t, p.Status, got.Status)
+		assert.Equal(t, p.ArchivePath, got.ArchivePath)
+		assert.Equal(t, catalog.StatusActive, got.StatusBeforeArchive)
+		assert.Equal(t, p.FieldUpdatedAt, got.FieldUpdatedAt)
+		assert.True(t, p.AddedAt.Equal(got.AddedAt))
+		assert.True(t, got.UpdatedAt.IsZero())
+	})
+
+	t.Run("rejects duplicate path", func(t *testing.T) {
+		cat, _ := newTestSQLiteCatalog(t)
+		dir := newTestDir(t)
+		require.NoError(t, cat.Add(catalog.NewProject("a", dir)))
+
+		err := cat.Add(catalog.NewProject("b", dir))
+
+		assert.ErrorIs(t, err, catalog.ErrAlreadyExists)
+		assert.Equal(t, 1, cat.Count())
+	})
+
+	t.Run("returns not found for unknown id", func(t *testing.T) {
+		cat, _ := newTestSQLiteCatalog(t)
+
+		_, err := cat.Get("nope")
+
+		assert.ErrorIs(t, err, catalog.ErrNotFound)
+	})
+}
+
+func TestSQLiteCatalog_GetByPath(t *testing.T) {
+	cat, _ := newTestSQLiteCatalog(t)
+	p := catalog.NewProject("api", newTestDir(t))
+	require.NoError(t, cat.Add(p))
+
+	got, err := cat.GetByPath(p.Path)
+	require.NoError(t, err)
+	assert.Equal(t, p.ID, got.ID)
+
+	_, err = cat.GetByPath("/nope")
+	assert.ErrorIs(t, err, catalog.ErrNotFound)
+}
+
+func TestSQLiteCatalog_GetByPathEitherForm(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "api"), 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(root, "web"), 0o755))
+	cat, _ := newTestSQLiteCatalog(t)
+	// api is stored expanded, web in portable form.
+	cat.WithPathMapper(config.PathMapper{Roots: map[string]string{"code": root}})
+	api := catalog.NewProject("api", filepath.Join(root, "api"))
+	require.NoError(t, cat.Add(api))
+	cat.WithPathMapper(config.PathMapper{Roots: map[string]string{"code": root}, Portable: true})
+	web := catalog.NewProject("web", filepath.Join(root, "web"))
+	require.NoError(t, cat.Add(web))
+	require.NoError(t, cat.Load())
+
+	for _, tc := range []struct{ path, id string }{
+		{filepath.Join(root, "api"), api.ID},
+		{"${code}/api", api.ID},
+		{filepath.Join(root, "web"), web.ID},
+		{"${code}/web", web.ID},
+	} {
+		got, err := cat.GetByPath(tc.path)
+		require.NoError(t, err, tc.path)
+		assert.Equal(t, tc.id, got.ID, tc.path)
+	}
+	assert.ErrorIs(t, cat.Add(catalog.NewProject("dup", "${code}/api")), catalog.ErrAlreadyExists)
+}
+
+func TestSQLiteCatalog_Update(t *testing.T) {
+	t.Run("replaces fields and tags", func(t *testing.T) {
+		cat, _ := newTestSQLiteCatalog(t)
+		p := catalog.NewProject("api", newTestDir(t)).WithTags("old")
+		require.NoError(t, cat.Add(p))
+
+		require.NoError(t, cat.Update(p.WithDescription("new").WithoutTags("old").WithTags("fresh")))
+
+		got, err := cat.Get(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "new", got.Description)
+		assert.Equal(t, []string{"fresh"}, got.Tags)
+		assert.False(t, got.UpdatedAt.IsZero())
+	})
+
+	t.Run("rejects moving onto another project's path", func(t *testing.T) {
+		cat, _ := newTestSQLiteCatalog(t)
+		a := catalog.NewProject("a", newTestDir(t))
+		b := catalog.NewProject("b", newTestDir(t))
+		require.NoError(t, cat.Add(a))
+		require.NoError(t, cat.Add(b))
+
+		b.Path = a.Path
+		err := cat.Update(b)
+
+		assert.ErrorIs(t, err, catalog.ErrAlreadyExists)
+	})
+
+	t.Run("returns not found for unknown project", func(t *testing.T) {
+		cat, _ := newTestSQLiteCatalog(t)
+
+		err := cat.Update(catalog.NewProject("a", newTestDir(t)))
+
+		assert.ErrorIs(t, err, catalog.ErrNotFound)
+	})
+}
+
+func TestSQLiteCatalog_Remove(t *testing.T) {
+	cat, _ := newTestSQLiteCatalog(t)
+	a := catalog.NewProject("a", newTestDir(t))
+	b := catalog.NewProject("b", newTestDir(t))
+	require.NoError(t, cat.Add(a))
+	require.NoError(t, cat.Add(b))
+	require.NoError(t, cat.AddWorkspace(catalog.NewWorkspace("stack", a.ID, b.ID)))
+
+	require.NoError(t, cat.Remove(a.ID))
+
+	assert.Equal(t, 1, cat.Count())
+	w, err := cat.GetWorkspace("stack")
+	require.NoError(t, err)
+	assert.Equal(t, []string{b.ID}, w.ProjectIDs)
+	assert.ErrorIs(t, cat.Remove(a.ID), catalog.ErrNotFound)
+}
+
+func TestSQLiteCatalog_Filter(t *testing.T) {
+	cat, _ := newTestSQLiteCatalog(t)
+	require.NoError(t, cat.Add(catalog.NewProject("web", newTestDir(t)).WithTags("work")))
+	require.NoError(t, cat.Add(catalog.NewProject("api", newTestDir(t)).WithTags("work")))
+	require.NoError(t, cat.Add(catalog.NewProject("dotfiles", newTestDir(t))))
+
+	got := cat.Filter(catalog.FilterOptions{Tag: "work", SortBy: catalog.SortByName})
+
+	require.Len(t, got, 2)
+	assert.Equal(t, "api", got[0].Name)
+	assert.Equal(t, "web", got[1].Name)
+	assert.Len(t, cat.Search("DOT"), 1)
+}
+
+func TestSQLiteCatalog_FilterInSQL(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"my_api", "myxapi", "web"} {
+		require.NoError(t, os.Mkdir(filepath.Join(root, name), 0o755))
+	}
+	cat, _ := newTestSQLiteCatalog(t)
+	cat.WithPathMapper(config.PathMapper{Roots: map[string]string{"code": root}, Portable: true})
+	require.NoError(t, cat.Add(catalog.NewProject("my_api", filepath.Join(root, "my_api")).WithTags("work")))
+	require.NoError(t, cat.Add(catalog.NewProject("myxapi", filepath.Join(root, "myxapi")).WithTags("work")))
+	require.NoError(t, cat.Add(catalog.NewProject("web", filepath.Join(root, "web")).
+		WithTags("work").WithStatus(catalog.StatusArchived)))
+
+	names := func(projects []catalog.Project) []string {
+		var out []string
+		for _, p := range projects {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+	// "_" is matched literally, not as a LIKE wildcard.
+	assert.Equal(t, []string{"my_api"}, names(cat.Search("Y_A")))
+	// Paths match as resolved on this host, not as stored.
+	assert.Equal(t, []string{"web"}, names(cat.Search(filepath.Join(root, "web"))))
+	assert.Equal(t, []string{"my_api", "myxapi"},
+		names(cat.Filter(catalog.FilterOptions{Tag: "work", ExcludeArchived: true, SortBy: catalog.SortByName})))
+	assert.Empty(t, cat.Filter(catalog.FilterOptions{Query: "web", ExcludeArchived: true}))
+}
+
+func TestSQLiteCatalog_LoadRekeysPaths(t *testing.T) {
+	oldRoot, newRoot := t.TempDir(), t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(oldRoot, "api"), 0o755))
+	cat, _ := newTestSQLiteCatalog(t)
+	cat.WithPathMapper(config.PathMapper{Roots: map[string]string{"code": oldRoot}, Portable: true})
+	require.NoError(t, cat.Add(catalog.NewProject("api", filepath.Join(oldRoot, "api"))))
+
+	// The same catalog on a host where ${code} lives elsewhere.
+	cat.WithPathMapper(config.PathMapper{Roots: map[string]string{"code": newRoot}, Portable: true})
+	require.NoError(t, cat.Load())
+
+	got, err := cat.GetByPath(filepath.Join(newRoot, "api"))
+	require.NoError(t, err)
+	assert.Equal(t, "api", got.Name)
+	_, err = cat.GetByPath(filepath.Join(oldRoot, "api"))
+	assert.ErrorIs(t, err, catalog.ErrNotFound)
+}
+
+func TestSQLiteCatalog_MigratesOlderDatabases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.db")
+	db, err := sql.Open("sqlite", "file:"+path)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE projects (
+			id TEXT PRIMARY KEY, name TEXT NOT NULL, path TEXT NOT NULL UNIQUE,
+			added_at INTEGER, last_accessed INTEGER, updated_at INTEGER,
+			description TEXT NOT NULL DEFAULT '', editor TEXT NOT NULL DEFAULT '', tmux TEXT
+		);
+		CREATE TABLE project_tags (project_id TEXT NOT NULL, tag TEXT NOT NULL, PRIMARY KEY (project_id, tag));
+		CREATE TABLE workspaces (name TEXT PRIMARY KEY, editor TEXT NOT NULL DEFAULT '');
+		CREATE TABLE workspace_projects (workspace TEXT NOT NULL, project_id TEXT NOT NULL, position INTEGER NOT NULL);
+		PRAGMA user_version = 1;`)
+	require.NoError(t, err)
+	dir := newTestDir(t)
+	_, err = db.Exec("INSERT INTO projects (id, name, path) VALUES ('old', 'legacy', ?)", dir)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	cat, err := catalog.NewSQLiteCatalog(path)
+	require.NoError(t, err)
+	defer cat.Close()
+
+	p, err := cat.Get("old")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", p.Name)
+	assert.Nil(t, p.Stack)
+	assert.Empty(t, p.Remote)
+	assert.Empty(t, p.Worktrees)
+
+	require.NoError(t, cat.Update(p.WithStack(&catalog.Stack{Languages: []string{"go"}}).WithRemote("/srv/git/legacy.git")))
+	got, err := cat.Get("old")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go"}, got.Languages())
+	assert.Equal(t, "/srv/git/legacy.git", got.Remote)
+
+	reopened, err := catalog.NewSQLiteCatalog(path)
+	require.NoError(t, err, "migrations are applied once")
+	require.NoError(t, reopened.Close())
+}
+
+func TestSQLiteCatalog_Workspaces(t *testing.T) {
+	cat, _ := newTestSQLiteCatalog(t)
+	a := catalog.NewProject("a", newTestDir(t))
+	b := catalog.NewProject("b", newTestDir(t))
+	require.NoError(t, cat.Add(a))
+	require.NoError(t, cat.Add(b))
+
+	require.NoError(t, cat.AddWorkspace(catalog.NewWorkspace("stack", b.ID, a.ID)))
+	assert.ErrorIs(t, cat.AddWorkspace(catalog.NewWorkspace("stack")), catalog.ErrWorkspaceExists)
+	assert.ErrorIs(t, cat.AddWorkspace(catalog.NewWorkspace("bad", "nope")), catalog.ErrNotFound)
+
+	require.NoError(t, cat.UpdateWorkspace(catalog.NewWorkspace("stack", b.ID).WithEditor("code")))
+	got, err := cat.GetWorkspace("stack")
+	require.NoError(t, err)
+	assert.Equal(t, catalog.NewWorkspace("stack", b.ID).WithEditor("code"), got)
+
+	require.NoError(t, cat.RemoveWorkspace("stack"))
+	assert.Empty(t, cat.ListWorkspaces())
+	assert.ErrorIs(t, cat.RemoveWorkspace("stack"), catalog.ErrWorkspaceNotFound)
+}
+
+func TestSQLiteCatalog_PersistsWithoutSave(t *testing.T) {
+	cat, path := newTestSQLiteCatalog(t)
+	p := catalog.NewProject("api", newTestDir(t))
+	require.NoError(t, cat.Add(p))
+
+	reopened, err := catalog.NewSQLiteCatalog(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	got, err := reopened.Get(p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "api", got.Name)
+}
+
+func TestSQLiteCatalog_PortablePaths(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "api"), 0o755))
+	mapper := config.PathMapper{Roots: map[string]string{"code": root}, Portable: true}
+	cat, path := newTestSQLiteCatalog(t)
+	cat.WithPathMapper(mapper)
+
+	p := catalog.NewProject("api", filepath.Join(root, "api"))
+	require.NoError(t, cat.Add(p))
+
+	snap, err := catalog.ReadSnapshot(path, catalog.BackendSQLite)
+	require.NoError(t, err)
+	require.Len(t, snap.Projects, 1)
+	assert.Equal(t, "${code}/api", snap.Projects[0].Path)
+
+	got, err := cat.GetByPath(p.Path)
+	require.NoError(t, err)
+	assert.Equal(t, p.ID, got.ID)
+	assert.Equal(t, "${code}/api", got.PortablePath)
+
+	// On a host without the project checked out it can still be edited.
+	require.NoError(t, os.Remove(p.Path))
+	got, err = cat.Get(p.ID)
+	require.NoError(t, err)
+	require.True(t, got.Unavailable)
+	require.NoError(t, cat.Update(got.WithTags("synced")))
+	got, err = cat.Get(p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"synced"}, got.Tags)
+}
+
+func TestBackendForPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		fallback catalog.Backend
+		want     catalog.Backend
+	}{
+		{"catalog.yaml", catalog.BackendSQLite, catalog.BackendYAML},
+		{"catalog.db", "", catalog.BackendSQLite},
+		{"catalog.SQLITE3", "", catalog.BackendSQLite},
+		{"catalog", catalog.BackendSQLite, catalog.BackendSQLite},
+		{"catalog", "", catalog.BackendYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, catalog.BackendForPath(tt.path, tt.fallback))
+		})
+	}
+}
+
+func TestSnapshot_MigrateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	snap := catalog.Snapshot{
+		Projects: []catalog.Project{
+			mergeProject("a", "api", "/src/api").WithTags("go"),
+			mergeProject("b", "web", "${code}/web"),
+		},
+		Workspaces: []catalog.Workspace{catalog.NewWorkspace("stack", "b", "a")},
+	}
+	dbPath := filepath.Join(dir, "catalog.db")
+	yamlPath := filepath.Join(dir, "catalog.yaml")
+
+	require.NoError(t, catalog.WriteSnapshot(dbPath, catalog.BackendSQLite, snap))
+	fromDB, err := catalog.ReadSnapshot(dbPath, catalog.BackendSQLite)
+	require.NoError(t, err)
+	require.NoError(t, catalog.WriteSnapshot(yamlPath, catalog.BackendYAML, fromDB))
+	got, err := catalog.ReadSnapshot(yamlPath, catalog.BackendYAML)
+	require.NoError(t, err)
+
+	require.Len(t, got.Projects, 2)
+	assert.Equal(t, "${code}/web", got.Projects[1].Path)
+	assert.Equal(t, []string{"go"}, got.Projects[0].Tags)
+	assert.True(t, snap.Projects[0].AddedAt.Equal(got.Projects[0].AddedAt))
+	assert.Equal(t, snap.Workspaces, got.Workspaces)
+}
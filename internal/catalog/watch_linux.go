@@ -0,0 +1,58 @@
+package catalog
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchFile calls onChange whenever path, or a file named after it such as
+// SQLite's "-wal", is written or replaced. It watches the parent directory
+// so atomic renames are seen. The watch is in place when it returns.
+func watchFile(path string, onChange func()) (stop func(), err error) {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	const mask = unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO | unix.IN_MOVED_FROM | unix.IN_DELETE
+	if _, err := unix.InotifyAddWatch(fd, filepath.Dir(path), mask); err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+
+	// A non-blocking fd gets a pollable File, so Close unblocks Read.
+	f := os.NewFile(uintptr(fd), "inotify")
+	name := filepath.Base(path)
+	go func() {
+		buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			if inotifyNames(buf[:n], name) {
+				onChange()
+			}
+		}
+	}()
+
+	return func() { _ = f.Close() }, nil
+}
+
+// inotifyNames reports whether any event in buf names name or one of its
+// companion files.
+func inotifyNames(buf []byte, name string) bool {
+	for off := 0; off+unix.SizeofInotifyEvent <= len(buf); {
+		nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+		start := off + unix.SizeofInotifyEvent
+		evName := strings.TrimRight(string(buf[start:start+nameLen]), "\x00")
+		if evName == name || strings.HasPrefix(evName, name+"-") {
+			return true
+		}
+		off = start + nameLen
+	}
+	return false
+}
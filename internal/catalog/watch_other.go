@@ -0,0 +1,43 @@
+//go:build !linux
+
+package catalog
+
+import (
+	"os"
+	"time"
+)
+
+const watchPollInterval = time.Second
+
+// watchFile polls path's size and modification time, calling onChange when
+// either moves.
+func watchFile(path string, onChange func()) (stop func(), err error) {
+	stamp := func() (time.Time, int64) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+
+	done := make(chan struct{})
+	lastMod, lastSize := stamp()
+	go func() {
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mod, size := stamp()
+				if !mod.Equal(lastMod) || size != lastSize {
+					lastMod, lastSize = mod, size
+					onChange()
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }, nil
+}
@@ -0,0 +1,56 @@
+package catalog
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrWorkspaceExists   = errors.New("workspace already exists")
+)
+
+type Workspace struct {
+	Name       string   `yaml:"name"`
+	ProjectIDs []string `yaml:"projects"`
+	Editor     string   `yaml:"editor,omitempty"`
+}
+
+func NewWorkspace(name string, projectIDs ...string) Workspace {
+	w := Workspace{Name: name}
+	for _, id := range projectIDs {
+		w = w.WithProject(id)
+	}
+	return w
+}
+
+func (w Workspace) WithEditor(editor string) Workspace {
+	newW := w
+	newW.Editor = editor
+	return newW
+}
+
+func (w Workspace) Contains(projectID string) bool {
+	return slices.Contains(w.ProjectIDs, projectID)
+}
+
+func (w Workspace) WithProject(projectID string) Workspace {
+	newW := w
+	newW.ProjectIDs = slices.Clone(w.ProjectIDs)
+	if !newW.Contains(projectID) {
+		newW.ProjectIDs = append(newW.ProjectIDs, projectID)
+	}
+	return newW
+}
+
+func (w Workspace) WithoutProject(projectID string) Workspace {
+	newW := w
+	newW.ProjectIDs = slices.DeleteFunc(slices.Clone(w.ProjectIDs), func(id string) bool {
+		return id == projectID
+	})
+	return newW
+}
+
+func (w *Workspace) Validate() error {
+	return ValidateName(w.Name)
+}
@@ -0,0 +1,167 @@
+package catalog_test
+
+import (
+	"path/filepath"
+	"pj/internal/catalog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspace_WithProject(t *testing.T) {
+	t.Run("appends in order", func(t *testing.T) {
+		w := catalog.NewWorkspace("stack").WithProject("a").WithProject("b")
+
+		assert.Equal(t, []string{"a", "b"}, w.ProjectIDs)
+	})
+
+	t.Run("ignores duplicates", func(t *testing.T) {
+		w := catalog.NewWorkspace("stack", "a").WithProject("a")
+
+		assert.Equal(t, []string{"a"}, w.ProjectIDs)
+	})
+
+	t.Run("NewWorkspace drops repeated IDs", func(t *testing.T) {
+		w := catalog.NewWorkspace("stack", "a", "b", "a")
+
+		assert.Equal(t, []string{"a", "b"}, w.ProjectIDs)
+	})
+
+	t.Run("does not alias the original", func(t *testing.T) {
+		orig := catalog.NewWorkspace("stack", "a")
+		_ = orig.WithProject("b")
+
+		assert.Equal(t, []string{"a"}, orig.ProjectIDs)
+	})
+}
+
+func TestWorkspace_WithoutProject(t *testing.T) {
+	orig := catalog.NewWorkspace("stack", "a", "b", "c")
+
+	w := orig.WithoutProject("b")
+
+	assert.Equal(t, []string{"a", "c"}, w.ProjectIDs)
+	assert.Equal(t, []string{"a", "b", "c"}, orig.ProjectIDs)
+}
+
+func TestYAMLCatalog_AddWorkspace(t *testing.T) {
+	t.Run("adds workspace with known projects", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+		p := catalog.NewProject("api", newTestDir(t))
+		require.NoError(t, cat.Add(p))
+
+		err := cat.AddWorkspace(catalog.NewWorkspace("stack", p.ID))
+
+		require.NoError(t, err)
+		got, err := cat.GetWorkspace("stack")
+		require.NoError(t, err)
+		assert.Equal(t, []string{p.ID}, got.ProjectIDs)
+	})
+
+	t.Run("rejects empty name", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+
+		err := cat.AddWorkspace(catalog.NewWorkspace("  "))
+
+		assert.ErrorIs(t, err, catalog.ErrEmptyName)
+	})
+
+	t.Run("rejects duplicate name", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+		require.NoError(t, cat.AddWorkspace(catalog.NewWorkspace("stack")))
+
+		err := cat.AddWorkspace(catalog.NewWorkspace("stack"))
+
+		assert.ErrorIs(t, err, catalog.ErrWorkspaceExists)
+	})
+
+	t.Run("rejects unknown project", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+
+		err := cat.AddWorkspace(catalog.NewWorkspace("stack", "missing"))
+
+		assert.ErrorIs(t, err, catalog.ErrNotFound)
+	})
+}
+
+func TestYAMLCatalog_UpdateWorkspace(t *testing.T) {
+	t.Run("returns error when workspace not found", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+
+		err := cat.UpdateWorkspace(catalog.NewWorkspace("stack"))
+
+		assert.ErrorIs(t, err, catalog.ErrWorkspaceNotFound)
+	})
+
+	t.Run("replaces project list", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+		p1 := catalog.NewProject("api", newTestDir(t))
+		p2 := catalog.NewProject("web", newTestDir(t))
+		require.NoError(t, cat.Add(p1))
+		require.NoError(t, cat.Add(p2))
+		require.NoError(t, cat.AddWorkspace(catalog.NewWorkspace("stack", p1.ID)))
+
+		err := cat.UpdateWorkspace(catalog.NewWorkspace("stack", p2.ID, p1.ID))
+
+		require.NoError(t, err)
+		got, _ := cat.GetWorkspace("stack")
+		assert.Equal(t, []string{p2.ID, p1.ID}, got.ProjectIDs)
+	})
+}
+
+func TestYAMLCatalog_RemoveWorkspace(t *testing.T) {
+	t.Run("removes existing workspace", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+		require.NoError(t, cat.AddWorkspace(catalog.NewWorkspace("stack")))
+
+		err := cat.RemoveWorkspace("stack")
+
+		require.NoError(t, err)
+		assert.Empty(t, cat.ListWorkspaces())
+	})
+
+	t.Run("returns error when workspace not found", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+
+		err := cat.RemoveWorkspace("stack")
+
+		assert.ErrorIs(t, err, catalog.ErrWorkspaceNotFound)
+	})
+}
+
+func TestYAMLCatalog_RemoveProjectPrunesWorkspaces(t *testing.T) {
+	cat := newTestYAMLCatalog(t)
+	p1 := catalog.NewProject("api", newTestDir(t))
+	p2 := catalog.NewProject("web", newTestDir(t))
+	require.NoError(t, cat.Add(p1))
+	require.NoError(t, cat.Add(p2))
+	require.NoError(t, cat.AddWorkspace(catalog.NewWorkspace("stack", p1.ID, p2.ID)))
+
+	require.NoError(t, cat.Remove(p1.ID))
+
+	got, err := cat.GetWorkspace("stack")
+	require.NoError(t, err)
+	assert.Equal(t, []string{p2.ID}, got.ProjectIDs)
+}
+
+func TestYAMLCatalog_WorkspacePersistence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "catalog.yaml")
+
+	cat1, err := catalog.NewYAMLCatalog(path)
+	require.NoError(t, err)
+	p := catalog.NewProject("api", newTestDir(t))
+	require.NoError(t, cat1.Add(p))
+	require.NoError(t, cat1.AddWorkspace(catalog.NewWorkspace("stack", p.ID).WithEditor("code")))
+	require.NoError(t, cat1.Save())
+
+	cat2, err := catalog.NewYAMLCatalog(path)
+	require.NoError(t, err)
+	require.NoError(t, cat2.Load())
+
+	got, err := cat2.GetWorkspace("stack")
+	require.NoError(t, err)
+	assert.Equal(t, []string{p.ID}, got.ProjectIDs)
+	assert.Equal(t, "code", got.Editor)
+}
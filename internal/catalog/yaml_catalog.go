@@ -1,26 +1,35 @@
 package catalog
 
 import (
+	"context"
+	"crypto/sha256"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"pj/internal/config"
 	"slices"
 	"strings"
 	"sync"
-
-	"gopkg.in/yaml.v3"
+	"time"
 )
 
-type catalogFile struct {
-	Version  int       `yaml:"version"`
-	Projects []Project `yaml:"projects"`
-}
-
 type YAMLCatalog struct {
-	path     string
-	projects map[string]Project
-	byPath   map[string]string
-	mu       sync.RWMutex
+	path       string
+	projects   map[string]Project
+	byPath     map[string]string
+	workspaces map[string]Workspace
+	mapper     config.PathMapper
+	mu         sync.RWMutex
+
+	// fileSum is the checksum of the file as last read or written, so the
+	// watcher can ignore our own saves.
+	fileSum [sha256.Size]byte
+	// saved is the file's content as last read or written, and pending
+	// says there are changes since. A reload merges them with the file.
+	saved   Snapshot
+	pending bool
+	events  eventHub
 }
 
 func NewYAMLCatalog(path string) (*YAMLCatalog, error) {
@@ -29,14 +38,22 @@ func NewYAMLCatalog(path string) (*YAMLCatalog, error) {
 	}
 
 	return &YAMLCatalog{
-		path:     path,
-		projects: make(map[string]Project),
-		byPath:   make(map[string]string),
+		path:       path,
+		projects:   make(map[string]Project),
+		byPath:     make(map[string]string),
+		workspaces: make(map[string]Workspace),
 	}, nil
 }
 
+// WithPathMapper sets how portable paths such as "~/src" or "${code}/api"
+// are resolved on load and written back on save.
+func (c *YAMLCatalog) WithPathMapper(m config.PathMapper) *YAMLCatalog {
+	c.mapper = m
+	return c
+}
+
 func (c *YAMLCatalog) Add(p Project) error {
-	if err := p.ValidateAndNormalize(); err != nil {
+	if err := p.ValidateAndNormalizeWith(c.mapper); err != nil {
 		return err
 	}
 
@@ -49,6 +66,8 @@ func (c *YAMLCatalog) Add(p Project) error {
 
 	c.projects[p.ID] = p
 	c.byPath[p.Path] = p.ID
+	c.pending = true
+	c.events.publish(Event{Kind: EventAdded, New: p})
 	return nil
 }
 
@@ -75,10 +94,6 @@ func (c *YAMLCatalog) GetByPath(path string) (Project, error) {
 }
 
 func (c *YAMLCatalog) Update(p Project) error {
-	if err := p.ValidateAndNormalize(); err != nil {
-		return err
-	}
-
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -86,6 +101,9 @@ func (c *YAMLCatalog) Update(p Project) error {
 	if !ok {
 		return ErrNotFound
 	}
+	if err := p.validateUpdate(c.mapper, existing); err != nil {
+		return err
+	}
 
 	if existing.Path != p.Path {
 		if existingID, exists := c.byPath[p.Path]; exists && existingID != p.ID {
@@ -95,7 +113,12 @@ func (c *YAMLCatalog) Update(p Project) error {
 		c.byPath[p.Path] = p.ID
 	}
 
+	if now := time.Now(); p.stampChanges(existing, now) {
+		p.UpdatedAt = now
+	}
 	c.projects[p.ID] = p
+	c.pending = true
+	c.events.publish(Event{Kind: EventUpdated, Old: existing, New: p})
 	return nil
 }
 
@@ -110,6 +133,13 @@ func (c *YAMLCatalog) Remove(id string) error {
 
 	delete(c.projects, id)
 	delete(c.byPath, p.Path)
+	for name, w := range c.workspaces {
+		if w.Contains(id) {
+			c.workspaces[name] = w.WithoutProject(id)
+		}
+	}
+	c.pending = true
+	c.events.publish(Event{Kind: EventRemoved, Old: p})
 	return nil
 }
 
@@ -177,6 +207,15 @@ func matchesFilter(p Project, opts FilterOptions) bool {
 	if opts.Query != "" && !matchesQuery(p, strings.ToLower(opts.Query)) {
 		return false
 	}
+	if opts.Tag != "" && !p.HasTag(opts.Tag) {
+		return false
+	}
+	if opts.Language != "" && !p.HasLanguage(opts.Language) {
+		return false
+	}
+	if opts.ExcludeArchived && p.Archived() {
+		return false
+	}
 	return true
 }
 
@@ -223,59 +262,293 @@ func (c *YAMLCatalog) Count() int {
 }
 
 func (c *YAMLCatalog) Save() error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	file := catalogFile{
-		Version:  1,
-		Projects: make([]Project, 0, len(c.projects)),
+	return c.saveUnlocked()
+}
+
+func (c *YAMLCatalog) saveUnlocked() error {
+	snap := c.snapshotUnlocked()
+	data, err := writeFile(c.path, snap)
+	if err != nil {
+		return err
 	}
+	c.fileSum = sha256.Sum256(data)
+	c.saved, c.pending = snap, false
+	return nil
+}
 
+// snapshotUnlocked is the catalog as it would be written to the file.
+func (c *YAMLCatalog) snapshotUnlocked() Snapshot {
+	snap := Snapshot{
+		Projects:   make([]Project, 0, len(c.projects)),
+		Workspaces: c.listWorkspacesUnlocked(),
+	}
 	for _, p := range c.projects {
-		file.Projects = append(file.Projects, p)
+		p.Path = storedPath(c.mapper, p)
+		snap.Projects = append(snap.Projects, p)
 	}
+	return snap
+}
 
-	slices.SortStableFunc(file.Projects, func(a, b Project) int {
-		return strings.Compare(a.Name, b.Name)
-	})
+func (c *YAMLCatalog) Batch(fn func(tx Tx) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	data, err := yaml.Marshal(file)
-	if err != nil {
+	staged := &YAMLCatalog{
+		path:       c.path,
+		projects:   maps.Clone(c.projects),
+		byPath:     maps.Clone(c.byPath),
+		workspaces: maps.Clone(c.workspaces),
+		mapper:     c.mapper,
+	}
+	rec := &batchRecorder{}
+	if err := rec.finish(fn(&yamlTx{cat: staged, rec: rec})); err != nil {
 		return err
 	}
 
-	tmpPath := c.path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
-		return err
+	projects, byPath, workspaces := c.projects, c.byPath, c.workspaces
+	c.projects, c.byPath, c.workspaces = staged.projects, staged.byPath, staged.workspaces
+	if err := c.saveUnlocked(); err != nil {
+		c.projects, c.byPath, c.workspaces = projects, byPath, workspaces
+		return fmt.Errorf("failed to save catalog: %w", err)
+	}
+	c.events.publish(diffProjects(projects, c.projects)...)
+	return nil
+}
+
+// yamlTx applies a batch to a private copy of the catalog's state.
+type yamlTx struct {
+	cat *YAMLCatalog
+	rec *batchRecorder
+}
+
+func (t *yamlTx) Add(p Project) error {
+	return t.rec.record("add", projectLabel(p), t.cat.Add(p))
+}
+
+func (t *yamlTx) Update(p Project) error {
+	return t.rec.record("update", projectLabel(p), t.cat.Update(p))
+}
+
+func (t *yamlTx) Remove(id string) error {
+	return t.rec.record("remove", id, t.cat.Remove(id))
+}
+
+func (t *yamlTx) Get(id string) (Project, error) {
+	return t.cat.Get(id)
+}
+
+func (t *yamlTx) GetByPath(path string) (Project, error) {
+	return t.cat.GetByPath(path)
+}
+
+func (t *yamlTx) List() []Project {
+	return t.cat.List()
+}
+
+func (t *yamlTx) AddWorkspace(w Workspace) error {
+	return t.rec.record("add workspace", w.Name, t.cat.AddWorkspace(w))
+}
+
+func (t *yamlTx) GetWorkspace(name string) (Workspace, error) {
+	return t.cat.GetWorkspace(name)
+}
+
+func (t *yamlTx) UpdateWorkspace(w Workspace) error {
+	return t.rec.record("update workspace", w.Name, t.cat.UpdateWorkspace(w))
+}
+
+func (t *yamlTx) RemoveWorkspace(name string) error {
+	return t.rec.record("remove workspace", name, t.cat.RemoveWorkspace(name))
+}
+
+func (t *yamlTx) ListWorkspaces() []Workspace {
+	return t.cat.ListWorkspaces()
+}
+
+// storedPath keeps the portable form a project was loaded with as long as
+// it still resolves to the same place.
+func storedPath(m config.PathMapper, p Project) string {
+	if p.PortablePath != "" {
+		abs, err := m.Expand(p.PortablePath)
+		if (err == nil && abs == p.Path) || (err != nil && p.Path == p.PortablePath) {
+			return p.PortablePath
+		}
 	}
+	if m.Portable {
+		return m.Contract(p.Path)
+	}
+	return p.Path
+}
 
-	return os.Rename(tmpPath, c.path)
+// resolveStored turns a project as stored into its form on this host,
+// marking it unavailable when its path does not resolve.
+func resolveStored(m config.PathMapper, p Project) Project {
+	if err := p.normalizePath(m); isUnavailable(err) {
+		p.Unavailable = true
+	}
+	return p
 }
 
 func (c *YAMLCatalog) Load() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	data, err := os.ReadFile(c.path)
-	if os.IsNotExist(err) {
-		return nil
+	snap, data, err := readFile(c.path)
+	if err != nil {
+		return err
 	}
+
+	c.replaceUnlocked(snap, data)
+	return nil
+}
+
+// Watch reports every change to the catalog's projects until ctx is done:
+// mutations made through this catalog, and edits to the file by anything
+// else. Those are merged with any unsaved changes, which a later Save
+// writes out.
+func (c *YAMLCatalog) Watch(ctx context.Context) <-chan Event {
+	return c.events.subscribe(ctx, func() func() {
+		return startFileWatch(c.path, c.reloadIfChanged)
+	})
+}
+
+func (c *YAMLCatalog) reloadIfChanged() {
+	data, err := os.ReadFile(c.path)
 	if err != nil {
-		return fmt.Errorf("failed to read catalog file: %w", err)
+		return
 	}
 
-	var file catalogFile
-	if err := yaml.Unmarshal(data, &file); err != nil {
-		return fmt.Errorf("failed to parse catalog file %q: %w", c.path, err)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if sha256.Sum256(data) == c.fileSum {
+		return
+	}
+	// A file that does not parse is most likely still being written.
+	snap, err := decodeFile(c.path, data)
+	if err != nil {
+		return
+	}
+	if !c.pending {
+		c.replaceUnlocked(snap, data)
+		return
 	}
+	merged := Merge(c.saved, c.snapshotUnlocked(), snap).Snapshot
+	c.replaceUnlocked(merged, data)
+	c.saved, c.pending = snap, true
+}
+
+func (c *YAMLCatalog) replaceUnlocked(snap Snapshot, data []byte) {
+	previous := c.projects
 
-	c.projects = make(map[string]Project, len(file.Projects))
-	c.byPath = make(map[string]string, len(file.Projects))
+	c.projects = make(map[string]Project, len(snap.Projects))
+	c.byPath = make(map[string]string, len(snap.Projects))
+	c.workspaces = make(map[string]Workspace, len(snap.Workspaces))
 
-	for _, p := range file.Projects {
+	for _, p := range snap.Projects {
+		p = resolveStored(c.mapper, p)
 		c.projects[p.ID] = p
 		c.byPath[p.Path] = p.ID
 	}
+	for _, w := range snap.Workspaces {
+		c.workspaces[w.Name] = w
+	}
+
+	c.fileSum = sha256.Sum256(data)
+	c.saved, c.pending = snap, false
+	c.events.publish(diffProjects(previous, c.projects)...)
+}
+
+func (c *YAMLCatalog) AddWorkspace(w Workspace) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.workspaces[w.Name]; exists {
+		return ErrWorkspaceExists
+	}
+	if err := c.checkWorkspaceProjectsUnlocked(w); err != nil {
+		return err
+	}
+
+	c.workspaces[w.Name] = w
+	c.pending = true
+	return nil
+}
+
+func (c *YAMLCatalog) GetWorkspace(name string) (Workspace, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	w, ok := c.workspaces[name]
+	if !ok {
+		return Workspace{}, ErrWorkspaceNotFound
+	}
+	return w, nil
+}
+
+func (c *YAMLCatalog) UpdateWorkspace(w Workspace) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.workspaces[w.Name]; !ok {
+		return ErrWorkspaceNotFound
+	}
+	if err := c.checkWorkspaceProjectsUnlocked(w); err != nil {
+		return err
+	}
+
+	c.workspaces[w.Name] = w
+	c.pending = true
+	return nil
+}
+
+func (c *YAMLCatalog) RemoveWorkspace(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.workspaces[name]; !ok {
+		return ErrWorkspaceNotFound
+	}
+
+	delete(c.workspaces, name)
+	c.pending = true
+	return nil
+}
+
+func (c *YAMLCatalog) ListWorkspaces() []Workspace {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.listWorkspacesUnlocked()
+}
+
+func (c *YAMLCatalog) listWorkspacesUnlocked() []Workspace {
+	workspaces := make([]Workspace, 0, len(c.workspaces))
+	for _, w := range c.workspaces {
+		workspaces = append(workspaces, w)
+	}
+	slices.SortFunc(workspaces, func(a, b Workspace) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return workspaces
+}
 
+func (c *YAMLCatalog) checkWorkspaceProjectsUnlocked(w Workspace) error {
+	for _, id := range w.ProjectIDs {
+		if _, ok := c.projects[id]; !ok {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+	}
 	return nil
 }
@@ -2,9 +2,11 @@ package catalog_test
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"pj/internal/catalog"
+	"slices"
 	"sync"
 	"testing"
 
@@ -94,6 +96,25 @@ func TestYAMLCatalog_Update(t *testing.T) {
 		assert.ErrorIs(t, err, catalog.ErrNotFound)
 	})
 
+	t.Run("timestamps changed fields but not touches", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+		p := catalog.NewProject("myproject", newTestDir(t))
+		require.NoError(t, cat.Add(p))
+
+		require.NoError(t, cat.Update(p.WithDescription("REST backend")))
+		edited, err := cat.Get(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"description"}, slices.Collect(maps.Keys(edited.FieldUpdatedAt)))
+		assert.Equal(t, edited.UpdatedAt, edited.FieldUpdatedAt["description"])
+
+		edited.Touch()
+		require.NoError(t, cat.Update(edited))
+		touched, err := cat.Get(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, edited.UpdatedAt, touched.UpdatedAt)
+		assert.Equal(t, edited.FieldUpdatedAt, touched.FieldUpdatedAt)
+	})
+
 	t.Run("returns error when updating to existing path", func(t *testing.T) {
 		cat := newTestYAMLCatalog(t)
 		dir1 := newTestDir(t)
@@ -238,6 +259,31 @@ func TestYAMLCatalog_Filter(t *testing.T) {
 
 		assert.Len(t, results, 3)
 	})
+
+	t.Run("filters by detected language", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+		require.NoError(t, cat.Add(catalog.NewProject("api", newTestDir(t)).
+			WithStack(&catalog.Stack{Languages: []string{"go", "typescript"}})))
+		require.NoError(t, cat.Add(catalog.NewProject("web", newTestDir(t)).
+			WithStack(&catalog.Stack{Languages: []string{"typescript"}})))
+		require.NoError(t, cat.Add(catalog.NewProject("notes", newTestDir(t))))
+
+		assert.Len(t, cat.Filter(catalog.FilterOptions{Language: "Go"}), 1)
+		assert.Len(t, cat.Filter(catalog.FilterOptions{Language: "typescript"}), 2)
+	})
+
+	t.Run("excludes archived projects on request", func(t *testing.T) {
+		cat := newTestYAMLCatalog(t)
+		require.NoError(t, cat.Add(catalog.NewProject("live", newTestDir(t))))
+		require.NoError(t, cat.Add(catalog.NewProject("resting", newTestDir(t)).WithStatus(catalog.StatusPaused)))
+		require.NoError(t, cat.Add(catalog.NewProject("dead", newTestDir(t)).WithStatus(catalog.StatusArchived)))
+
+		assert.Len(t, cat.Filter(catalog.FilterOptions{}), 3)
+		results := cat.Filter(catalog.FilterOptions{ExcludeArchived: true})
+		require.Len(t, results, 2)
+		assert.Equal(t, "live", results[0].Name)
+		assert.Equal(t, "resting", results[1].Name)
+	})
 }
 
 func TestYAMLCatalog_Persistence(t *testing.T) {
@@ -264,6 +310,24 @@ func TestYAMLCatalog_Persistence(t *testing.T) {
 		assert.Equal(t, p.Path, got.Path)
 	})
 
+	t.Run("save and load preserves the detected stack", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "catalog.yaml")
+		stack := &catalog.Stack{Languages: []string{"go"}, Frameworks: []string{"gin"}, PackageManagers: []string{"go"}}
+		cat1, err := catalog.NewYAMLCatalog(path)
+		require.NoError(t, err)
+		p := catalog.NewProject("api", newTestDir(t)).WithStack(stack)
+		require.NoError(t, cat1.Add(p))
+		require.NoError(t, cat1.Save())
+
+		cat2, err := catalog.NewYAMLCatalog(path)
+		require.NoError(t, err)
+		require.NoError(t, cat2.Load())
+
+		got, err := cat2.Get(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, stack, got.Stack)
+	})
+
 	t.Run("load creates empty catalog if file doesn't exist", func(t *testing.T) {
 		dir := t.TempDir()
 		path := filepath.Join(dir, "nonexistent.yaml")
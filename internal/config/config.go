@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the per-user configuration file. Unlike the catalog it is not
+// meant to be shared between machines, though host sections allow it.
+type Config struct {
+	Catalog CatalogConfig `yaml:"catalog,omitempty"`
+	// PortablePaths makes the catalog store paths relative to a root or
+	// $HOME whenever possible.
+	PortablePaths bool                  `yaml:"portable_paths,omitempty"`
+	Roots         map[string]string     `yaml:"roots,omitempty"`
+	Hosts         map[string]HostConfig `yaml:"hosts,omitempty"`
+	Editors       EditorConfig          `yaml:"editors,omitempty"`
+	// CloneRoot is where `pj clone` lays out repositories as
+	// <root>/<host>/<owner>/<repo>. It defaults to DefaultProjectsDir.
+	CloneRoot string `yaml:"clone_root,omitempty"`
+	// WorktreeRoot is where `pj wt add` creates worktrees, as
+	// <root>/<project>/<branch>. By default they go next to the project in
+	// <project>.worktrees/<branch>.
+	WorktreeRoot string `yaml:"worktree_root,omitempty"`
+	// ArchiveRoot is where `pj archive --compress` puts tarballs. By default
+	// they go in an archive directory next to the catalog.
+	ArchiveRoot string `yaml:"archive_root,omitempty"`
+	// Forges maps self-hosted git hosts to their web URL layout.
+	Forges map[string]ForgeConfig `yaml:"forges,omitempty"`
+	// Monorepos adds globs, relative to the project, whose directories are
+	// sub-projects of the named project. They extend what go.work and
+	// workspace files declare.
+	Monorepos map[string][]string `yaml:"monorepos,omitempty"`
+}
+
+type CatalogConfig struct {
+	// Backend is the storage used when the catalog path has no recognized
+	// extension: "yaml" (the default) or "sqlite".
+	Backend string `yaml:"backend,omitempty"`
+}
+
+type HostConfig struct {
+	Roots map[string]string `yaml:"roots,omitempty"`
+}
+
+// DefaultConfigPath returns the path to the config file.
+// It uses XDG_CONFIG_HOME if set, otherwise falls back to ~/.config.
+func DefaultConfigPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, _ := os.UserHomeDir()
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "pj", "config.yaml")
+}
+
+// Load reads the config file at path. A missing file yields the zero Config.
+func Load(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+	if err := cfg.Editors.validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config file %q: %w", path, err)
+	}
+	if err := validateForges(cfg.Forges); err != nil {
+		return cfg, fmt.Errorf("invalid config file %q: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// CloneDir returns the expanded clone root.
+func (c Config) CloneDir() (string, error) {
+	if c.CloneRoot == "" {
+		return DefaultProjectsDir(), nil
+	}
+	dir, err := ExpandPath(c.CloneRoot)
+	if err != nil {
+		return "", fmt.Errorf("clone_root: %w", err)
+	}
+	return dir, nil
+}
+
+// WorktreeDir returns where the worktree for branch of the named project
+// at projectPath belongs.
+func (c Config) WorktreeDir(name, projectPath, branch string) (string, error) {
+	dir := strings.ReplaceAll(branch, "/", "-")
+	if c.WorktreeRoot == "" {
+		return filepath.Join(projectPath+".worktrees", dir), nil
+	}
+	root, err := ExpandPath(c.WorktreeRoot)
+	if err != nil {
+		return "", fmt.Errorf("worktree_root: %w", err)
+	}
+	return filepath.Join(root, name, dir), nil
+}
+
+// ArchiveDir returns the expanded archive root, falling back to an archive
+// directory next to the catalog at catalogPath.
+func (c Config) ArchiveDir(catalogPath string) (string, error) {
+	if c.ArchiveRoot == "" {
+		return filepath.Join(filepath.Dir(catalogPath), "archive"), nil
+	}
+	dir, err := ExpandPath(c.ArchiveRoot)
+	if err != nil {
+		return "", fmt.Errorf("archive_root: %w", err)
+	}
+	return dir, nil
+}
+
+// PathMapper returns the mapper for hostname, with that host's roots
+// overriding the shared ones.
+func (c Config) PathMapper(hostname string) (PathMapper, error) {
+	roots := make(map[string]string, len(c.Roots))
+	maps.Copy(roots, c.Roots)
+	if host, ok := c.Hosts[hostname]; ok {
+		maps.Copy(roots, host.Roots)
+	}
+
+	for name, dir := range roots {
+		expanded, err := ExpandPath(dir)
+		if err != nil {
+			return PathMapper{}, fmt.Errorf("root %q: %w", name, err)
+		}
+		roots[name] = expanded
+	}
+
+	return PathMapper{Roots: roots, Portable: c.PortablePaths}, nil
+}
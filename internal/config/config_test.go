@@ -0,0 +1,129 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultConfigPath(t *testing.T) {
+	t.Run("respects XDG_CONFIG_HOME when set", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "/custom/config")
+
+		assert.Equal(t, "/custom/config/pj/config.yaml", config.DefaultConfigPath())
+	})
+
+	t.Run("falls back to ~/.config", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+		home, err := os.UserHomeDir()
+		require.NoError(t, err)
+
+		assert.Equal(t, filepath.Join(home, ".config", "pj", "config.yaml"), config.DefaultConfigPath())
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("missing file yields zero config", func(t *testing.T) {
+		cfg, err := config.Load(filepath.Join(t.TempDir(), "config.yaml"))
+
+		require.NoError(t, err)
+		assert.Equal(t, config.Config{}, cfg)
+	})
+
+	t.Run("parses roots and hosts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := `portable_paths: true
+roots:
+  code: /srv/code
+hosts:
+  laptop:
+    roots:
+      code: /Users/alice/code
+`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		cfg, err := config.Load(path)
+
+		require.NoError(t, err)
+		assert.True(t, cfg.PortablePaths)
+		assert.Equal(t, "/srv/code", cfg.Roots["code"])
+		assert.Equal(t, "/Users/alice/code", cfg.Hosts["laptop"].Roots["code"])
+	})
+
+	t.Run("returns error for malformed YAML", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("roots: [unclosed"), 0o644))
+
+		_, err := config.Load(path)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse config file")
+	})
+}
+
+func TestConfig_PathMapper(t *testing.T) {
+	cfg := config.Config{
+		PortablePaths: true,
+		Roots:         map[string]string{"code": "/srv/code", "notes": "/srv/notes"},
+		Hosts: map[string]config.HostConfig{
+			"laptop": {Roots: map[string]string{"code": "/Users/alice/code"}},
+		},
+	}
+
+	t.Run("host roots override shared roots", func(t *testing.T) {
+		m, err := cfg.PathMapper("laptop")
+
+		require.NoError(t, err)
+		assert.Equal(t, "/Users/alice/code", m.Roots["code"])
+		assert.Equal(t, "/srv/notes", m.Roots["notes"])
+		assert.True(t, m.Portable)
+	})
+
+	t.Run("other hosts use shared roots", func(t *testing.T) {
+		m, err := cfg.PathMapper("workstation")
+
+		require.NoError(t, err)
+		assert.Equal(t, "/srv/code", m.Roots["code"])
+	})
+
+	t.Run("expands tilde in root definitions", func(t *testing.T) {
+		home, err := os.UserHomeDir()
+		require.NoError(t, err)
+		cfg := config.Config{Roots: map[string]string{"code": "~/code"}}
+
+		m, err := cfg.PathMapper("any")
+
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(home, "code"), m.Roots["code"])
+	})
+}
+
+func TestLoad_Forges(t *testing.T) {
+	t.Run("parses forge layouts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := `forges:
+  git.corp.example:
+    type: gitlab
+    base_url: https://git.corp.example/gitlab
+`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		cfg, err := config.Load(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, config.ForgeConfig{Type: "gitlab", BaseURL: "https://git.corp.example/gitlab"}, cfg.Forges["git.corp.example"])
+	})
+
+	t.Run("rejects unknown forge types", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("forges:\n  git.corp.example:\n    type: sourcehut\n"), 0o644))
+
+		_, err := config.Load(path)
+
+		assert.ErrorContains(t, err, `unknown type "sourcehut"`)
+	})
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// EditorConfig chooses the editor for projects that do not set their own.
+// Editors in rules, Default and `pj open --with` are either profile names or
+// commands.
+type EditorConfig struct {
+	Default  string            `yaml:"default,omitempty"`
+	Profiles map[string]string `yaml:"profiles,omitempty"`
+	Rules    []EditorRule      `yaml:"rules,omitempty"`
+}
+
+// EditorRule matches when every condition it sets holds; a rule without
+// conditions matches every project. The first matching rule wins.
+type EditorRule struct {
+	Language string `yaml:"language,omitempty"`
+	Tag      string `yaml:"tag,omitempty"`
+	// Path is a glob matched against the project path and its parents, so
+	// "~/notebooks" covers every project below it.
+	Path   string `yaml:"path,omitempty"`
+	Editor string `yaml:"editor"`
+}
+
+// Command returns the command for a profile name, or editor itself when it
+// names no profile.
+func (e EditorConfig) Command(editor string) string {
+	if cmd, ok := e.Profiles[editor]; ok {
+		return cmd
+	}
+	return editor
+}
+
+// Match returns the index of the first rule matching a project with the
+// given languages, tags and path.
+func (e EditorConfig) Match(languages, tags []string, path string) (int, bool) {
+	for i, r := range e.Rules {
+		if r.matches(languages, tags, path) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func (r EditorRule) matches(languages, tags []string, path string) bool {
+	if r.Language != "" && !slices.ContainsFunc(languages, func(l string) bool {
+		return strings.EqualFold(l, r.Language)
+	}) {
+		return false
+	}
+	if r.Tag != "" && !slices.Contains(tags, r.Tag) {
+		return false
+	}
+	if r.Path != "" && !matchPathOrParent(r.Path, path) {
+		return false
+	}
+	return true
+}
+
+// String describes the rule's conditions, e.g. "language go, tag work".
+func (r EditorRule) String() string {
+	var conds []string
+	if r.Language != "" {
+		conds = append(conds, "language "+r.Language)
+	}
+	if r.Tag != "" {
+		conds = append(conds, "tag "+r.Tag)
+	}
+	if r.Path != "" {
+		conds = append(conds, "path "+r.Path)
+	}
+	if len(conds) == 0 {
+		return "any project"
+	}
+	return strings.Join(conds, ", ")
+}
+
+func (e EditorConfig) validate() error {
+	for i, r := range e.Rules {
+		if strings.TrimSpace(r.Editor) == "" {
+			return fmt.Errorf("editors.rules[%d]: editor is required", i)
+		}
+		if r.Path != "" {
+			pattern, err := ExpandPath(r.Path)
+			if err != nil {
+				return fmt.Errorf("editors.rules[%d]: %w", i, err)
+			}
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("editors.rules[%d]: invalid path pattern %q", i, r.Path)
+			}
+		}
+	}
+	return nil
+}
+
+func matchPathOrParent(pattern, path string) bool {
+	pattern, err := ExpandPath(pattern)
+	if err != nil {
+		return false
+	}
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if ok, _ := filepath.Match(pattern, dir); ok {
+			return true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return false
+		}
+	}
+}
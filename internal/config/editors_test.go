@@ -0,0 +1,89 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Editors(t *testing.T) {
+	t.Run("parses profiles and rules", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := `editors:
+  default: nvim
+  profiles:
+    goland: goland --wait
+  rules:
+    - language: go
+      editor: goland
+    - path: ~/notebooks
+      editor: jupyter lab
+`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		cfg, err := config.Load(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, "nvim", cfg.Editors.Default)
+		assert.Equal(t, "goland --wait", cfg.Editors.Command("goland"))
+		assert.Equal(t, []config.EditorRule{
+			{Language: "go", Editor: "goland"},
+			{Path: "~/notebooks", Editor: "jupyter lab"},
+		}, cfg.Editors.Rules)
+	})
+
+	t.Run("rejects a rule without an editor", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("editors:\n  rules:\n    - tag: work\n"), 0o644))
+
+		_, err := config.Load(path)
+
+		assert.ErrorContains(t, err, "editors.rules[0]: editor is required")
+	})
+
+	t.Run("rejects a malformed path pattern", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("editors:\n  rules:\n    - path: /srv/[\n      editor: vim\n"), 0o644))
+
+		_, err := config.Load(path)
+
+		assert.ErrorContains(t, err, "invalid path pattern")
+	})
+}
+
+func TestEditorConfig_Match(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	cfg := config.EditorConfig{Rules: []config.EditorRule{
+		{Language: "Go", Tag: "work", Editor: "goland"},
+		{Path: "~/code/*/notebooks", Editor: "jupyter"},
+		{Editor: "nvim"},
+	}}
+
+	tests := []struct {
+		name      string
+		languages []string
+		tags      []string
+		path      string
+		want      int
+	}{
+		{"every condition must hold", []string{"go"}, []string{"work"}, "/srv/api", 0},
+		{"language alone is not enough", []string{"go"}, nil, "/srv/api", 2},
+		{"glob matches a parent", nil, nil, filepath.Join(home, "code", "ml", "notebooks", "mnist"), 1},
+		{"rule without conditions matches anything", nil, nil, "/tmp/x", 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := cfg.Match(tc.languages, tc.tags, tc.path)
+			require.True(t, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	_, ok := config.EditorConfig{}.Match([]string{"go"}, nil, "/srv/api")
+	assert.False(t, ok)
+}
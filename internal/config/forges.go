@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ForgeTypes are the hosting services whose URL layouts are built in.
+var ForgeTypes = []string{"github", "gitlab", "gitea", "bitbucket"}
+
+// ForgeConfig describes how `pj browse` builds web URLs for one host. Type
+// selects a built-in layout; any template set here overrides that layout's
+// template of the same name.
+//
+// Templates may use {base}, {repo} (the full owner/name path), {owner},
+// {name}, {branch}, {file}, {line} and {number}.
+type ForgeConfig struct {
+	Type string `yaml:"type,omitempty"`
+	// BaseURL replaces https://<host>, e.g. for instances served on
+	// another port or under a path prefix.
+	BaseURL     string `yaml:"base_url,omitempty"`
+	Repo        string `yaml:"repo,omitempty"`
+	Branch      string `yaml:"branch,omitempty"`
+	File        string `yaml:"file,omitempty"`
+	Line        string `yaml:"line,omitempty"`
+	PullRequest string `yaml:"pull_request,omitempty"`
+	Issue       string `yaml:"issue,omitempty"`
+}
+
+func validateForges(forges map[string]ForgeConfig) error {
+	for host, f := range forges {
+		if f.Type != "" && !slices.Contains(ForgeTypes, f.Type) {
+			return fmt.Errorf("forges.%s: unknown type %q (want one of %v)", host, f.Type, ForgeTypes)
+		}
+	}
+	return nil
+}
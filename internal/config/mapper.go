@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrUnknownRoot = errors.New("unknown path root")
+
+// PathMapper translates between absolute paths and their portable forms:
+// "~/src/api", "$HOME/src/api" or "${code}/api" for a root named code.
+type PathMapper struct {
+	// Home overrides the user's home directory; empty means os.UserHomeDir.
+	Home     string
+	Roots    map[string]string
+	Portable bool
+}
+
+func (m PathMapper) home() (string, error) {
+	if m.Home != "" {
+		return m.Home, nil
+	}
+	return os.UserHomeDir()
+}
+
+func IsPortablePath(path string) bool {
+	return path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "$")
+}
+
+// Expand resolves a portable path to an absolute one. Paths that are not
+// portable are returned unchanged.
+func (m PathMapper) Expand(path string) (string, error) {
+	if !IsPortablePath(path) {
+		return path, nil
+	}
+
+	name, rest := splitPortable(path)
+	var base string
+	if name == "HOME" {
+		home, err := m.home()
+		if err != nil {
+			return "", fmt.Errorf("cannot expand %s: %w", path, err)
+		}
+		base = home
+	} else {
+		root, ok := m.Roots[name]
+		if !ok {
+			return "", fmt.Errorf("%w: ${%s}", ErrUnknownRoot, name)
+		}
+		base = root
+	}
+
+	if rest == "" {
+		return filepath.Clean(base), nil
+	}
+	return filepath.Join(base, rest), nil
+}
+
+func splitPortable(path string) (name, rest string) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return "HOME", strings.TrimPrefix(path[1:], "/")
+	}
+
+	head := path[1:]
+	if strings.HasPrefix(head, "{") {
+		if end := strings.Index(head, "}"); end > 0 {
+			return head[1:end], strings.TrimPrefix(head[end+1:], "/")
+		}
+	}
+	name, rest, _ = strings.Cut(head, "/")
+	return name, rest
+}
+
+// Contract returns the portable form of an absolute path, preferring the
+// most specific root and falling back to "~".
+func (m PathMapper) Contract(path string) string {
+	bestName, bestRoot := "", ""
+	for name, root := range m.Roots {
+		if !isWithin(path, root) {
+			continue
+		}
+		if len(root) > len(bestRoot) || (len(root) == len(bestRoot) && name < bestName) {
+			bestName, bestRoot = name, root
+		}
+	}
+	if bestName != "" {
+		return joinPortable("${"+bestName+"}", path, bestRoot)
+	}
+
+	if home, err := m.home(); err == nil && isWithin(path, home) {
+		return joinPortable("~", path, home)
+	}
+	return path
+}
+
+func isWithin(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+func joinPortable(prefix, path, dir string) string {
+	rel, _ := filepath.Rel(dir, path)
+	if rel == "." {
+		return prefix
+	}
+	return prefix + "/" + filepath.ToSlash(rel)
+}
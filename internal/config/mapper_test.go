@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"pj/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathMapper_Expand(t *testing.T) {
+	m := config.PathMapper{
+		Home:  "/home/alice",
+		Roots: map[string]string{"code": "/mnt/src"},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"tilde alone", "~", "/home/alice"},
+		{"tilde path", "~/dotfiles", "/home/alice/dotfiles"},
+		{"HOME variable", "$HOME/dotfiles", "/home/alice/dotfiles"},
+		{"braced HOME variable", "${HOME}/dotfiles", "/home/alice/dotfiles"},
+		{"named root", "${code}/service-a", "/mnt/src/service-a"},
+		{"named root alone", "${code}", "/mnt/src"},
+		{"unbraced named root", "$code/service-a", "/mnt/src/service-a"},
+		{"absolute path unchanged", "/opt/tool", "/opt/tool"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := m.Expand(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+
+	t.Run("unknown root returns ErrUnknownRoot", func(t *testing.T) {
+		_, err := m.Expand("${work}/api")
+		assert.ErrorIs(t, err, config.ErrUnknownRoot)
+	})
+}
+
+func TestPathMapper_Contract(t *testing.T) {
+	m := config.PathMapper{
+		Home: "/home/alice",
+		Roots: map[string]string{
+			"code": "/home/alice/code",
+			"work": "/home/alice/code/work",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"most specific root wins", "/home/alice/code/work/api", "${work}/api"},
+		{"root itself", "/home/alice/code", "${code}"},
+		{"falls back to home", "/home/alice/dotfiles", "~/dotfiles"},
+		{"sibling prefix is not a match", "/home/alice/codex", "~/codex"},
+		{"outside home stays absolute", "/opt/tool", "/opt/tool"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, m.Contract(tc.input))
+		})
+	}
+}
+
+func TestPathMapper_RoundTrip(t *testing.T) {
+	m := config.PathMapper{Home: "/home/alice", Roots: map[string]string{"code": "/srv/code"}}
+
+	for _, abs := range []string{"/srv/code/a/b", "/home/alice/x", "/etc"} {
+		got, err := m.Expand(m.Contract(abs))
+		require.NoError(t, err)
+		assert.Equal(t, abs, got)
+	}
+}
@@ -20,6 +20,18 @@ func DefaultCatalogPath() string {
 	return filepath.Join(dataHome, "pj", "catalog.yaml")
 }
 
+// DefaultStateDir returns the directory for pj's runtime state, such as
+// shell session history. It uses XDG_STATE_HOME if set, otherwise falls back
+// to ~/.local/state.
+func DefaultStateDir() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		home, _ := os.UserHomeDir()
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateHome, "pj")
+}
+
 func DefaultProjectsDir() string {
 	home, _ := os.UserHomeDir()
 	projectsDir := filepath.Join(home, "projects")
@@ -77,6 +77,22 @@ func TestDefaultCatalogPath(t *testing.T) {
 	})
 }
 
+func TestDefaultStateDir(t *testing.T) {
+	t.Run("respects XDG_STATE_HOME when set", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/custom/state")
+
+		assert.Equal(t, "/custom/state/pj", config.DefaultStateDir())
+	})
+
+	t.Run("falls back to ~/.local/state", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("XDG_STATE_HOME", "")
+
+		assert.Equal(t, filepath.Join(home, ".local", "state", "pj"), config.DefaultStateDir())
+	})
+}
+
 func expandTilde(path, home string) string {
 	if len(path) >= 2 && path[:2] == "~/" {
 		return filepath.Join(home, path[2:])
@@ -0,0 +1,186 @@
+// Package detect guesses a project's languages, frameworks and package
+// managers from the marker files in its root directory.
+package detect
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"slices"
+	"strings"
+)
+
+// maxManifestSize caps how much of a manifest is read for framework hints.
+const maxManifestSize = 1 << 20
+
+// marker is a file whose presence in the root implies a language and a
+// package manager. Patterns are matched with filepath.Match.
+type marker struct {
+	pattern  string
+	language string
+	manager  string
+}
+
+var markers = []marker{
+	{"go.mod", "go", "go"},
+	{"Cargo.toml", "rust", "cargo"},
+	{"package.json", "javascript", "npm"},
+	{"deno.json", "typescript", "deno"},
+	{"deno.jsonc", "typescript", "deno"},
+	{"pyproject.toml", "python", "pip"},
+	{"setup.py", "python", "pip"},
+	{"requirements.txt", "python", "pip"},
+	{"Pipfile", "python", "pipenv"},
+	{"pom.xml", "java", "maven"},
+	{"build.gradle", "java", "gradle"},
+	{"build.gradle.kts", "kotlin", "gradle"},
+	{"Gemfile", "ruby", "bundler"},
+	{"composer.json", "php", "composer"},
+	{"mix.exs", "elixir", "mix"},
+	{"Package.swift", "swift", "swiftpm"},
+	{"pubspec.yaml", "dart", "pub"},
+	{"build.zig", "zig", ""},
+	{"stack.yaml", "haskell", "stack"},
+	{"*.cabal", "haskell", "cabal"},
+	{"deps.edn", "clojure", "clojure"},
+	{"project.clj", "clojure", "leiningen"},
+	{"*.csproj", "c#", "nuget"},
+	{"*.fsproj", "f#", "nuget"},
+	{"CMakeLists.txt", "c++", "cmake"},
+	{"flake.nix", "nix", "nix"},
+	{"default.nix", "nix", "nix"},
+}
+
+// lockfile replaces a language's default package manager with the one
+// whose lock file is present.
+type lockfile struct {
+	file     string
+	replaces string
+	manager  string
+}
+
+var lockfiles = []lockfile{
+	{"yarn.lock", "npm", "yarn"},
+	{"pnpm-lock.yaml", "npm", "pnpm"},
+	{"bun.lockb", "npm", "bun"},
+	{"bun.lock", "npm", "bun"},
+	{"poetry.lock", "pip", "poetry"},
+	{"uv.lock", "pip", "uv"},
+	{"pdm.lock", "pip", "pdm"},
+}
+
+// hint names a framework when a manifest mentions one of its packages.
+type hint struct {
+	file      string
+	needle    string
+	framework string
+}
+
+var hints = []hint{
+	{"package.json", `"next"`, "next.js"},
+	{"package.json", `"react"`, "react"},
+	{"package.json", `"vue"`, "vue"},
+	{"package.json", `"svelte"`, "svelte"},
+	{"package.json", `"@angular/core"`, "angular"},
+	{"package.json", `"express"`, "express"},
+	{"package.json", `"astro"`, "astro"},
+	{"go.mod", "github.com/gin-gonic/gin", "gin"},
+	{"go.mod", "github.com/labstack/echo", "echo"},
+	{"go.mod", "github.com/gofiber/fiber", "fiber"},
+	{"go.mod", "github.com/charmbracelet/bubbletea", "bubbletea"},
+	{"Cargo.toml", "actix-web", "actix"},
+	{"Cargo.toml", "axum", "axum"},
+	{"Cargo.toml", "rocket", "rocket"},
+	{"Cargo.toml", "tauri", "tauri"},
+	{"pyproject.toml", "django", "django"},
+	{"pyproject.toml", "flask", "flask"},
+	{"pyproject.toml", "fastapi", "fastapi"},
+	{"requirements.txt", "django", "django"},
+	{"requirements.txt", "flask", "flask"},
+	{"requirements.txt", "fastapi", "fastapi"},
+	{"Gemfile", "rails", "rails"},
+	{"Gemfile", "sinatra", "sinatra"},
+	{"pom.xml", "spring-boot", "spring"},
+	{"build.gradle", "spring-boot", "spring"},
+	{"build.gradle.kts", "spring-boot", "spring"},
+	{"composer.json", "laravel/framework", "laravel"},
+	{"mix.exs", ":phoenix", "phoenix"},
+	{"pubspec.yaml", "flutter", "flutter"},
+}
+
+// Dir inspects the root of dir. It returns nil when nothing is recognized
+// or dir cannot be read.
+func Dir(dir string) *catalog.Stack {
+	var stack catalog.Stack
+	for _, m := range markers {
+		if !exists(dir, m.pattern) {
+			continue
+		}
+		stack.Languages = appendNew(stack.Languages, m.language)
+		if m.manager != "" {
+			stack.PackageManagers = appendNew(stack.PackageManagers, m.manager)
+		}
+	}
+
+	if slices.Contains(stack.Languages, "javascript") && exists(dir, "tsconfig.json") {
+		stack.Languages = replace(stack.Languages, "javascript", "typescript")
+	}
+	for _, l := range lockfiles {
+		if slices.Contains(stack.PackageManagers, l.replaces) && exists(dir, l.file) {
+			stack.PackageManagers = replace(stack.PackageManagers, l.replaces, l.manager)
+		}
+	}
+
+	manifests := map[string][]byte{}
+	for _, h := range hints {
+		data, ok := manifests[h.file]
+		if !ok {
+			data = readManifest(filepath.Join(dir, h.file))
+			manifests[h.file] = data
+		}
+		if bytes.Contains(bytes.ToLower(data), []byte(h.needle)) {
+			stack.Frameworks = appendNew(stack.Frameworks, h.framework)
+		}
+	}
+
+	if len(stack.Languages) == 0 && len(stack.Frameworks) == 0 {
+		return nil
+	}
+	return &stack
+}
+
+func exists(dir, pattern string) bool {
+	if !strings.Contains(pattern, "*") {
+		_, err := os.Stat(filepath.Join(dir, pattern))
+		return err == nil
+	}
+	entries, _ := os.ReadDir(dir)
+	return slices.ContainsFunc(entries, func(e os.DirEntry) bool {
+		ok, _ := filepath.Match(pattern, e.Name())
+		return ok
+	})
+}
+
+func readManifest(path string) []byte {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	data, _ := io.ReadAll(io.LimitReader(f, maxManifestSize))
+	return data
+}
+
+func appendNew(list []string, v string) []string {
+	if slices.Contains(list, v) {
+		return list
+	}
+	return append(list, v)
+}
+
+func replace(list []string, old, v string) []string {
+	list = slices.DeleteFunc(slices.Clone(list), func(s string) bool { return s == old })
+	return appendNew(list, v)
+}
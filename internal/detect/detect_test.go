@@ -0,0 +1,107 @@
+package detect_test
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/detect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestDir(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  *catalog.Stack
+	}{
+		{
+			name:  "go module with framework",
+			files: map[string]string{"go.mod": "module x\n\nrequire github.com/gin-gonic/gin v1.9.0\n"},
+			want:  &catalog.Stack{Languages: []string{"go"}, Frameworks: []string{"gin"}, PackageManagers: []string{"go"}},
+		},
+		{
+			name: "typescript with pnpm",
+			files: map[string]string{
+				"package.json":   `{"dependencies": {"next": "14", "react": "18"}}`,
+				"tsconfig.json":  "{}",
+				"pnpm-lock.yaml": "",
+			},
+			want: &catalog.Stack{
+				Languages:       []string{"typescript"},
+				Frameworks:      []string{"next.js", "react"},
+				PackageManagers: []string{"pnpm"},
+			},
+		},
+		{
+			name:  "plain javascript with npm",
+			files: map[string]string{"package.json": `{"name": "x"}`},
+			want:  &catalog.Stack{Languages: []string{"javascript"}, PackageManagers: []string{"npm"}},
+		},
+		{
+			name:  "python with poetry",
+			files: map[string]string{"pyproject.toml": "[tool.poetry.dependencies]\nDjango = \"^5\"\n", "poetry.lock": ""},
+			want:  &catalog.Stack{Languages: []string{"python"}, Frameworks: []string{"django"}, PackageManagers: []string{"poetry"}},
+		},
+		{
+			name:  "polyglot repository",
+			files: map[string]string{"Cargo.toml": "[dependencies]\naxum = \"0.7\"\n", "flake.nix": "{}"},
+			want: &catalog.Stack{
+				Languages:       []string{"rust", "nix"},
+				Frameworks:      []string{"axum"},
+				PackageManagers: []string{"cargo", "nix"},
+			},
+		},
+		{
+			name:  "glob markers",
+			files: map[string]string{"App.csproj": "<Project/>"},
+			want:  &catalog.Stack{Languages: []string{"c#"}, PackageManagers: []string{"nuget"}},
+		},
+		{
+			name:  "ruby on rails",
+			files: map[string]string{"Gemfile": "gem 'rails', '~> 7.1'\n"},
+			want:  &catalog.Stack{Languages: []string{"ruby"}, Frameworks: []string{"rails"}, PackageManagers: []string{"bundler"}},
+		},
+		{
+			name:  "maven",
+			files: map[string]string{"pom.xml": "<artifactId>spring-boot-starter</artifactId>"},
+			want:  &catalog.Stack{Languages: []string{"java"}, Frameworks: []string{"spring"}, PackageManagers: []string{"maven"}},
+		},
+		{
+			name:  "nothing recognized",
+			files: map[string]string{"notes.txt": "hello"},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detect.Dir(writeFiles(t, tt.files)))
+		})
+	}
+
+	t.Run("missing directory", func(t *testing.T) {
+		assert.Nil(t, detect.Dir(filepath.Join(t.TempDir(), "missing")))
+	})
+
+	t.Run("directory name with glob characters", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "[x]*")
+		require.NoError(t, os.Mkdir(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "x.cabal"), nil, 0o644))
+
+		got := detect.Dir(dir)
+
+		require.NotNil(t, got)
+		assert.Equal(t, []string{"haskell"}, got.Languages)
+	})
+}
@@ -0,0 +1,168 @@
+package detect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Members returns the directories of the packages in the monorepo rooted
+// at dir, as declared by go.work, pnpm-workspace.yaml, package.json
+// workspaces or a Cargo workspace, plus those matching extra globs
+// relative to dir. The root itself is never a member.
+func Members(dir string, extra []string) []string {
+	var patterns, excludes []string
+	add := func(list []string) {
+		for _, p := range list {
+			if rest, ok := strings.CutPrefix(p, "!"); ok {
+				excludes = append(excludes, rest)
+			} else {
+				patterns = append(patterns, p)
+			}
+		}
+	}
+	add(goWorkUses(filepath.Join(dir, "go.work")))
+	add(pnpmPackages(filepath.Join(dir, "pnpm-workspace.yaml")))
+	add(npmWorkspaces(filepath.Join(dir, "package.json")))
+	members, exclude := cargoMembers(filepath.Join(dir, "Cargo.toml"))
+	add(members)
+	excludes = append(excludes, exclude...)
+	add(extra)
+
+	excluded := expand(dir, excludes)
+	var dirs []string
+	for _, d := range expand(dir, patterns) {
+		if d != dir && !slices.Contains(dirs, d) && !slices.Contains(excluded, d) {
+			dirs = append(dirs, d)
+		}
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// expand resolves glob patterns relative to root to the directories they
+// match inside it. A trailing "/**" matches one level, which covers the
+// usual "packages/**".
+func expand(root string, patterns []string) []string {
+	var dirs []string
+	for _, pattern := range patterns {
+		if rest, ok := strings.CutSuffix(pattern, "/**"); ok {
+			pattern = rest + "/*"
+		}
+		matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		for _, m := range matches {
+			m = filepath.Clean(m)
+			if rel, err := filepath.Rel(root, m); err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			if info, err := os.Stat(m); err == nil && info.IsDir() {
+				dirs = append(dirs, m)
+			}
+		}
+	}
+	return dirs
+}
+
+func goWorkUses(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var uses []string
+	inBlock := false
+	for line := range strings.Lines(string(data)) {
+		line, _, _ = strings.Cut(line, "//")
+		line = strings.TrimSpace(line)
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			uses = append(uses, strings.Trim(line, `"`))
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			uses = append(uses, strings.Trim(strings.TrimSpace(line[len("use "):]), `"`))
+		}
+	}
+	return uses
+}
+
+func pnpmPackages(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var ws struct {
+		Packages []string `yaml:"packages"`
+	}
+	_ = yaml.Unmarshal(data, &ws)
+	return ws.Packages
+}
+
+func npmWorkspaces(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || len(pkg.Workspaces) == 0 {
+		return nil
+	}
+	var list []string
+	if json.Unmarshal(pkg.Workspaces, &list) == nil {
+		return list
+	}
+	// Yarn classic also accepts {"packages": [...], "nohoist": [...]}.
+	var obj struct {
+		Packages []string `json:"packages"`
+	}
+	_ = json.Unmarshal(pkg.Workspaces, &obj)
+	return obj.Packages
+}
+
+var (
+	tomlTable  = regexp.MustCompile(`(?m)^\s*\[([^\]]+)\]\s*$`)
+	tomlString = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+)
+
+// cargoMembers reads members and exclude from the [workspace] table. It
+// understands only the string arrays those keys hold, not TOML at large.
+func cargoMembers(path string) (members, exclude []string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	text := string(data)
+	var table string
+	for i, loc := range tomlTable.FindAllStringSubmatchIndex(text, -1) {
+		if strings.TrimSpace(text[loc[2]:loc[3]]) != "workspace" {
+			continue
+		}
+		table = text[loc[1]:]
+		if next := tomlTable.FindAllStringIndex(text, -1); i+1 < len(next) {
+			table = text[loc[1]:next[i+1][0]]
+		}
+		break
+	}
+	return tomlArray(table, "members"), tomlArray(table, "exclude")
+}
+
+func tomlArray(table, key string) []string {
+	re := regexp.MustCompile(`(?ms)^\s*` + key + `\s*=\s*\[(.*?)\]`)
+	m := re.FindStringSubmatch(table)
+	if m == nil {
+		return nil
+	}
+	var values []string
+	for _, s := range tomlString.FindAllStringSubmatch(m[1], -1) {
+		values = append(values, s[1]+s[2])
+	}
+	return values
+}
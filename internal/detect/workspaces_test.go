@@ -0,0 +1,83 @@
+package detect_test
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/detect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMembers(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		dirs  []string
+		extra []string
+		want  []string
+	}{
+		{
+			name:  "go.work",
+			files: map[string]string{"go.work": "go 1.22\n\nuse ./tools // helpers\n\nuse (\n\t.\n\t./api\n\t\"./web\"\n)\n"},
+			dirs:  []string{"api", "web", "tools"},
+			want:  []string{"api", "tools", "web"},
+		},
+		{
+			name:  "pnpm workspace with exclusion",
+			files: map[string]string{"pnpm-workspace.yaml": "packages:\n  - 'packages/**'\n  - '!packages/internal'\n"},
+			dirs:  []string{"packages/a", "packages/b", "packages/internal"},
+			want:  []string{"packages/a", "packages/b"},
+		},
+		{
+			name:  "package.json workspaces",
+			files: map[string]string{"package.json": `{"workspaces": ["apps/*", "lib"]}`},
+			dirs:  []string{"apps/site", "lib"},
+			want:  []string{"apps/site", "lib"},
+		},
+		{
+			name:  "yarn workspaces object",
+			files: map[string]string{"package.json": `{"workspaces": {"packages": ["pkgs/*"], "nohoist": ["**"]}}`},
+			dirs:  []string{"pkgs/ui"},
+			want:  []string{"pkgs/ui"},
+		},
+		{
+			name:  "cargo workspace",
+			files: map[string]string{"Cargo.toml": "[package]\nname = \"root\"\n\n[workspace]\nmembers = [\n  \"crates/*\",\n  'cli',\n]\nexclude = [\"crates/old\"]\n\n[dependencies]\nserde = \"1\"\n"},
+			dirs:  []string{"crates/core", "crates/old", "cli"},
+			want:  []string{"cli", "crates/core"},
+		},
+		{
+			name:  "configured globs skip files and missing dirs",
+			files: map[string]string{"services/README.md": ""},
+			dirs:  []string{"services/billing"},
+			extra: []string{"services/*", "gone"},
+			want:  []string{"services/billing"},
+		},
+		{
+			name:  "plain project",
+			files: map[string]string{"go.mod": "module x\n"},
+			dirs:  []string{"internal"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, dir := range tt.dirs {
+				require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0o755))
+			}
+			for name, content := range tt.files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0o755))
+				require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0o644))
+			}
+
+			var want []string
+			for _, dir := range tt.want {
+				want = append(want, filepath.Join(root, dir))
+			}
+			assert.Equal(t, want, detect.Members(root, tt.extra))
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pj/internal/catalog"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatCSV      Format = "csv"
+	FormatVSCode   Format = "vscode-project-manager"
+	FormatMarkdown Format = "markdown"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+const documentVersion = 1
+
+// Document is the portable form of the catalog written by the json and yaml
+// formats. It mirrors the catalog file so exports can be imported losslessly.
+type Document struct {
+	Version  int               `yaml:"version" json:"version"`
+	Projects []catalog.Project `yaml:"projects" json:"projects"`
+}
+
+func NewDocument(projects []catalog.Project) Document {
+	if projects == nil {
+		projects = []catalog.Project{}
+	}
+	return Document{Version: documentVersion, Projects: projects}
+}
+
+func Write(w io.Writer, format Format, projects []catalog.Project) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, projects)
+	case FormatYAML:
+		return writeYAML(w, projects)
+	case FormatCSV:
+		return writeCSV(w, projects)
+	case FormatVSCode:
+		return writeVSCode(w, projects)
+	case FormatMarkdown:
+		return writeMarkdown(w, projects)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+func writeJSON(w io.Writer, projects []catalog.Project) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewDocument(projects))
+}
+
+func writeYAML(w io.Writer, projects []catalog.Project) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(NewDocument(projects)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// field is one catalog.Project field as the csv and markdown formats write
+// it. Key is the field's yaml name.
+type field struct {
+	Key   string
+	Title string
+	Text  func(catalog.Project) string
+	// Cell renders the field for markdown; nil means use Text.
+	Cell func(catalog.Project) string
+	// CSVOnly leaves bookkeeping out of the markdown table.
+	CSVOnly bool
+}
+
+// fields lists every exported Project field, in column order.
+var fields = []field{
+	{Key: "id", Title: "ID", Text: func(p catalog.Project) string { return p.ID }},
+	{Key: "name", Title: "Name", Text: func(p catalog.Project) string { return p.Name }},
+	{Key: "path", Title: "Path", Text: func(p catalog.Project) string { return p.Path },
+		Cell: func(p catalog.Project) string { return "`" + p.Path + "`" }},
+	{Key: "description", Title: "Description", Text: func(p catalog.Project) string { return p.Description }},
+	{Key: "editor", Title: "Editor", Text: func(p catalog.Project) string { return p.Editor }},
+	{Key: "tags", Title: "Tags", Text: func(p catalog.Project) string { return strings.Join(p.Tags, ";") },
+		Cell: func(p catalog.Project) string { return strings.Join(p.Tags, ", ") }},
+	{Key: "added_at", Title: "Added", Text: func(p catalog.Project) string { return timestamp(p.AddedAt) },
+		Cell: func(p catalog.Project) string { return date(p.AddedAt) }},
+	{Key: "last_accessed", Title: "Last accessed", Text: func(p catalog.Project) string { return timestamp(p.LastAccessed) },
+		Cell: func(p catalog.Project) string { return date(p.LastAccessed) }},
+	{Key: "updated_at", Title: "Updated", Text: func(p catalog.Project) string { return timestamp(p.UpdatedAt) },
+		Cell: func(p catalog.Project) string { return date(p.UpdatedAt) }},
+	{Key: "status", Title: "Status", Text: func(p catalog.Project) string { return string(p.Status) }},
+	{Key: "parent", Title: "Parent", Text: func(p catalog.Project) string { return p.Parent }},
+	{Key: "remote", Title: "Remote", Text: func(p catalog.Project) string { return p.Remote }},
+	{Key: "stack", Title: "Stack", Text: func(p catalog.Project) string { return compactJSON(p.Stack) },
+		Cell: func(p catalog.Project) string {
+			if p.Stack == nil {
+				return ""
+			}
+			return strings.Join(p.Stack.Summary(), ", ")
+		}},
+	{Key: "worktrees", Title: "Worktrees", Text: func(p catalog.Project) string { return compactJSON(p.Worktrees) },
+		Cell: func(p catalog.Project) string {
+			names := make([]string, len(p.Worktrees))
+			for i, wt := range p.Worktrees {
+				names[i] = wt.Name()
+			}
+			return strings.Join(names, ", ")
+		}},
+	{Key: "tmux", Title: "Tmux", Text: func(p catalog.Project) string { return compactJSON(p.Tmux) },
+		Cell: func(p catalog.Project) string {
+			if p.Tmux == nil {
+				return ""
+			}
+			names := make([]string, len(p.Tmux.Windows))
+			for i, w := range p.Tmux.Windows {
+				names[i] = w.Name
+			}
+			return strings.Join(names, ", ")
+		}},
+	{Key: "archive_path", Title: "Archive", Text: func(p catalog.Project) string { return p.ArchivePath },
+		Cell: func(p catalog.Project) string {
+			if p.ArchivePath == "" {
+				return ""
+			}
+			return "`" + p.ArchivePath + "`"
+		}},
+	{Key: "status_before_archive", Text: func(p catalog.Project) string { return string(p.StatusBeforeArchive) }, CSVOnly: true},
+	{Key: "field_updated_at", Text: func(p catalog.Project) string { return compactJSON(p.FieldUpdatedAt) }, CSVOnly: true},
+}
+
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+// compactJSON encodes structured fields such as the tmux layout so a csv
+// cell keeps all of them. Unset values become an empty cell.
+func compactJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" || string(data) == "[]" {
+		return ""
+	}
+	return string(data)
+}
+
+func writeCSV(w io.Writer, projects []catalog.Project) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.Key
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, p := range projects {
+		record := make([]string, len(fields))
+		for i, f := range fields {
+			record[i] = f.Text(p)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type vscodeProject struct {
+	Name     string   `json:"name"`
+	RootPath string   `json:"rootPath"`
+	Paths    []string `json:"paths"`
+	Tags     []string `json:"tags"`
+	Enabled  bool     `json:"enabled"`
+}
+
+func writeVSCode(w io.Writer, projects []catalog.Project) error {
+	out := make([]vscodeProject, 0, len(projects))
+	for _, p := range projects {
+		tags := p.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		out = append(out, vscodeProject{
+			Name:     p.Name,
+			RootPath: p.Path,
+			Paths:    []string{},
+			Tags:     tags,
+			Enabled:  true,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(out)
+}
+
+func writeMarkdown(w io.Writer, projects []catalog.Project) error {
+	columns := slices.DeleteFunc(slices.Clone(fields), func(f field) bool { return f.CSVOnly })
+	var sb strings.Builder
+	titles := make([]string, len(columns))
+	for i, f := range columns {
+		titles[i] = f.Title
+	}
+	sb.WriteString("| " + strings.Join(titles, " | ") + " |\n")
+	sb.WriteString(strings.Repeat("| --- ", len(columns)) + "|\n")
+	for _, p := range projects {
+		cells := make([]string, len(columns))
+		for i, f := range columns {
+			cell := f.Cell
+			if cell == nil {
+				cell = f.Text
+			}
+			cells[i] = escapeMarkdownCell(cell(p))
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
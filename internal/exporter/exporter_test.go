@@ -0,0 +1,148 @@
+package exporter_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"pj/internal/catalog"
+	"pj/internal/exporter"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixedTime = time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC)
+
+func sampleProjects() []catalog.Project {
+	return []catalog.Project{
+		{
+			ID:           "11111111-1111-1111-1111-111111111111",
+			Name:         "api",
+			Path:         "/src/api",
+			AddedAt:      fixedTime,
+			LastAccessed: fixedTime,
+			Description:  "Backend | REST",
+			Editor:       "code",
+			Tags:         []string{"go", "work"},
+		},
+	}
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, exporter.Write(&buf, exporter.FormatJSON, sampleProjects()))
+
+	var doc exporter.Document
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, 1, doc.Version)
+	assert.Equal(t, sampleProjects(), doc.Projects)
+}
+
+func TestWrite_YAML(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, exporter.Write(&buf, exporter.FormatYAML, sampleProjects()))
+
+	assert.Contains(t, buf.String(), "version: 1\n")
+	assert.Contains(t, buf.String(), "name: api\n")
+	assert.Contains(t, buf.String(), "editor: code\n")
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, exporter.Write(&buf, exporter.FormatCSV, sampleProjects()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"id", "name", "path", "description", "editor", "tags", "added_at", "last_accessed"}, records[0][:8])
+	assert.Equal(t, "Backend | REST", records[1][3])
+	assert.Equal(t, "go;work", records[1][5])
+	assert.Equal(t, "2026-01-07T12:00:00Z", records[1][6])
+}
+
+func TestWrite_CSVHasEveryProjectField(t *testing.T) {
+	p := sampleProjects()[0]
+	p.Tmux = &catalog.TmuxLayout{Windows: []catalog.TmuxWindow{{Name: "main", Panes: []string{"nvim"}}}}
+	p.Stack = &catalog.Stack{Languages: []string{"go"}}
+	p.Remote = "github.com/acme/api"
+	p.Worktrees = []catalog.Worktree{{Branch: "fix", Path: "/src/api-fix"}}
+	p.Parent = "22222222-2222-2222-2222-222222222222"
+	p.Status = catalog.StatusArchived
+	p.ArchivePath = "/archive/api.tar.gz"
+	p.StatusBeforeArchive = catalog.StatusPaused
+	p.UpdatedAt = fixedTime
+	p.FieldUpdatedAt = map[string]time.Time{"status": fixedTime}
+	var buf bytes.Buffer
+
+	require.NoError(t, exporter.Write(&buf, exporter.FormatCSV, []catalog.Project{p}))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	row := make(map[string]string)
+	for i, key := range records[0] {
+		row[key] = records[1][i]
+	}
+	// Every field stored in the catalog must have a column.
+	typ := reflect.TypeFor[catalog.Project]()
+	for i := range typ.NumField() {
+		key, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		if key == "-" {
+			continue
+		}
+		assert.Contains(t, row, key, "field %s is not exported", typ.Field(i).Name)
+		assert.NotEmpty(t, row[key], "field %s is empty", typ.Field(i).Name)
+	}
+	assert.Equal(t, `{"windows":[{"name":"main","panes":["nvim"]}]}`, row["tmux"])
+	assert.Equal(t, `[{"branch":"fix","path":"/src/api-fix"}]`, row["worktrees"])
+	assert.Equal(t, "archived", row["status"])
+}
+
+func TestWrite_VSCode(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, exporter.Write(&buf, exporter.FormatVSCode, sampleProjects()))
+
+	var out []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	require.Len(t, out, 1)
+	assert.Equal(t, "api", out[0]["name"])
+	assert.Equal(t, "/src/api", out[0]["rootPath"])
+	assert.Equal(t, true, out[0]["enabled"])
+	assert.Equal(t, []any{"go", "work"}, out[0]["tags"])
+}
+
+func TestWrite_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, exporter.Write(&buf, exporter.FormatMarkdown, sampleProjects()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "| ID | Name | Path |")
+	assert.Contains(t, lines[2], "| api | `/src/api` | Backend \\| REST | code | go, work | 2026-01-07 | 2026-01-07 |")
+	// Every line has a column per field; the row's extra "|" is the escaped
+	// one in the description.
+	assert.Equal(t, strings.Count(lines[0], "|"), strings.Count(lines[1], "|"))
+	assert.Equal(t, strings.Count(lines[0], "|"), strings.Count(lines[2], "|")-1)
+}
+
+func TestWrite_EmptyJSONHasProjectsArray(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, exporter.Write(&buf, exporter.FormatJSON, nil))
+
+	assert.Contains(t, buf.String(), `"projects": []`)
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	err := exporter.Write(&bytes.Buffer{}, "xml", nil)
+
+	assert.ErrorIs(t, err, exporter.ErrUnknownFormat)
+}
@@ -0,0 +1,170 @@
+// Package forge builds web URLs for repositories hosted on GitHub, GitLab,
+// Gitea, Bitbucket and self-hosted instances of them.
+package forge
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"pj/internal/config"
+	"pj/internal/git"
+	"strconv"
+	"strings"
+)
+
+var ErrLocal = errors.New("local repositories have no web URL")
+
+// Link selects the page to open. The zero value is the repository's home
+// page.
+type Link struct {
+	Branch      string
+	File        string
+	Line        int
+	PullRequest int
+	Issue       int
+}
+
+var layouts = map[string]config.ForgeConfig{
+	"github": {
+		Repo:        "{base}/{repo}",
+		Branch:      "{base}/{repo}/tree/{branch}",
+		File:        "{base}/{repo}/blob/{branch}/{file}",
+		Line:        "#L{line}",
+		PullRequest: "{base}/{repo}/pull/{number}",
+		Issue:       "{base}/{repo}/issues/{number}",
+	},
+	"gitlab": {
+		Repo:        "{base}/{repo}",
+		Branch:      "{base}/{repo}/-/tree/{branch}",
+		File:        "{base}/{repo}/-/blob/{branch}/{file}",
+		Line:        "#L{line}",
+		PullRequest: "{base}/{repo}/-/merge_requests/{number}",
+		Issue:       "{base}/{repo}/-/issues/{number}",
+	},
+	"gitea": {
+		Repo:        "{base}/{repo}",
+		Branch:      "{base}/{repo}/src/branch/{branch}",
+		File:        "{base}/{repo}/src/branch/{branch}/{file}",
+		Line:        "#L{line}",
+		PullRequest: "{base}/{repo}/pulls/{number}",
+		Issue:       "{base}/{repo}/issues/{number}",
+	},
+	"bitbucket": {
+		Repo:        "{base}/{repo}",
+		Branch:      "{base}/{repo}/src/{branch}",
+		File:        "{base}/{repo}/src/{branch}/{file}",
+		Line:        "#lines-{line}",
+		PullRequest: "{base}/{repo}/pull-requests/{number}",
+		Issue:       "{base}/{repo}/issues/{number}",
+	},
+}
+
+var knownHosts = map[string]string{
+	"github.com":    "github",
+	"gitlab.com":    "gitlab",
+	"codeberg.org":  "gitea",
+	"gitea.com":     "gitea",
+	"bitbucket.org": "bitbucket",
+}
+
+// URL returns the web URL of link in the repository at remote. forges is
+// the user's per-host configuration; hosts without one are recognized by
+// name.
+func URL(forges map[string]config.ForgeConfig, remote git.RemoteURL, link Link) (string, error) {
+	if remote.Host == git.LocalHost {
+		return "", ErrLocal
+	}
+	layout, err := layoutFor(forges, remote.Host)
+	if err != nil {
+		return "", err
+	}
+
+	branch := link.Branch
+	if branch == "" {
+		branch = "HEAD"
+	}
+	var tmpl, number string
+	switch {
+	case link.PullRequest > 0:
+		tmpl, number = layout.PullRequest, strconv.Itoa(link.PullRequest)
+	case link.Issue > 0:
+		tmpl, number = layout.Issue, strconv.Itoa(link.Issue)
+	case link.File != "":
+		tmpl = layout.File
+		if link.Line > 0 && tmpl != "" {
+			tmpl += layout.Line
+		}
+	case link.Branch != "":
+		tmpl = layout.Branch
+	default:
+		tmpl = layout.Repo
+	}
+	if tmpl == "" {
+		return "", fmt.Errorf("no URL template for this link on %s", remote.Host)
+	}
+
+	base := strings.TrimSuffix(layout.BaseURL, "/")
+	if base == "" {
+		base = "https://" + remote.Host
+	}
+	return strings.NewReplacer(
+		"{base}", base,
+		"{repo}", escapePath(remote.Path),
+		"{owner}", escapePath(remote.Owner()),
+		"{name}", url.PathEscape(remote.Repo()),
+		"{branch}", escapePath(branch),
+		"{file}", escapePath(strings.TrimPrefix(link.File, "/")),
+		"{line}", strconv.Itoa(link.Line),
+		"{number}", number,
+	).Replace(tmpl), nil
+}
+
+// layoutFor merges the host's configured templates over its type's layout.
+func layoutFor(forges map[string]config.ForgeConfig, host string) (config.ForgeConfig, error) {
+	custom, configured := forges[host]
+	kind := custom.Type
+	if kind == "" {
+		kind = knownHosts[host]
+	}
+	if kind == "" {
+		kind = guessType(host)
+	}
+
+	layout, ok := layouts[kind]
+	if !ok && !configured {
+		return config.ForgeConfig{}, fmt.Errorf("unknown forge %s: set forges.%s.type in the config file", host, host)
+	}
+	layout.BaseURL = custom.BaseURL
+	override(&layout.Repo, custom.Repo)
+	override(&layout.Branch, custom.Branch)
+	override(&layout.File, custom.File)
+	override(&layout.Line, custom.Line)
+	override(&layout.PullRequest, custom.PullRequest)
+	override(&layout.Issue, custom.Issue)
+	return layout, nil
+}
+
+func override(dst *string, src string) {
+	if src != "" {
+		*dst = src
+	}
+}
+
+// guessType recognizes self-hosted instances named after their software,
+// such as gitlab.example.com.
+func guessType(host string) string {
+	for _, kind := range config.ForgeTypes {
+		if strings.Contains(host, kind) {
+			return kind
+		}
+	}
+	return ""
+}
+
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
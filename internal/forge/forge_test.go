@@ -0,0 +1,79 @@
+package forge_test
+
+import (
+	"pj/internal/config"
+	"pj/internal/forge"
+	"pj/internal/git"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURL(t *testing.T) {
+	file := forge.Link{Branch: "main", File: "cmd/server/main.go", Line: 42}
+	tests := []struct {
+		name   string
+		remote git.RemoteURL
+		link   forge.Link
+		want   string
+	}{
+		{"github repo", git.RemoteURL{Host: "github.com", Path: "acme/api"}, forge.Link{}, "https://github.com/acme/api"},
+		{"github file", git.RemoteURL{Host: "github.com", Path: "acme/api"}, file, "https://github.com/acme/api/blob/main/cmd/server/main.go#L42"},
+		{"github file without branch", git.RemoteURL{Host: "github.com", Path: "acme/api"}, forge.Link{File: "README.md"}, "https://github.com/acme/api/blob/HEAD/README.md"},
+		{"github branch", git.RemoteURL{Host: "github.com", Path: "acme/api"}, forge.Link{Branch: "feature/x y"}, "https://github.com/acme/api/tree/feature/x%20y"},
+		{"github pr", git.RemoteURL{Host: "github.com", Path: "acme/api"}, forge.Link{PullRequest: 7}, "https://github.com/acme/api/pull/7"},
+		{"gitlab file", git.RemoteURL{Host: "gitlab.com", Path: "group/sub/api"}, file, "https://gitlab.com/group/sub/api/-/blob/main/cmd/server/main.go#L42"},
+		{"gitlab merge request", git.RemoteURL{Host: "gitlab.com", Path: "group/api"}, forge.Link{PullRequest: 3}, "https://gitlab.com/group/api/-/merge_requests/3"},
+		{"gitea issue", git.RemoteURL{Host: "codeberg.org", Path: "acme/api"}, forge.Link{Issue: 9}, "https://codeberg.org/acme/api/issues/9"},
+		{"gitea file", git.RemoteURL{Host: "codeberg.org", Path: "acme/api"}, file, "https://codeberg.org/acme/api/src/branch/main/cmd/server/main.go#L42"},
+		{"bitbucket file", git.RemoteURL{Host: "bitbucket.org", Path: "acme/api"}, file, "https://bitbucket.org/acme/api/src/main/cmd/server/main.go#lines-42"},
+		{"bitbucket pr", git.RemoteURL{Host: "bitbucket.org", Path: "acme/api"}, forge.Link{PullRequest: 5}, "https://bitbucket.org/acme/api/pull-requests/5"},
+		{"self-hosted guessed by name", git.RemoteURL{Host: "gitlab.corp.example", Path: "team/api"}, forge.Link{Issue: 1}, "https://gitlab.corp.example/team/api/-/issues/1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := forge.URL(nil, tc.remote, tc.link)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestURL_Configured(t *testing.T) {
+	forges := map[string]config.ForgeConfig{
+		"git.corp.example": {Type: "gitea", BaseURL: "https://git.corp.example:8443/"},
+		"code.internal": {
+			Repo: "https://code.internal/projects/{owner}/repos/{name}",
+			File: "https://code.internal/projects/{owner}/repos/{name}/browse/{file}?at={branch}",
+			Line: "#{line}",
+		},
+	}
+
+	t.Run("type and base URL", func(t *testing.T) {
+		got, err := forge.URL(forges, git.RemoteURL{Host: "git.corp.example", Path: "team/api"}, forge.Link{PullRequest: 2})
+		require.NoError(t, err)
+		assert.Equal(t, "https://git.corp.example:8443/team/api/pulls/2", got)
+	})
+
+	t.Run("custom templates", func(t *testing.T) {
+		remote := git.RemoteURL{Host: "code.internal", Path: "TEAM/api"}
+
+		got, err := forge.URL(forges, remote, forge.Link{File: "main.go", Line: 3, Branch: "dev"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://code.internal/projects/TEAM/repos/api/browse/main.go?at=dev#3", got)
+
+		_, err = forge.URL(forges, remote, forge.Link{Issue: 1})
+		assert.ErrorContains(t, err, "no URL template")
+	})
+
+	t.Run("unknown hosts must be configured", func(t *testing.T) {
+		_, err := forge.URL(forges, git.RemoteURL{Host: "example.org", Path: "a/b"}, forge.Link{})
+		assert.ErrorContains(t, err, "set forges.example.org.type")
+	})
+
+	t.Run("local repositories have no URL", func(t *testing.T) {
+		_, err := forge.URL(forges, git.RemoteURL{Host: git.LocalHost, Path: "a/b"}, forge.Link{})
+		assert.ErrorIs(t, err, forge.ErrLocal)
+	})
+}
@@ -0,0 +1,92 @@
+// Package git reads repository state by shelling out to the git binary.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+var ErrNotRepository = errors.New("not a git repository")
+
+type Status struct {
+	Branch    string `json:"branch"`
+	Ahead     int    `json:"ahead"`
+	Behind    int    `json:"behind"`
+	Changed   int    `json:"changed"`
+	Untracked int    `json:"untracked"`
+}
+
+func (s Status) Dirty() bool {
+	return s.Changed > 0 || s.Untracked > 0
+}
+
+// StatusOf reports the branch and working tree state of the repository
+// containing dir.
+func StatusOf(ctx context.Context, dir string) (Status, error) {
+	out, err := run(ctx, dir, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return Status{}, err
+	}
+	return parseStatus(out), nil
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "not a git repository") {
+			return "", ErrNotRepository
+		}
+		if msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+func parseStatus(out string) Status {
+	var s Status
+	for line := range strings.Lines(out) {
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			s.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			for field := range strings.FieldsSeq(strings.TrimPrefix(line, "# branch.ab ")) {
+				n, _ := strconv.Atoi(field[1:])
+				if field[0] == '+' {
+					s.Ahead = n
+				} else {
+					s.Behind = n
+				}
+			}
+		case strings.HasPrefix(line, "? "):
+			s.Untracked++
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			s.Changed++
+		}
+	}
+	return s
+}
+
+// Origin returns the URL of the origin remote, or "" when the repository
+// has none.
+func Origin(ctx context.Context, dir string) (string, error) {
+	out, err := run(ctx, dir, "remote", "get-url", "origin")
+	if err != nil {
+		if strings.Contains(err.Error(), "No such remote") {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,139 @@
+package git_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"pj/internal/git"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("hi\n"), 0o644))
+	gitCmd(t, dir, "add", "README")
+	gitCmd(t, dir, "commit", "-q", "-m", "init")
+	return dir
+}
+
+func TestStatusOf(t *testing.T) {
+	t.Run("clean repository", func(t *testing.T) {
+		dir := initRepo(t)
+
+		s, err := git.StatusOf(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, git.Status{Branch: "main"}, s)
+		assert.False(t, s.Dirty())
+	})
+
+	t.Run("counts changed and untracked files", func(t *testing.T) {
+		dir := initRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("changed\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new"), nil, 0o644))
+
+		s, err := git.StatusOf(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.Changed)
+		assert.Equal(t, 1, s.Untracked)
+		assert.True(t, s.Dirty())
+	})
+
+	t.Run("reports commits ahead of upstream", func(t *testing.T) {
+		upstream := initRepo(t)
+		dir := filepath.Join(t.TempDir(), "clone")
+		gitCmd(t, upstream, "clone", "-q", upstream, dir)
+		gitCmd(t, dir, "commit", "-q", "--allow-empty", "-m", "local")
+
+		s, err := git.StatusOf(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.Ahead)
+		assert.Equal(t, 0, s.Behind)
+	})
+
+	t.Run("returns ErrNotRepository outside a repository", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not installed")
+		}
+
+		_, err := git.StatusOf(context.Background(), t.TempDir())
+
+		assert.ErrorIs(t, err, git.ErrNotRepository)
+	})
+}
+
+func TestOrigin(t *testing.T) {
+	dir := initRepo(t)
+	ctx := context.Background()
+
+	origin, err := git.Origin(ctx, dir)
+	require.NoError(t, err)
+	assert.Empty(t, origin)
+
+	gitCmd(t, dir, "remote", "add", "origin", "git@github.com:acme/api.git")
+	origin, err = git.Origin(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "git@github.com:acme/api.git", origin)
+
+	_, err = git.Origin(ctx, t.TempDir())
+	assert.ErrorIs(t, err, git.ErrNotRepository)
+}
+
+func TestWorktrees(t *testing.T) {
+	dir := initRepo(t)
+	ctx := context.Background()
+	wtDir := filepath.Join(t.TempDir(), "feature-x")
+
+	require.NoError(t, git.AddWorktree(ctx, dir, wtDir, "feature-x", ""))
+	gitCmd(t, dir, "branch", "existing")
+	existingDir := filepath.Join(t.TempDir(), "existing")
+	require.NoError(t, git.AddWorktree(ctx, dir, existingDir, "existing", ""))
+
+	list, err := git.Worktrees(ctx, wtDir)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, "main", list[0].Branch)
+	assert.Equal(t, "feature-x", list[1].Branch)
+	assert.Equal(t, "existing", list[2].Branch)
+
+	assert.True(t, git.IsMainWorktree(ctx, dir))
+	assert.False(t, git.IsMainWorktree(ctx, wtDir))
+	main, ok := git.MainOfLinked(ctx, wtDir)
+	assert.True(t, ok)
+	assert.True(t, git.IsMainWorktree(ctx, main))
+	require.NoError(t, os.Mkdir(filepath.Join(wtDir, "sub"), 0o755))
+	_, ok = git.MainOfLinked(ctx, filepath.Join(wtDir, "sub"))
+	assert.False(t, ok, "subdirectories are not worktrees")
+	_, ok = git.MainOfLinked(ctx, dir)
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(filepath.Join(wtDir, "dirty"), []byte("x"), 0o644))
+	assert.Error(t, git.RemoveWorktree(ctx, dir, wtDir, false), "refuses to drop local changes")
+	require.NoError(t, git.RemoveWorktree(ctx, dir, wtDir, true))
+
+	list, err = git.Worktrees(ctx, dir)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
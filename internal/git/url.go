@@ -0,0 +1,135 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalHost is the host recorded for repositories cloned from the local
+// filesystem.
+const LocalHost = "localhost"
+
+// RemoteURL identifies a repository by host and path, the way ghq lays
+// clones out on disk.
+type RemoteURL struct {
+	Host string
+	// Path is the slash-separated repository path without ".git", e.g.
+	// "acme/api" or "group/subgroup/api".
+	Path string
+}
+
+// Owner is everything before the repository name; nested groups are kept.
+func (u RemoteURL) Owner() string {
+	owner, _ := path.Split(u.Path)
+	return strings.TrimSuffix(owner, "/")
+}
+
+// Repo is the repository name.
+func (u RemoteURL) Repo() string {
+	return path.Base(u.Path)
+}
+
+func (u RemoteURL) String() string {
+	return u.Host + "/" + u.Path
+}
+
+// ParseURL understands scheme URLs (https, ssh, git, file), scp-like
+// "user@host:owner/repo" and local paths. Local repositories are placed
+// under LocalHost with their last two path elements.
+func ParseURL(raw string) (RemoteURL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return RemoteURL{}, fmt.Errorf("empty repository URL")
+	}
+
+	var host, p string
+	switch {
+	case strings.Contains(raw, "://"):
+		u, err := url.Parse(raw)
+		if err != nil {
+			return RemoteURL{}, fmt.Errorf("invalid repository URL %q: %w", raw, err)
+		}
+		if u.Scheme == "file" {
+			return localURL(raw, u.Path)
+		}
+		host, p = u.Hostname(), u.Path
+	case isSCPLike(raw):
+		userHost, rest, _ := strings.Cut(raw, ":")
+		_, h, found := strings.Cut(userHost, "@")
+		if !found {
+			h = userHost
+		}
+		host, p = h, rest
+	default:
+		return localURL(raw, raw)
+	}
+
+	if host == "" {
+		return RemoteURL{}, fmt.Errorf("no host in repository URL %q", raw)
+	}
+	p = cleanRepoPath(p)
+	if p == "" {
+		return RemoteURL{}, fmt.Errorf("no repository path in URL %q", raw)
+	}
+	return RemoteURL{Host: strings.ToLower(host), Path: p}, nil
+}
+
+// NormalizeURL reduces a remote URL to the host/path form of
+// RemoteURL.String, dropping the scheme and any credentials it holds. It
+// returns "" for URLs ParseURL rejects.
+func NormalizeURL(raw string) string {
+	u, err := ParseURL(raw)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// ParseRemote reads a remote as the catalog stores it: the host/path form
+// NormalizeURL returns, or any URL ParseURL accepts, as older catalogs
+// hold.
+func ParseRemote(s string) (RemoteURL, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "://") && !isSCPLike(s) && !filepath.IsAbs(s) {
+		host, p, _ := strings.Cut(s, "/")
+		if p = cleanRepoPath(p); host != "" && p != "" {
+			return RemoteURL{Host: strings.ToLower(host), Path: p}, nil
+		}
+	}
+	return ParseURL(s)
+}
+
+// isSCPLike reports whether raw is "[user@]host:path", which git treats as
+// ssh as long as no slash comes before the colon.
+func isSCPLike(raw string) bool {
+	colon := strings.Index(raw, ":")
+	slash := strings.Index(raw, "/")
+	return colon > 0 && (slash < 0 || colon < slash)
+}
+
+func localURL(raw, dir string) (RemoteURL, error) {
+	p := cleanRepoPath(filepath.ToSlash(dir))
+	if p == "" {
+		return RemoteURL{}, fmt.Errorf("no repository path in %q", raw)
+	}
+	if parts := strings.Split(p, "/"); len(parts) > 2 {
+		p = strings.Join(parts[len(parts)-2:], "/")
+	}
+	return RemoteURL{Host: LocalHost, Path: p}, nil
+}
+
+func cleanRepoPath(p string) string {
+	p = strings.Trim(path.Clean("/"+p), "/")
+	p = strings.TrimSuffix(p, ".git")
+	return strings.TrimPrefix(p, "~")
+}
+
+// Clone clones url into dir, whose parent must exist.
+func Clone(ctx context.Context, url, dir string) error {
+	_, err := run(ctx, filepath.Dir(dir), "clone", "--quiet", "--", url, dir)
+	return err
+}
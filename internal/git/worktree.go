@@ -0,0 +1,122 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is one entry of `git worktree list`.
+type Worktree struct {
+	Path     string
+	Branch   string
+	Detached bool
+	Bare     bool
+	// Prunable worktrees have lost their directory.
+	Prunable bool
+}
+
+// Worktrees lists the worktrees of the repository containing dir. The main
+// worktree comes first.
+func Worktrees(ctx context.Context, dir string) ([]Worktree, error) {
+	out, err := run(ctx, dir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktrees(out), nil
+}
+
+func parseWorktrees(out string) []Worktree {
+	var list []Worktree
+	for line := range strings.Lines(out) {
+		line = strings.TrimRight(line, "\n")
+		key, value, _ := strings.Cut(line, " ")
+		if key == "worktree" {
+			list = append(list, Worktree{Path: value})
+			continue
+		}
+		if len(list) == 0 {
+			continue
+		}
+		w := &list[len(list)-1]
+		switch key {
+		case "branch":
+			w.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "detached":
+			w.Detached = true
+		case "bare":
+			w.Bare = true
+		case "prunable":
+			w.Prunable = true
+		}
+	}
+	return list
+}
+
+// IsMainWorktree reports whether dir is the top of a repository's main
+// worktree rather than a linked worktree or a subdirectory.
+func IsMainWorktree(ctx context.Context, dir string) bool {
+	list, err := Worktrees(ctx, dir)
+	return err == nil && len(list) > 0 && samePath(list[0].Path, dir)
+}
+
+// MainOfLinked returns the main worktree when dir is the top of a linked
+// worktree.
+func MainOfLinked(ctx context.Context, dir string) (string, bool) {
+	list, err := Worktrees(ctx, dir)
+	if err != nil || len(list) < 2 {
+		return "", false
+	}
+	for _, w := range list[1:] {
+		if samePath(w.Path, dir) {
+			return list[0].Path, true
+		}
+	}
+	return "", false
+}
+
+// AddWorktree checks out branch at path. A branch that exists neither
+// locally nor on origin is created from base, or from HEAD when base is
+// empty.
+func AddWorktree(ctx context.Context, dir, path, branch, base string) error {
+	args := []string{"worktree", "add", "--quiet"}
+	switch {
+	case refExists(ctx, dir, "refs/heads/"+branch):
+		args = append(args, "--", path, branch)
+	case base == "" && refExists(ctx, dir, "refs/remotes/origin/"+branch):
+		args = append(args, "--track", "-b", branch, "--", path, "origin/"+branch)
+	default:
+		args = append(args, "-b", branch, "--", path)
+		if base != "" {
+			args = append(args, base)
+		}
+	}
+	_, err := run(ctx, dir, args...)
+	return err
+}
+
+// RemoveWorktree deletes the worktree at path. Without force git refuses
+// when it has local changes.
+func RemoveWorktree(ctx context.Context, dir, path string, force bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	_, err := run(ctx, dir, append(args, "--", path)...)
+	return err
+}
+
+func refExists(ctx context.Context, dir, ref string) bool {
+	_, err := run(ctx, dir, "rev-parse", "--verify", "--quiet", ref)
+	return err == nil
+}
+
+func samePath(a, b string) bool {
+	if ra, err := filepath.EvalSymlinks(a); err == nil {
+		a = ra
+	}
+	if rb, err := filepath.EvalSymlinks(b); err == nil {
+		b = rb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
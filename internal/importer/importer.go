@@ -0,0 +1,179 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/config"
+	"pj/internal/detect"
+	"pj/internal/git"
+	"strings"
+)
+
+type Source string
+
+const (
+	SourceVSCode     Source = "vscode"
+	SourceProjectile Source = "projectile"
+	SourceZoxide     Source = "zoxide"
+	SourceGHQ        Source = "ghq"
+	SourcePlain      Source = "plain"
+	SourceJSON       Source = "json"
+	SourceYAML       Source = "yaml"
+)
+
+var ErrUnknownSource = errors.New("unknown import source")
+
+type Entry struct {
+	Name string
+	Path string
+	Tags []string
+	// Project is set by pj's own json and yaml formats and is imported
+	// verbatim, keeping its ID and timestamps.
+	Project *catalog.Project
+}
+
+// Read parses the bookmark store at path. For ghq, path is a ghq root
+// directory; for every other source it is a file, or "-" for stdin.
+func Read(source Source, path string, stdin io.Reader) ([]Entry, error) {
+	if path == "-" {
+		if source == SourceGHQ {
+			return nil, errors.New("ghq imports need a root directory, not stdin")
+		}
+		return Parse(source, stdin)
+	}
+
+	path, err := config.ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if source == SourceGHQ {
+		return ScanGHQRoot(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(source, f)
+}
+
+func Parse(source Source, r io.Reader) ([]Entry, error) {
+	switch source {
+	case SourceVSCode:
+		return ParseVSCode(r)
+	case SourceProjectile:
+		return ParseProjectile(r)
+	case SourceZoxide:
+		return ParseZoxide(r)
+	case SourcePlain:
+		return ParsePlain(r)
+	case SourceJSON:
+		return ParseJSON(r)
+	case SourceYAML:
+		return ParseYAML(r)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSource, source)
+	}
+}
+
+type Outcome string
+
+const (
+	OutcomeImported Outcome = "imported"
+	OutcomeSkipped  Outcome = "skipped"
+	OutcomeInvalid  Outcome = "invalid"
+)
+
+type Result struct {
+	Entry   Entry
+	Outcome Outcome
+	Reason  string
+}
+
+type Report struct {
+	Results []Result
+}
+
+func (r Report) Count(o Outcome) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Outcome == o {
+			n++
+		}
+	}
+	return n
+}
+
+// Apply adds every entry in a single batch run by batch, such as a
+// catalog's Batch method, skipping paths that are already cataloged and
+// reporting entries that cannot be added. The valid entries are saved
+// together, or not at all if saving fails.
+func Apply(batch func(fn func(tx catalog.Tx) error) error, entries []Entry) (Report, error) {
+	var report Report
+	err := batch(func(tx catalog.Tx) error {
+		for _, e := range entries {
+			res, err := applyEntry(tx, e)
+			if err != nil {
+				return err
+			}
+			report.Results = append(report.Results, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+func applyEntry(tx catalog.Tx, e Entry) (Result, error) {
+	path, err := config.ExpandPath(e.Path)
+	if err != nil {
+		return Result{Entry: e, Outcome: OutcomeInvalid, Reason: err.Error()}, nil
+	}
+	e.Path = path
+	if strings.TrimSpace(e.Name) == "" {
+		e.Name = filepath.Base(path)
+	}
+
+	if existing, err := tx.GetByPath(path); err == nil {
+		return Result{Entry: e, Outcome: OutcomeSkipped, Reason: "already cataloged as " + existing.Name}, nil
+	}
+
+	info, err := os.Stat(path)
+	if err == nil && !info.IsDir() {
+		return Result{Entry: e, Outcome: OutcomeInvalid, Reason: "not a directory"}, nil
+	}
+
+	origin, _ := git.Origin(context.Background(), path)
+	p := catalog.NewProject(e.Name, path).
+		WithTags(e.Tags...).
+		WithStack(detect.Dir(path)).
+		WithRemote(git.NormalizeURL(origin))
+	if e.Project != nil {
+		if existing, err := tx.Get(e.Project.ID); err == nil {
+			return Result{Entry: e, Outcome: OutcomeSkipped, Reason: "ID already cataloged as " + existing.Name}, nil
+		}
+		p = *e.Project
+		p.Name = e.Name
+		p.Path = path
+	}
+
+	// Invalid entries are reported rather than handed to the batch, which
+	// would reject the whole import.
+	candidate := p
+	if err := candidate.ValidateAndNormalize(); err != nil {
+		return Result{Entry: e, Outcome: OutcomeInvalid, Reason: err.Error()}, nil
+	}
+
+	if err := tx.Add(p); err != nil {
+		return Result{}, err
+	}
+	return Result{Entry: e, Outcome: OutcomeImported}, nil
+}
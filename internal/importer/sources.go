@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"pj/internal/exporter"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type vscodeProject struct {
+	Name     string   `json:"name"`
+	RootPath string   `json:"rootPath"`
+	Tags     []string `json:"tags"`
+	Enabled  *bool    `json:"enabled"`
+}
+
+// ParseVSCode reads the projects.json of the VS Code Project Manager
+// extension. Disabled entries are dropped.
+func ParseVSCode(r io.Reader) ([]Entry, error) {
+	var projects []vscodeProject
+	if err := json.NewDecoder(r).Decode(&projects); err != nil {
+		return nil, fmt.Errorf("parsing projects.json: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(projects))
+	for _, p := range projects {
+		if p.Enabled != nil && !*p.Enabled {
+			continue
+		}
+		path := p.RootPath
+		if rest, ok := strings.CutPrefix(path, "$home"); ok {
+			path = "~" + rest
+		}
+		entries = append(entries, Entry{Name: p.Name, Path: path, Tags: p.Tags})
+	}
+	return entries, nil
+}
+
+// ParseProjectile reads projectile-bookmarks.eld, an Emacs Lisp list of
+// quoted directory strings.
+func ParseProjectile(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	var current strings.Builder
+	inString, escaped := false, false
+	for _, c := range string(data) {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			if inString {
+				entries = append(entries, Entry{Path: current.String()})
+				current.Reset()
+			}
+			inString = !inString
+		case inString:
+			current.WriteRune(c)
+		}
+	}
+	if inString {
+		return nil, errors.New("parsing projectile bookmarks: unterminated string")
+	}
+	return entries, nil
+}
+
+// ParseZoxide reads the output of `zoxide query -ls`: a score followed by a
+// path on each line.
+func ParseZoxide(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		_, path, ok := strings.Cut(line, " ")
+		path = strings.TrimSpace(path)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("parsing zoxide output: malformed line %q", line)
+		}
+		entries = append(entries, Entry{Path: path})
+	}
+	return entries, scanner.Err()
+}
+
+// ParsePlain reads one path per line, ignoring blank lines and # comments.
+func ParsePlain(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, Entry{Path: line})
+	}
+	return entries, scanner.Err()
+}
+
+// ScanGHQRoot finds repositories laid out as <root>/<host>/<owner>/<repo>.
+func ScanGHQRoot(root string) ([]Entry, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("ghq root is not a directory: %s", root)
+	}
+
+	var entries []Entry
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == root {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		depth := strings.Count(rel, string(filepath.Separator)) + 1
+		if depth < 3 {
+			return nil
+		}
+		if isRepo(path) {
+			entries = append(entries, Entry{Name: d.Name(), Path: path})
+		}
+		return filepath.SkipDir
+	})
+	return entries, err
+}
+
+func isRepo(dir string) bool {
+	for _, marker := range []string{".git", ".hg", ".svn", ".jj"} {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseJSON reads a catalog written by `pj export --to json`.
+func ParseJSON(r io.Reader) ([]Entry, error) {
+	var doc exporter.Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing pj JSON export: %w", err)
+	}
+	return documentEntries(doc), nil
+}
+
+// ParseYAML reads a catalog written by `pj export --to yaml`, or a catalog
+// file itself.
+func ParseYAML(r io.Reader) ([]Entry, error) {
+	var doc exporter.Document
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing pj YAML export: %w", err)
+	}
+	return documentEntries(doc), nil
+}
+
+func documentEntries(doc exporter.Document) []Entry {
+	entries := make([]Entry, 0, len(doc.Projects))
+	for _, p := range doc.Projects {
+		if p.ID == "" {
+			entries = append(entries, Entry{Name: p.Name, Path: p.Path})
+			continue
+		}
+		entries = append(entries, Entry{Name: p.Name, Path: p.Path, Project: &p})
+	}
+	return entries
+}
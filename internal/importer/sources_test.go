@@ -0,0 +1,108 @@
+package importer_test
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/importer"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVSCode(t *testing.T) {
+	t.Run("reads name and root path", func(t *testing.T) {
+		input := `[
+  {"name": "api", "rootPath": "/src/api", "paths": [], "tags": ["work"], "enabled": true},
+  {"name": "dotfiles", "rootPath": "$home/dotfiles"},
+  {"name": "old", "rootPath": "/src/old", "enabled": false}
+]`
+
+		entries, err := importer.ParseVSCode(strings.NewReader(input))
+
+		require.NoError(t, err)
+		assert.Equal(t, []importer.Entry{
+			{Name: "api", Path: "/src/api", Tags: []string{"work"}},
+			{Name: "dotfiles", Path: "~/dotfiles"},
+		}, entries)
+	})
+
+	t.Run("returns error for malformed JSON", func(t *testing.T) {
+		_, err := importer.ParseVSCode(strings.NewReader(`[{"name":`))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "projects.json")
+	})
+}
+
+func TestParseProjectile(t *testing.T) {
+	t.Run("reads quoted paths", func(t *testing.T) {
+		input := `("~/src/api/" "/opt/with \"quote\"/")` + "\n"
+
+		entries, err := importer.ParseProjectile(strings.NewReader(input))
+
+		require.NoError(t, err)
+		assert.Equal(t, []importer.Entry{
+			{Path: "~/src/api/"},
+			{Path: `/opt/with "quote"/`},
+		}, entries)
+	})
+
+	t.Run("returns error for unterminated string", func(t *testing.T) {
+		_, err := importer.ParseProjectile(strings.NewReader(`("~/src/api/`))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestParseZoxide(t *testing.T) {
+	t.Run("strips scores", func(t *testing.T) {
+		input := "  52.0 /src/api\n   4.5 /src/with space\n\n"
+
+		entries, err := importer.ParseZoxide(strings.NewReader(input))
+
+		require.NoError(t, err)
+		assert.Equal(t, []importer.Entry{
+			{Path: "/src/api"},
+			{Path: "/src/with space"},
+		}, entries)
+	})
+
+	t.Run("returns error for line without path", func(t *testing.T) {
+		_, err := importer.ParseZoxide(strings.NewReader("52.0\n"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestParsePlain(t *testing.T) {
+	input := "# my projects\n/src/api\n\n  /src/web  \n"
+
+	entries, err := importer.ParsePlain(strings.NewReader(input))
+
+	require.NoError(t, err)
+	assert.Equal(t, []importer.Entry{{Path: "/src/api"}, {Path: "/src/web"}}, entries)
+}
+
+func TestScanGHQRoot(t *testing.T) {
+	root := t.TempDir()
+	for _, repo := range []string{"github.com/acme/api", "gitlab.com/me/dotfiles"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, repo, ".git"), 0o755))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "github.com", "acme", "not-a-repo"), 0o755))
+
+	entries, err := importer.ScanGHQRoot(root)
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []importer.Entry{
+		{Name: "api", Path: filepath.Join(root, "github.com", "acme", "api")},
+		{Name: "dotfiles", Path: filepath.Join(root, "gitlab.com", "me", "dotfiles")},
+	}, entries)
+}
+
+func TestParse_UnknownSource(t *testing.T) {
+	_, err := importer.Parse("nope", strings.NewReader(""))
+
+	assert.ErrorIs(t, err, importer.ErrUnknownSource)
+}
@@ -0,0 +1,153 @@
+// Package notes keeps per-project notes as markdown files named after the
+// project's ID, so renaming or moving a project keeps its notes.
+//
+// Each note is a "## 2006-01-02 15:04" heading followed by its text. The
+// files are meant to be edited by hand; anything before the first dated
+// heading, such as a title, is left alone and is not a note.
+package notes
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const timeLayout = "2006-01-02 15:04"
+
+var ErrEmpty = errors.New("note is empty")
+
+// Note is one dated entry of a project's notes.
+type Note struct {
+	ProjectID string
+	Time      time.Time
+	Text      string
+}
+
+// Store is a directory of notes files.
+type Store struct {
+	dir string
+}
+
+// New returns the store in dir. The directory is created on first write.
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// ForCatalog returns the store kept next to the catalog file at path.
+func ForCatalog(path string) *Store {
+	return New(filepath.Join(filepath.Dir(path), "notes"))
+}
+
+// Path returns the notes file of the project with id.
+func (s *Store) Path(id string) string {
+	return filepath.Join(s.dir, id+".md")
+}
+
+// Append adds a note dated at to the project's notes.
+func (s *Store) Append(id string, at time.Time, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ErrEmpty
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create notes directory: %w", err)
+	}
+
+	path := s.Path(id)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read notes: %w", err)
+	}
+	var buf bytes.Buffer
+	switch {
+	case len(existing) == 0:
+	case bytes.HasSuffix(existing, []byte("\n\n")):
+	case bytes.HasSuffix(existing, []byte("\n")):
+		buf.WriteString("\n")
+	default:
+		buf.WriteString("\n\n")
+	}
+	fmt.Fprintf(&buf, "## %s\n\n%s\n", at.Local().Format(timeLayout), text)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open notes: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns the project's notes, oldest first. A project without notes
+// has none.
+func (s *Store) Read(id string) ([]Note, error) {
+	data, err := os.ReadFile(s.Path(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+	return Parse(id, data), nil
+}
+
+// Since returns every project's notes dated at or after t, oldest first.
+func (s *Store) Since(t time.Time) ([]Note, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	var all []Note
+	for _, file := range files {
+		notes, err := s.Read(strings.TrimSuffix(filepath.Base(file), ".md"))
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			if !n.Time.Before(t) {
+				all = append(all, n)
+			}
+		}
+	}
+	slices.SortStableFunc(all, func(a, b Note) int { return a.Time.Compare(b.Time) })
+	return all, nil
+}
+
+// Parse splits a notes file into its dated notes, oldest first.
+func Parse(id string, data []byte) []Note {
+	var notes []Note
+	var text []string
+	current := -1
+	flush := func() {
+		if current >= 0 {
+			notes[current].Text = strings.TrimSpace(strings.Join(text, "\n"))
+		}
+		text = text[:0]
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			if t, err := time.ParseInLocation(timeLayout, strings.TrimSpace(heading), time.Local); err == nil {
+				flush()
+				notes = append(notes, Note{ProjectID: id, Time: t})
+				current = len(notes) - 1
+				continue
+			}
+		}
+		text = append(text, line)
+	}
+	flush()
+
+	slices.SortStableFunc(notes, func(a, b Note) int { return a.Time.Compare(b.Time) })
+	return notes
+}
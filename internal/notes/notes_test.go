@@ -0,0 +1,65 @@
+package notes_test
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/notes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestStore_AppendAndRead(t *testing.T) {
+	store := notes.New(filepath.Join(t.TempDir(), "notes"))
+
+	list, err := store.Read("p1")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, store.Append("p1", at("2026-10-01 09:30"), "first"))
+	require.NoError(t, store.Append("p1", at("2026-10-02 17:05"), "second\n\nwith a paragraph\n"))
+	assert.ErrorIs(t, store.Append("p1", time.Now(), "  "), notes.ErrEmpty)
+
+	data, err := os.ReadFile(store.Path("p1"))
+	require.NoError(t, err)
+	assert.Equal(t, "## 2026-10-01 09:30\n\nfirst\n\n## 2026-10-02 17:05\n\nsecond\n\nwith a paragraph\n", string(data))
+
+	list, err = store.Read("p1")
+	require.NoError(t, err)
+	assert.Equal(t, []notes.Note{
+		{ProjectID: "p1", Time: at("2026-10-01 09:30"), Text: "first"},
+		{ProjectID: "p1", Time: at("2026-10-02 17:05"), Text: "second\n\nwith a paragraph"},
+	}, list)
+}
+
+func TestParse(t *testing.T) {
+	data := "# api\n\nScratch space.\n\n## 2026-10-03 08:00\n\nlater\n### Details\n- a\n\n## 2026-10-01 12:00\nearlier\n"
+
+	assert.Equal(t, []notes.Note{
+		{ProjectID: "x", Time: at("2026-10-01 12:00"), Text: "earlier"},
+		{ProjectID: "x", Time: at("2026-10-03 08:00"), Text: "later\n### Details\n- a"},
+	}, notes.Parse("x", []byte(data)))
+}
+
+func TestStore_Since(t *testing.T) {
+	store := notes.New(t.TempDir())
+	require.NoError(t, store.Append("a", at("2026-10-01 09:00"), "old"))
+	require.NoError(t, store.Append("a", at("2026-10-05 09:00"), "a new"))
+	require.NoError(t, store.Append("b", at("2026-10-04 09:00"), "b new"))
+
+	list, err := store.Since(at("2026-10-02 00:00"))
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "b new", list[0].Text)
+	assert.Equal(t, "a new", list[1].Text)
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"pj/internal/git"
+	"pj/pkg/pj"
+	"slices"
+	"time"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+const gitStatusTimeout = 5 * time.Second
+
+// Opener launches an editor for a project on the machine running the server.
+type Opener func(p pj.Project) error
+
+// WithOpener enables the dashboard's "open in editor" action.
+func (s *Server) WithOpener(open Opener) *Server {
+	s.open = open
+	return s
+}
+
+// WithStaleAfter sets how long a project can go unmodified before the
+// dashboard marks it stale.
+func (s *Server) WithStaleAfter(d time.Duration) *Server {
+	s.staleAfter = d
+	return s
+}
+
+func (s *Server) WithClock(now func() time.Time) *Server {
+	s.now = now
+	return s
+}
+
+func (s *Server) registerDashboard() {
+	static, _ := fs.Sub(staticFiles, "static")
+	s.mux.Handle("GET /{$}", http.FileServerFS(static))
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	s.mux.HandleFunc("GET /v1/dashboard", s.dashboard)
+	s.mux.HandleFunc("GET /v1/projects/{id}/git", s.gitStatus)
+	s.mux.HandleFunc("POST /v1/projects/{id}/open", s.openProject)
+}
+
+type dashboardItem struct {
+	pj.Project
+	Modified  time.Time `json:"modified,omitzero"`
+	Stale     bool      `json:"stale"`
+	Available bool      `json:"available"`
+}
+
+// dashboard lists projects by most recent modification, like `pj list`,
+// along with every tag in the catalog for the tag filter.
+func (s *Server) dashboard(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	projects := s.cat.Filter(pj.FilterOptions{Query: q.Get("q"), Tag: q.Get("tag"), ExcludeArchived: true})
+
+	now := s.now()
+	items := make([]dashboardItem, len(projects))
+	for i, p := range projects {
+		item := dashboardItem{Project: p, Available: !p.Unavailable}
+		if info, err := os.Stat(p.Path); err == nil {
+			item.Modified = info.ModTime()
+			item.Stale = s.staleAfter > 0 && now.Sub(item.Modified) > s.staleAfter
+		} else {
+			item.Available = false
+		}
+		items[i] = item
+	}
+	slices.SortStableFunc(items, func(a, b dashboardItem) int {
+		return b.Modified.Compare(a.Modified)
+	})
+
+	var tags []string
+	for _, p := range s.cat.List() {
+		tags = append(tags, p.Tags...)
+	}
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"projects": items,
+		"tags":     append([]string{}, tags...),
+		"can_open": s.open != nil && s.token != "",
+	})
+}
+
+type gitResponse struct {
+	Repository bool `json:"repository"`
+	*git.Status
+	Dirty bool `json:"dirty"`
+}
+
+func (s *Server) gitStatus(w http.ResponseWriter, r *http.Request) {
+	p, err := s.cat.Get(r.PathValue("id"))
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), gitStatusTimeout)
+	defer cancel()
+	status, err := git.StatusOf(ctx, p.Path)
+	switch {
+	case errors.Is(err, git.ErrNotRepository):
+		writeJSON(w, http.StatusOK, gitResponse{})
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusOK, gitResponse{Repository: true, Status: &status, Dirty: status.Dirty()})
+	}
+}
+
+func (s *Server) openProject(w http.ResponseWriter, r *http.Request) {
+	if s.open == nil {
+		writeError(w, http.StatusNotImplemented, errors.New("opening projects is not enabled on this server"))
+		return
+	}
+
+	p, err := s.cat.Get(r.PathValue("id"))
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	if _, err := os.Stat(p.Path); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, errors.New("project path no longer exists: "+p.Path))
+		return
+	}
+	if err := s.open(p); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if p, err = s.cat.Touch(p.ID); err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
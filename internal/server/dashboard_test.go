@@ -0,0 +1,139 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"pj/internal/server"
+	"pj/pkg/pj"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestDashboard_Assets(t *testing.T) {
+	ts, _ := newTestServer(t, testToken)
+
+	for _, path := range []string{"/", "/static/app.js", "/static/style.css"} {
+		t.Run(path, func(t *testing.T) {
+			status, body := get(t, ts.URL+path)
+
+			assert.Equal(t, http.StatusOK, status)
+			assert.NotContains(t, body, "https://", "assets must work offline")
+			assert.NotContains(t, body, "http://", "assets must work offline")
+		})
+	}
+
+	status, _ := get(t, ts.URL+"/v1/dashboard")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestDashboard_Projects(t *testing.T) {
+	now := time.Now()
+	ts, _ := newTestServer(t, testToken, func(s *server.Server) {
+		s.WithStaleAfter(24 * time.Hour).WithClock(func() time.Time { return now })
+	})
+	fresh := addViaAPI(t, ts, "fresh")
+	old := addViaAPI(t, ts, "old")
+	_, out := do(t, ts, http.MethodGet, "/v1/projects/"+old, "")
+	oldPath := out["path"].(string)
+	require.NoError(t, os.Chtimes(oldPath, now.Add(-48*time.Hour), now.Add(-48*time.Hour)))
+	do(t, ts, http.MethodPatch, "/v1/projects/"+old, `{"tags":["work","go"]}`)
+
+	resp, out := do(t, ts, http.MethodGet, "/v1/dashboard", "")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []any{"go", "work"}, out["tags"])
+	assert.Equal(t, false, out["can_open"])
+	projects := out["projects"].([]any)
+	require.Len(t, projects, 2)
+	first, second := projects[0].(map[string]any), projects[1].(map[string]any)
+	assert.Equal(t, fresh, first["id"])
+	assert.Equal(t, false, first["stale"])
+	assert.Equal(t, old, second["id"])
+	assert.Equal(t, true, second["stale"])
+	assert.Equal(t, true, second["available"])
+
+	_, out = do(t, ts, http.MethodGet, "/v1/dashboard?tag=go", "")
+	assert.Len(t, out["projects"], 1)
+}
+
+func TestDashboard_GitStatus(t *testing.T) {
+	ts, cat := newTestServer(t, testToken)
+	id := addViaAPI(t, ts, "plain")
+
+	resp, out := do(t, ts, http.MethodGet, "/v1/projects/"+id+"/git", "")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, false, out["repository"])
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	p, err := cat.Get(id)
+	require.NoError(t, err)
+	require.NoError(t, exec.Command("git", "init", "-q", "-b", "trunk", p.Path).Run())
+	require.NoError(t, os.WriteFile(p.Path+"/new", nil, 0o644))
+
+	_, out = do(t, ts, http.MethodGet, "/v1/projects/"+id+"/git", "")
+
+	assert.Equal(t, true, out["repository"])
+	assert.Equal(t, "trunk", out["branch"])
+	assert.Equal(t, true, out["dirty"])
+	assert.EqualValues(t, 1, out["untracked"])
+}
+
+func TestDashboard_Open(t *testing.T) {
+	t.Run("is disabled without an opener", func(t *testing.T) {
+		ts, _ := newTestServer(t, testToken)
+		id := addViaAPI(t, ts, "api")
+
+		resp, _ := do(t, ts, http.MethodPost, "/v1/projects/"+id+"/open", "")
+
+		assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	})
+
+	t.Run("opens and touches the project", func(t *testing.T) {
+		var opened []string
+		ts, cat := newTestServer(t, testToken, func(s *server.Server) {
+			s.WithOpener(func(p pj.Project) error {
+				opened = append(opened, p.Name)
+				return nil
+			})
+		})
+		id := addViaAPI(t, ts, "api")
+		before, _ := cat.Get(id)
+
+		resp, _ := do(t, ts, http.MethodPost, "/v1/projects/"+id+"/open", "")
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"api"}, opened)
+		after, _ := cat.Get(id)
+		assert.True(t, after.LastAccessed.After(before.LastAccessed))
+	})
+
+	t.Run("reports editor errors", func(t *testing.T) {
+		ts, _ := newTestServer(t, testToken, func(s *server.Server) {
+			s.WithOpener(func(pj.Project) error { return io.ErrUnexpectedEOF })
+		})
+		id := addViaAPI(t, ts, "api")
+
+		resp, out := do(t, ts, http.MethodPost, "/v1/projects/"+id+"/open", "")
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Contains(t, out["error"], "unexpected EOF")
+	})
+}
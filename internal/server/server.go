@@ -0,0 +1,382 @@
+// Package server exposes a catalog over a small HTTP/JSON API for editor
+// extensions and launchers.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"pj/pkg/pj"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server routes the /v1 API to a catalog. Every mutation goes through
+// pj.Catalog.Mutate, which the pj command uses too, so it takes the same
+// catalog lock, sees the command's changes and is applied atomically.
+type Server struct {
+	cat   *pj.Catalog
+	token string
+	mux   *http.ServeMux
+
+	open       Opener
+	staleAfter time.Duration
+	now        func() time.Time
+}
+
+// errReadOnly answers changes to a server started without a token.
+var errReadOnly = errors.New("this server is read-only: changes need a bearer token (pj serve --token-file)")
+
+// New returns a server for cat. A non-empty token is required as a bearer
+// token on every API request; the dashboard's static assets are public to
+// local clients. Without a token the API is read-only and only answers
+// clients on this machine.
+func New(cat *pj.Catalog, token string) *Server {
+	s := &Server{cat: cat, token: token, mux: http.NewServeMux(), now: time.Now}
+	s.mux.HandleFunc("GET /v1/projects", s.listProjects)
+	s.mux.HandleFunc("POST /v1/projects", s.addProject)
+	s.mux.HandleFunc("GET /v1/projects/{id}", s.getProject)
+	s.mux.HandleFunc("PATCH /v1/projects/{id}", s.updateProject)
+	s.mux.HandleFunc("DELETE /v1/projects/{id}", s.removeProject)
+	s.mux.HandleFunc("POST /v1/projects/{id}/touch", s.touchProject)
+	s.mux.HandleFunc("GET /v1/resolve", s.resolveProject)
+	s.registerDashboard()
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.admitted(r) {
+		writeError(w, http.StatusForbidden, errors.New("requests must be addressed to localhost, or carry the bearer token from another host"))
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/v1/") && (s.token != "" || changes(r)) && !s.authorized(r) {
+		if s.token == "" {
+			writeError(w, http.StatusForbidden, errReadOnly)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="pj"`)
+		writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return s.token != "" && ok && subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1
+}
+
+// changes reports whether r may change the catalog or start a program.
+func changes(r *http.Request) bool {
+	return r.Method != http.MethodGet && r.Method != http.MethodHead
+}
+
+// admitted reports whether r may reach the server at all. Clients on this
+// machine must address it as a loopback host and, when r is sent by a web
+// page, come from one. This keeps other sites from reaching the API through
+// the browser, even by rebinding their domain to 127.0.0.1. The Host and
+// Origin headers say nothing about where r was sent from, so clients on
+// other hosts must carry the bearer token instead. Browsers cannot connect
+// to unix sockets, so those are exempt.
+func (s *Server) admitted(r *http.Request) bool {
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok && addr.Network() == "unix" {
+		return true
+	}
+	if !isLoopbackHost(r.RemoteAddr) {
+		return s.authorized(r)
+	}
+	if !isLoopbackHost(r.Host) {
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && isLoopbackHost(u.Host)
+}
+
+func isLoopbackHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = strings.TrimSuffix(strings.TrimPrefix(hostport, "["), "]")
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ReadToken reads a bearer token from path, which must not be readable by
+// other users.
+func ReadToken(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return "", fmt.Errorf("token file %s must not be accessible by other users (chmod 600)", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// Listen opens addr, which is either host:port or "unix:" followed by a
+// socket path. A stale socket is replaced; any other file at the path is
+// left alone and reported.
+func Listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// projectInput is the body of add and update requests. Absent fields are
+// left unchanged on update.
+type projectInput struct {
+	Name        *string   `json:"name"`
+	Path        *string   `json:"path"`
+	Description *string   `json:"description"`
+	Tags        *[]string `json:"tags"`
+}
+
+// expandPath resolves "~" and configured roots in the requested path.
+func (in *projectInput) expandPath(cat *pj.Catalog) error {
+	if in.Path == nil {
+		return nil
+	}
+	path, err := cat.ExpandPath(*in.Path)
+	if err != nil {
+		return err
+	}
+	in.Path = &path
+	return nil
+}
+
+func (in projectInput) apply(p pj.Project) pj.Project {
+	if in.Path != nil {
+		p.Path = *in.Path
+	}
+	if in.Name != nil {
+		p.Name = *in.Name
+	}
+	if in.Description != nil {
+		p = p.WithDescription(*in.Description)
+	}
+	if in.Tags != nil {
+		p = p.WithoutTags(p.Tags...).WithTags(*in.Tags...)
+	}
+	return p
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	desc, _ := strconv.ParseBool(q.Get("desc"))
+	all, _ := strconv.ParseBool(q.Get("all"))
+	projects := s.cat.Filter(pj.FilterOptions{
+		Query:           q.Get("q"),
+		Tag:             q.Get("tag"),
+		ExcludeArchived: !all,
+		SortBy:          pj.SortField(q.Get("sort")),
+		Descending:      desc,
+	})
+	if projects == nil {
+		projects = []pj.Project{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"projects": projects})
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	p, err := s.cat.Get(r.PathValue("id"))
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) resolveProject(w http.ResponseWriter, r *http.Request) {
+	p, err := s.cat.Resolve(r.URL.Query().Get("q"))
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) addProject(w http.ResponseWriter, r *http.Request) {
+	var in projectInput
+	if !s.readInput(w, r, &in) {
+		return
+	}
+	if in.Path == nil {
+		writeError(w, http.StatusBadRequest, errors.New("path is required"))
+		return
+	}
+
+	p := in.apply(pj.NewProject("", ""))
+	if in.Name == nil {
+		p.Name = filepath.Base(p.Path)
+	}
+
+	if err := s.cat.Mutate(func(tx pj.Tx) error { return tx.Add(p) }); err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	s.respondWithProject(w, http.StatusCreated, p.ID)
+}
+
+func (s *Server) updateProject(w http.ResponseWriter, r *http.Request) {
+	var in projectInput
+	if !s.readInput(w, r, &in) {
+		return
+	}
+
+	id := r.PathValue("id")
+	err := s.cat.Mutate(func(tx pj.Tx) error {
+		p, err := tx.Get(id)
+		if err != nil {
+			return err
+		}
+		return tx.Update(in.apply(p))
+	})
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	s.respondWithProject(w, http.StatusOK, id)
+}
+
+func (s *Server) removeProject(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.cat.Mutate(func(tx pj.Tx) error { return tx.Remove(id) }); err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) touchProject(w http.ResponseWriter, r *http.Request) {
+	p, err := s.cat.Touch(r.PathValue("id"))
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) respondWithProject(w http.ResponseWriter, status int, id string) {
+	p, err := s.cat.Get(id)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeJSON(w, status, p)
+}
+
+func (s *Server) readInput(w http.ResponseWriter, r *http.Request, in *projectInput) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(in); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	if err := in.expandPath(s.cat); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type errorBody struct {
+	Error   string       `json:"error"`
+	Items   []itemError  `json:"items,omitempty"`
+	Matches []pj.Project `json:"matches,omitempty"`
+}
+
+type itemError struct {
+	Index int    `json:"index"`
+	Op    string `json:"op"`
+	Item  string `json:"item"`
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorBody{Error: err.Error()})
+}
+
+// writeCatalogError maps catalog errors to status codes.
+func writeCatalogError(w http.ResponseWriter, err error) {
+	body := errorBody{Error: err.Error()}
+
+	var ambErr *pj.AmbiguousMatchError
+	if errors.As(err, &ambErr) {
+		body.Matches = ambErr.Matches
+	}
+	var batchErr *pj.BatchError
+	if errors.As(err, &batchErr) {
+		for _, item := range batchErr.Items {
+			body.Items = append(body.Items, itemError{Index: item.Index, Op: item.Op, Item: item.Item, Error: item.Err.Error()})
+		}
+	}
+
+	writeJSON(w, statusFor(err), body)
+}
+
+func statusFor(err error) int {
+	var ambErr *pj.AmbiguousMatchError
+	switch {
+	case errors.Is(err, pj.ErrNotFound), errors.Is(err, pj.ErrNoMatch):
+		return http.StatusNotFound
+	case errors.Is(err, pj.ErrAlreadyExists), errors.As(err, &ambErr):
+		return http.StatusConflict
+	case errors.As(err, new(*pj.BatchError)):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
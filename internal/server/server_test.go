@@ -0,0 +1,360 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"pj/internal/server"
+	"pj/pkg/pj"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "s3cret"
+
+func newTestServer(t *testing.T, token string, configure ...func(*server.Server)) (*httptest.Server, *pj.Catalog) {
+	t.Helper()
+	cat, err := pj.Open(pj.Options{
+		CatalogPath: filepath.Join(t.TempDir(), "catalog.yaml"),
+		ConfigPath:  filepath.Join(t.TempDir(), "config.yaml"),
+	})
+	require.NoError(t, err)
+	srv := server.New(cat, token)
+	for _, fn := range configure {
+		fn(srv)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts, cat
+}
+
+func do(t *testing.T, ts *httptest.Server, method, path, body string) (*http.Response, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out map[string]any
+	if resp.StatusCode != http.StatusNoContent {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp, out
+}
+
+func addViaAPI(t *testing.T, ts *httptest.Server, name string) string {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"name": name, "path": t.TempDir()})
+	resp, out := do(t, ts, http.MethodPost, "/v1/projects", string(body))
+	require.Equal(t, http.StatusCreated, resp.StatusCode, out)
+	return out["id"].(string)
+}
+
+func TestServer_Projects(t *testing.T) {
+	t.Run("adds and gets a project", func(t *testing.T) {
+		ts, cat := newTestServer(t, testToken)
+		id := addViaAPI(t, ts, "api")
+
+		resp, out := do(t, ts, http.MethodGet, "/v1/projects/"+id, "")
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "api", out["name"])
+		_, err := cat.Get(id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("lists with filters", func(t *testing.T) {
+		ts, _ := newTestServer(t, testToken)
+		addViaAPI(t, ts, "web")
+		addViaAPI(t, ts, "api")
+
+		resp, out := do(t, ts, http.MethodGet, "/v1/projects?sort=name&desc=true", "")
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		projects := out["projects"].([]any)
+		require.Len(t, projects, 2)
+		assert.Equal(t, "web", projects[0].(map[string]any)["name"])
+
+		_, out = do(t, ts, http.MethodGet, "/v1/projects?q=ap", "")
+		assert.Len(t, out["projects"], 1)
+	})
+
+	t.Run("updates only given fields", func(t *testing.T) {
+		ts, _ := newTestServer(t, testToken)
+		id := addViaAPI(t, ts, "api")
+
+		resp, out := do(t, ts, http.MethodPatch, "/v1/projects/"+id, `{"description":"REST","tags":["work"]}`)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode, out)
+		assert.Equal(t, "api", out["name"])
+		assert.Equal(t, "REST", out["description"])
+		assert.Equal(t, []any{"work"}, out["tags"])
+	})
+
+	t.Run("touches a project", func(t *testing.T) {
+		ts, cat := newTestServer(t, testToken)
+		id := addViaAPI(t, ts, "api")
+		before, _ := cat.Get(id)
+
+		resp, _ := do(t, ts, http.MethodPost, "/v1/projects/"+id+"/touch", "")
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		after, _ := cat.Get(id)
+		assert.True(t, after.LastAccessed.After(before.LastAccessed))
+	})
+
+	t.Run("keeps changes made by another process", func(t *testing.T) {
+		ts, cat := newTestServer(t, testToken)
+		id := addViaAPI(t, ts, "api")
+		other, err := pj.Open(pj.Options{CatalogPath: cat.Path(), ConfigPath: filepath.Join(t.TempDir(), "config.yaml")})
+		require.NoError(t, err)
+		require.NoError(t, other.Mutate(func(tx pj.Tx) error {
+			return tx.Add(pj.NewProject("web", t.TempDir()))
+		}))
+
+		resp, out := do(t, ts, http.MethodPatch, "/v1/projects/"+id, `{"description":"REST"}`)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode, out)
+		reopened, err := pj.Open(pj.Options{CatalogPath: cat.Path(), ConfigPath: filepath.Join(t.TempDir(), "config.yaml")})
+		require.NoError(t, err)
+		assert.Len(t, reopened.List(), 2)
+	})
+
+	t.Run("removes a project", func(t *testing.T) {
+		ts, _ := newTestServer(t, testToken)
+		id := addViaAPI(t, ts, "api")
+
+		resp, _ := do(t, ts, http.MethodDelete, "/v1/projects/"+id, "")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, _ = do(t, ts, http.MethodDelete, "/v1/projects/"+id, "")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestServer_Errors(t *testing.T) {
+	ts, _ := newTestServer(t, testToken)
+	dir := t.TempDir()
+	body, _ := json.Marshal(map[string]any{"path": dir})
+	resp, _ := do(t, ts, http.MethodPost, "/v1/projects", string(body))
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"duplicate path", http.MethodPost, "/v1/projects", string(body), http.StatusConflict},
+		{"missing path", http.MethodPost, "/v1/projects", `{"name":"x"}`, http.StatusBadRequest},
+		{"unknown field", http.MethodPost, "/v1/projects", `{"nope":1}`, http.StatusBadRequest},
+		{"editor is not settable", http.MethodPatch, "/v1/projects/nope", `{"editor":"sh -c evil"}`, http.StatusBadRequest},
+		{"nonexistent directory", http.MethodPost, "/v1/projects", `{"path":"/does/not/exist"}`, http.StatusUnprocessableEntity},
+		{"unknown id", http.MethodGet, "/v1/projects/nope", "", http.StatusNotFound},
+		{"unknown id on update", http.MethodPatch, "/v1/projects/nope", `{}`, http.StatusNotFound},
+		{"no match", http.MethodGet, "/v1/resolve?q=zzz", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, out := do(t, ts, tt.method, tt.path, tt.body)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.NotEmpty(t, out["error"])
+		})
+	}
+}
+
+func TestServer_Resolve(t *testing.T) {
+	ts, _ := newTestServer(t, testToken)
+	addViaAPI(t, ts, "api-gateway")
+	addViaAPI(t, ts, "api-client")
+
+	resp, out := do(t, ts, http.MethodGet, "/v1/resolve?q=gateway", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "api-gateway", out["name"])
+
+	resp, out = do(t, ts, http.MethodGet, "/v1/resolve?q=api", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Len(t, out["matches"], 2)
+}
+
+// send makes a request with the given headers and returns its status.
+func send(t *testing.T, ts *httptest.Server, method, path string, header map[string]string) int {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, nil)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if host, ok := header["Host"]; ok {
+		req.Host = host
+	}
+	resp, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServer_BearerToken(t *testing.T) {
+	ts, _ := newTestServer(t, testToken)
+	id := addViaAPI(t, ts, "api")
+
+	assert.Equal(t, http.StatusUnauthorized, send(t, ts, http.MethodGet, "/v1/projects", nil))
+	assert.Equal(t, http.StatusUnauthorized, send(t, ts, http.MethodDelete, "/v1/projects/"+id, map[string]string{"Authorization": "Bearer wrong"}))
+	assert.Equal(t, http.StatusOK, send(t, ts, http.MethodGet, "/v1/projects", map[string]string{"Authorization": "Bearer " + testToken}))
+}
+
+func TestServer_ReadOnlyWithoutToken(t *testing.T) {
+	ts, _ := newTestServer(t, "")
+
+	assert.Equal(t, http.StatusOK, send(t, ts, http.MethodGet, "/v1/projects", nil))
+	resp, out := do(t, ts, http.MethodPost, "/v1/projects", `{"path":"`+t.TempDir()+`"}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Contains(t, out["error"], "read-only")
+	resp, _ = do(t, ts, http.MethodPost, "/v1/projects/x/open", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestServer_Loopback(t *testing.T) {
+	ts, _ := newTestServer(t, testToken)
+	auth := "Bearer " + testToken
+
+	tests := []struct {
+		name   string
+		path   string
+		header map[string]string
+		status int
+	}{
+		{"loopback address", "/v1/projects", map[string]string{"Authorization": auth}, http.StatusOK},
+		{"localhost", "/v1/projects", map[string]string{"Authorization": auth, "Host": "localhost:7777"}, http.StatusOK},
+		{"ipv6 loopback", "/v1/projects", map[string]string{"Authorization": auth, "Host": "[::1]:7777"}, http.StatusOK},
+		{"rebound domain", "/v1/projects", map[string]string{"Authorization": auth, "Host": "evil.example:7777"}, http.StatusForbidden},
+		{"local origin", "/v1/projects", map[string]string{"Authorization": auth, "Origin": "http://127.0.0.1:7777"}, http.StatusOK},
+		{"foreign origin", "/v1/projects", map[string]string{"Authorization": auth, "Origin": "https://evil.example"}, http.StatusForbidden},
+		{"dashboard assets", "/", map[string]string{"Host": "evil.example"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.status, send(t, ts, http.MethodGet, tt.path, tt.header))
+		})
+	}
+}
+
+func TestServer_RemoteClient(t *testing.T) {
+	ts, _ := newTestServer(t, testToken)
+
+	tests := []struct {
+		name   string
+		path   string
+		header map[string]string
+		status int
+	}{
+		{"spoofed loopback host", "/v1/projects", map[string]string{"Host": "127.0.0.1:7777"}, http.StatusForbidden},
+		{"dashboard assets", "/", map[string]string{"Host": "127.0.0.1:7777"}, http.StatusForbidden},
+		{"bearer token", "/v1/projects", map[string]string{"Authorization": "Bearer " + testToken}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = "192.0.2.1:53124"
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if host, ok := tt.header["Host"]; ok {
+				req.Host = host
+			}
+			rec := httptest.NewRecorder()
+
+			ts.Config.Handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestReadToken(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("trims whitespace", func(t *testing.T) {
+		path := filepath.Join(dir, "token")
+		require.NoError(t, os.WriteFile(path, []byte("abc\n"), 0o600))
+
+		token, err := server.ReadToken(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc", token)
+	})
+
+	t.Run("rejects group-readable file", func(t *testing.T) {
+		path := filepath.Join(dir, "open")
+		require.NoError(t, os.WriteFile(path, []byte("abc"), 0o644))
+
+		_, err := server.ReadToken(path)
+
+		assert.ErrorContains(t, err, "chmod 600")
+	})
+
+	t.Run("rejects empty file", func(t *testing.T) {
+		path := filepath.Join(dir, "empty")
+		require.NoError(t, os.WriteFile(path, []byte(" \n"), 0o600))
+
+		_, err := server.ReadToken(path)
+
+		assert.ErrorContains(t, err, "empty")
+	})
+}
+
+func TestListen_UnixSocket(t *testing.T) {
+	ts, _ := newTestServer(t, testToken)
+	sock := filepath.Join(t.TempDir(), "pj.sock")
+	stale, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	ln, err := server.Listen("unix:" + sock)
+	require.NoError(t, err)
+	srv := &http.Server{Handler: ts.Config.Handler}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	req, err := http.NewRequest(http.MethodPost, "http://pj/v1/projects", bytes.NewReader([]byte(`{"path":"`+t.TempDir()+`"}`)))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	info, err := os.Stat(sock)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestListen_KeepsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pj.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+	_, err := server.Listen("unix:" + path)
+
+	assert.ErrorContains(t, err, "not a socket")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+}
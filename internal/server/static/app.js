@@ -0,0 +1,150 @@
+"use strict";
+
+// A bearer token can be passed once in the URL fragment (#token=...); it is
+// kept for the session and never sent to the server in the URL.
+const params = new URLSearchParams(location.hash.slice(1));
+if (params.has("token")) {
+  sessionStorage.setItem("pj-token", params.get("token"));
+  history.replaceState(null, "", location.pathname);
+}
+
+const state = { query: "", tag: "" };
+const $ = (id) => document.getElementById(id);
+
+async function api(method, path) {
+  const headers = {};
+  const token = sessionStorage.getItem("pj-token");
+  if (token) headers.Authorization = "Bearer " + token;
+  const resp = await fetch(path, { method, headers });
+  const body = await resp.json().catch(() => ({}));
+  if (!resp.ok) throw new Error(body.error || resp.statusText);
+  return body;
+}
+
+function el(tag, attrs = {}, ...children) {
+  const node = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs)) {
+    if (key === "class") node.className = value;
+    else node.setAttribute(key, value);
+  }
+  node.append(...children.filter((c) => c != null));
+  return node;
+}
+
+function showError(err) {
+  $("error").textContent = err ? err.message : "";
+  $("error").hidden = !err;
+}
+
+// formatTime mirrors the CLI list: time of day, weekday, or date.
+function formatTime(iso) {
+  if (!iso) return "Unknown";
+  const t = new Date(iso);
+  const now = new Date();
+  const days = Math.round(
+    (new Date(now.toDateString()) - new Date(t.toDateString())) / 86400000,
+  );
+  const time = t.toLocaleTimeString([], { hour: "2-digit", minute: "2-digit", hour12: false });
+  if (days === 0) return time;
+  if (days === 1) return "Yesterday " + time;
+  if (days < 7) return t.toLocaleDateString([], { weekday: "short" }) + " " + time;
+  const opts = { month: "short", day: "numeric" };
+  if (t.getFullYear() !== now.getFullYear()) opts.year = "2-digit";
+  return t.toLocaleDateString([], opts) + " " + time;
+}
+
+function renderTags(tags) {
+  $("tags").replaceChildren(
+    ...tags.map((tag) => {
+      const button = el("button", { class: tag === state.tag ? "tag active" : "tag" }, tag);
+      button.onclick = () => {
+        state.tag = state.tag === tag ? "" : tag;
+        load();
+      };
+      return button;
+    }),
+  );
+}
+
+async function loadGit(project, cell) {
+  try {
+    const git = await api("GET", `/v1/projects/${project.id}/git`);
+    if (!git.repository) {
+      cell.textContent = "—";
+      return;
+    }
+    let text = git.branch;
+    if (git.ahead) text += ` ↑${git.ahead}`;
+    if (git.behind) text += ` ↓${git.behind}`;
+    if (git.dirty) text += ` ●${git.changed + git.untracked}`;
+    cell.textContent = text;
+    cell.classList.add(git.dirty ? "dirty" : "clean");
+  } catch (err) {
+    cell.textContent = "?";
+    cell.title = err.message;
+  }
+}
+
+function renderRow(project, canOpen) {
+  const gitCell = el("td", { class: "git" }, "…");
+  const open = el("button", { class: "open" }, "Open");
+  open.disabled = !canOpen || !project.available;
+  open.onclick = async () => {
+    try {
+      await api("POST", `/v1/projects/${project.id}/open`);
+      showError(null);
+    } catch (err) {
+      showError(err);
+    }
+  };
+
+  const row = el(
+    "tr",
+    { class: project.stale ? "stale" : "" },
+    el(
+      "td",
+      {},
+      el(
+        "div",
+        { class: "name" },
+        project.name,
+        ...((project.stack && project.stack.languages) || []).map((l) => el("span", { class: "lang" }, l)),
+      ),
+      el("div", { class: "path" }, project.path),
+      project.description ? el("div", { class: "desc" }, project.description) : null,
+    ),
+    el("td", {}, ...(project.tags || []).map((tag) => el("span", { class: "tag" }, tag))),
+    gitCell,
+    el("td", { class: "time" }, project.available ? formatTime(project.modified) : "Not on this host"),
+    el("td", {}, open),
+  );
+  if (project.available) loadGit(project, gitCell);
+  else gitCell.textContent = "—";
+  return row;
+}
+
+async function load() {
+  const query = new URLSearchParams();
+  if (state.query) query.set("q", state.query);
+  if (state.tag) query.set("tag", state.tag);
+  try {
+    const data = await api("GET", "/v1/dashboard?" + query);
+    renderTags(data.tags);
+    $("projects").replaceChildren(...data.projects.map((p) => renderRow(p, data.can_open)));
+    $("empty").hidden = data.projects.length > 0;
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+let debounce;
+$("search").addEventListener("input", (e) => {
+  clearTimeout(debounce);
+  debounce = setTimeout(() => {
+    state.query = e.target.value;
+    load();
+  }, 150);
+});
+
+load();
@@ -0,0 +1,562 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"pj/internal/git"
+	"pj/pkg/pj"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	readmeLines   = 8
+	detailTimeout = 5 * time.Second
+)
+
+var readmeNames = []string{"README.md", "README", "README.rst", "README.txt", "readme.md"}
+
+// Action is what the caller should do with the selected project once the
+// dashboard exits.
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionOpen
+	ActionCd
+)
+
+type Result struct {
+	Action  Action
+	Project pj.Project
+}
+
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeFilter
+	modeEdit
+	modeTag
+	modeConfirmRemove
+)
+
+type details struct {
+	git    *git.Status
+	gitErr error
+	readme string
+}
+
+type detailsMsg struct {
+	id      string
+	details details
+}
+
+type catalogChangedMsg struct{}
+
+// Dashboard is the full-screen project browser behind `pj tui`.
+type Dashboard struct {
+	cat    *pj.Catalog
+	events <-chan pj.Event
+
+	projects []pj.Project
+	cursor   int
+	offset   int
+	sortIdx  int
+	desc     bool
+	details  map[string]details
+
+	mode   mode
+	filter textinput.Model
+	input  textinput.Model
+	status string
+
+	width  int
+	height int
+	result Result
+}
+
+func NewDashboard(cat *pj.Catalog) Dashboard {
+	filter := textinput.New()
+	filter.Prompt = "/ "
+	filter.Placeholder = "filter"
+
+	m := Dashboard{
+		cat:     cat,
+		sortIdx: slices.Index(pj.SortFields, pj.SortByLastAccessed),
+		desc:    true,
+		details: map[string]details{},
+		filter:  filter,
+		input:   textinput.New(),
+		width:   80,
+		height:  24,
+	}
+	m.reload()
+	return m
+}
+
+// RunDashboard shows the dashboard until the user quits or picks a project,
+// refreshing whenever the catalog changes.
+func RunDashboard(ctx context.Context, cat *pj.Catalog) (Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m := NewDashboard(cat)
+	m.events = cat.Watch(ctx)
+	final, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	if err != nil {
+		return Result{}, err
+	}
+	return final.(Dashboard).Result(), nil
+}
+
+func (m Dashboard) Result() Result {
+	return m.result
+}
+
+func (m Dashboard) Selected() (pj.Project, bool) {
+	if m.cursor >= len(m.projects) {
+		return pj.Project{}, false
+	}
+	return m.projects[m.cursor], true
+}
+
+func (m Dashboard) Init() tea.Cmd {
+	return tea.Batch(m.loadDetails(), m.waitForChange())
+}
+
+func (m Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+		return m, nil
+	case detailsMsg:
+		m.details[msg.id] = msg.details
+		return m, nil
+	case catalogChangedMsg:
+		clear(m.details)
+		m.reload()
+		return m, tea.Batch(m.loadDetails(), m.waitForChange())
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		switch m.mode {
+		case modeFilter:
+			return m.updateFilter(msg)
+		case modeEdit, modeTag:
+			return m.updateInput(msg)
+		case modeConfirmRemove:
+			return m.updateConfirm(msg)
+		default:
+			return m.updateBrowse(msg)
+		}
+	}
+	return m, nil
+}
+
+func (m Dashboard) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	p, ok := m.Selected()
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "esc":
+		if m.filter.Value() != "" {
+			m.filter.SetValue("")
+			m.reload()
+			return m, m.loadDetails()
+		}
+		return m, tea.Quit
+	case "up", "k":
+		return m.move(-1)
+	case "down", "j":
+		return m.move(1)
+	case "pgup":
+		return m.move(-m.listHeight())
+	case "pgdown":
+		return m.move(m.listHeight())
+	case "home", "g":
+		return m.move(-len(m.projects))
+	case "end", "G":
+		return m.move(len(m.projects))
+	case "/":
+		m.mode = modeFilter
+		return m, m.filter.Focus()
+	case "s":
+		m.sortIdx = (m.sortIdx + 1) % len(pj.SortFields)
+		m.reload()
+		return m, m.loadDetails()
+	case "r":
+		m.desc = !m.desc
+		m.reload()
+		return m, m.loadDetails()
+	}
+
+	if !ok {
+		return m, nil
+	}
+	switch msg.String() {
+	case "enter", "o":
+		m.result = Result{Action: ActionOpen, Project: p}
+		return m, tea.Quit
+	case "c":
+		m.result = Result{Action: ActionCd, Project: p}
+		return m, tea.Quit
+	case "e":
+		m.mode = modeEdit
+		m.input.Prompt = "Description: "
+		m.input.Placeholder = ""
+		m.input.SetValue(p.Description)
+		return m, m.input.Focus()
+	case "t":
+		m.mode = modeTag
+		m.input.Prompt = "Tags: "
+		m.input.Placeholder = "tag -removed"
+		m.input.SetValue("")
+		return m, m.input.Focus()
+	case "d", "x":
+		m.mode = modeConfirmRemove
+	}
+	return m, nil
+}
+
+func (m Dashboard) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter, tea.KeyEsc:
+		m.mode = modeBrowse
+		m.filter.Blur()
+		return m, nil
+	case tea.KeyUp, tea.KeyDown:
+		return m.updateBrowse(msg)
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.reload()
+	return m, tea.Batch(cmd, m.loadDetails())
+}
+
+func (m Dashboard) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.mode = modeBrowse
+		m.input.Blur()
+		return m, nil
+	case tea.KeyEnter:
+		p, _ := m.Selected()
+		value := m.input.Value()
+		var err error
+		if m.mode == modeEdit {
+			err = m.update(p.ID, func(p pj.Project) pj.Project { return p.WithDescription(value) })
+		} else {
+			err = m.update(p.ID, func(p pj.Project) pj.Project { return applyTags(p, value) })
+		}
+		m.mode = modeBrowse
+		m.input.Blur()
+		m.setStatus(err, "Updated "+p.Name)
+		m.reload()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m Dashboard) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.mode = modeBrowse
+	p, _ := m.Selected()
+	if msg.String() != "y" && msg.String() != "Y" {
+		m.status = "Kept " + p.Name
+		return m, nil
+	}
+
+	err := m.cat.Mutate(func(tx pj.Tx) error { return tx.Remove(p.ID) })
+	m.setStatus(err, "Removed "+p.Name)
+	m.reload()
+	return m, m.loadDetails()
+}
+
+func (m *Dashboard) update(id string, fn func(pj.Project) pj.Project) error {
+	return m.cat.Mutate(func(tx pj.Tx) error {
+		p, err := tx.Get(id)
+		if err != nil {
+			return err
+		}
+		return tx.Update(fn(p))
+	})
+}
+
+func (m *Dashboard) setStatus(err error, success string) {
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	m.status = success
+}
+
+// applyTags adds each word of input as a tag, or removes it when prefixed
+// with "-".
+func applyTags(p pj.Project, input string) pj.Project {
+	for tag := range strings.FieldsSeq(input) {
+		if name, ok := strings.CutPrefix(tag, "-"); ok {
+			p = p.WithoutTags(name)
+		} else {
+			p = p.WithTags(tag)
+		}
+	}
+	return p
+}
+
+func (m Dashboard) move(delta int) (tea.Model, tea.Cmd) {
+	m.cursor = max(0, min(len(m.projects)-1, m.cursor+delta))
+	m.scroll()
+	return m, m.loadDetails()
+}
+
+// reload re-reads the catalog with the current filter and sort, keeping the
+// selection on the same project when it is still listed.
+func (m *Dashboard) reload() {
+	selected, _ := m.Selected()
+	m.projects = m.cat.Filter(pj.FilterOptions{
+		Query:           m.filter.Value(),
+		ExcludeArchived: true,
+		SortBy:          pj.SortFields[m.sortIdx],
+		Descending:      m.desc,
+	})
+	if i := slices.IndexFunc(m.projects, func(p pj.Project) bool { return p.ID == selected.ID }); i >= 0 {
+		m.cursor = i
+	}
+	m.cursor = max(0, min(len(m.projects)-1, m.cursor))
+	m.scroll()
+}
+
+func (m *Dashboard) scroll() {
+	height := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.projects)-height))
+}
+
+func (m Dashboard) loadDetails() tea.Cmd {
+	p, ok := m.Selected()
+	if !ok {
+		return nil
+	}
+	if _, cached := m.details[p.ID]; cached {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), detailTimeout)
+		defer cancel()
+
+		var d details
+		if status, err := git.StatusOf(ctx, p.Path); err == nil {
+			d.git = &status
+		} else {
+			d.gitErr = err
+		}
+		d.readme = readmeExcerpt(p.Path, readmeLines)
+		return detailsMsg{id: p.ID, details: d}
+	}
+}
+
+func (m Dashboard) waitForChange() tea.Cmd {
+	if m.events == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if _, ok := <-m.events; !ok {
+			return nil
+		}
+		return catalogChangedMsg{}
+	}
+}
+
+// readmeExcerpt returns the first non-blank lines of the project's README.
+func readmeExcerpt(dir string, n int) string {
+	for _, name := range readmeNames {
+		if f, err := os.Open(filepath.Join(dir, name)); err == nil {
+			defer f.Close()
+			return firstLines(f, n)
+		}
+	}
+	return ""
+}
+
+func firstLines(r io.Reader, n int) string {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for len(lines) < n && scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	tuiTitleStyle    = lipgloss.NewStyle().Bold(true)
+	tuiSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	tuiMutedStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	tuiLabelStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Width(14)
+	tuiDirtyStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	tuiCleanStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+)
+
+func (m Dashboard) listHeight() int {
+	return max(1, m.height-4)
+}
+
+func (m Dashboard) View() string {
+	order := "↑"
+	if m.desc {
+		order = "↓"
+	}
+	header := tuiTitleStyle.Render("pj") + tuiMutedStyle.Render(fmt.Sprintf(
+		"%s%d projects%ssort: %s %s", separator, len(m.projects), separator, pj.SortFields[m.sortIdx], order))
+
+	listWidth := max(20, m.width*2/5)
+	list := lipgloss.NewStyle().Width(listWidth).Height(m.listHeight()).Render(m.viewList(listWidth))
+	detail := lipgloss.NewStyle().
+		Width(max(20, m.width-listWidth-3)).
+		Height(m.listHeight()).
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(lipgloss.Color("8")).
+		PaddingLeft(1).
+		Render(m.viewDetails())
+	body := lipgloss.JoinHorizontal(lipgloss.Top, list, " ", detail)
+
+	return strings.Join([]string{header, "", body, m.viewFooter()}, "\n")
+}
+
+func (m Dashboard) viewList(width int) string {
+	if len(m.projects) == 0 {
+		return tuiMutedStyle.Render("No projects found.")
+	}
+
+	end := min(len(m.projects), m.offset+m.listHeight())
+	lines := make([]string, 0, end-m.offset)
+	for i := m.offset; i < end; i++ {
+		name := truncate(m.projects[i].Name, width-2)
+		if i == m.cursor {
+			lines = append(lines, tuiSelectedStyle.Render(activeSymbol+" "+name))
+		} else {
+			lines = append(lines, "  "+name)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Dashboard) viewDetails() string {
+	p, ok := m.Selected()
+	if !ok {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(tuiTitleStyle.Render(p.Name) + "\n\n")
+	field := func(label, value string) {
+		if value != "" {
+			b.WriteString(tuiLabelStyle.Render(label) + value + "\n")
+		}
+	}
+	field("Path", p.Path)
+	if p.Unavailable {
+		field("Host", "not available")
+	}
+	field("Description", p.Description)
+	field("Editor", p.Editor)
+	field("Tags", strings.Join(p.Tags, ", "))
+	if p.Stack != nil {
+		field("Stack", strings.Join(p.Stack.Summary(), ", "))
+	}
+	field("Added", formatTimestamp(p.AddedAt))
+	field("Last opened", formatTimestamp(p.LastAccessed))
+	field("Updated", formatTimestamp(p.UpdatedAt))
+	if p.Tmux != nil {
+		field("Tmux", fmt.Sprintf("%d windows", len(p.Tmux.Windows)))
+	}
+
+	d, loaded := m.details[p.ID]
+	switch {
+	case !loaded:
+		field("Git", tuiMutedStyle.Render("loading…"))
+	case d.git != nil:
+		field("Git", formatGit(*d.git))
+	case errors.Is(d.gitErr, git.ErrNotRepository):
+		field("Git", tuiMutedStyle.Render("not a repository"))
+	case d.gitErr != nil:
+		field("Git", tuiMutedStyle.Render(d.gitErr.Error()))
+	}
+	if d.readme != "" {
+		b.WriteString("\n" + tuiMutedStyle.Render("README") + "\n" + d.readme + "\n")
+	}
+	return b.String()
+}
+
+func (m Dashboard) viewFooter() string {
+	p, _ := m.Selected()
+	switch m.mode {
+	case modeFilter:
+		return m.filter.View()
+	case modeEdit, modeTag:
+		return m.input.View()
+	case modeConfirmRemove:
+		return tuiDirtyStyle.Render(fmt.Sprintf("Remove %s from the catalog? [y/N]", p.Name))
+	}
+	if m.status != "" {
+		return m.status
+	}
+	if m.filter.Value() != "" {
+		return m.filter.View() + tuiMutedStyle.Render(separator+"esc clear")
+	}
+	return tuiMutedStyle.Render(strings.Join([]string{
+		"enter open", "c cd", "e edit", "t tag", "d remove", "s sort", "r reverse", "/ filter", "q quit",
+	}, separator))
+}
+
+func formatGit(s git.Status) string {
+	text := s.Branch
+	if s.Ahead > 0 {
+		text += fmt.Sprintf(" ↑%d", s.Ahead)
+	}
+	if s.Behind > 0 {
+		text += fmt.Sprintf(" ↓%d", s.Behind)
+	}
+	if !s.Dirty() {
+		return text + tuiCleanStyle.Render(" clean")
+	}
+	return text + tuiDirtyStyle.Render(fmt.Sprintf(" %d changed, %d untracked", s.Changed, s.Untracked))
+}
+
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width || width < 2 {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:width-1]) + "…"
+}
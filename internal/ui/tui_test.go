@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"pj/internal/git"
+	"pj/pkg/pj"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDashboard(t *testing.T, names ...string) (Dashboard, *pj.Catalog) {
+	t.Helper()
+	cat, err := pj.Open(pj.Options{
+		CatalogPath: filepath.Join(t.TempDir(), "catalog.yaml"),
+		ConfigPath:  filepath.Join(t.TempDir(), "config.yaml"),
+	})
+	require.NoError(t, err)
+
+	base := time.Now()
+	for i, name := range names {
+		p := pj.NewProject(name, t.TempDir())
+		p.LastAccessed = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, cat.Mutate(func(tx pj.Tx) error { return tx.Add(p) }))
+	}
+	return NewDashboard(cat), cat
+}
+
+func press(t *testing.T, m Dashboard, keys ...string) (Dashboard, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		var model tea.Model
+		model, cmd = m.Update(msg)
+		m = model.(Dashboard)
+	}
+	return m, cmd
+}
+
+func selectedName(m Dashboard) string {
+	p, _ := m.Selected()
+	return p.Name
+}
+
+func TestDashboard_Navigation(t *testing.T) {
+	m, _ := newTestDashboard(t, "alpha", "beta", "gamma")
+
+	assert.Equal(t, "gamma", selectedName(m), "most recently opened first")
+
+	m, _ = press(t, m, "j")
+	assert.Equal(t, "beta", selectedName(m))
+
+	m, _ = press(t, m, "G")
+	assert.Equal(t, "alpha", selectedName(m))
+
+	m, _ = press(t, m, "g")
+	assert.Equal(t, "gamma", selectedName(m))
+}
+
+func TestDashboard_Sort(t *testing.T) {
+	m, _ := newTestDashboard(t, "beta", "alpha", "gamma")
+
+	view := stripANSI(m.View())
+	assert.Contains(t, view, "sort: last_accessed ↓")
+
+	m, _ = press(t, m, "s")
+	assert.Contains(t, stripANSI(m.View()), "sort: added_at ↓")
+
+	m, _ = press(t, m, "s", "r", "g")
+	assert.Contains(t, stripANSI(m.View()), "sort: name ↑")
+	assert.Equal(t, "alpha", selectedName(m))
+
+	m, _ = press(t, m, "s", "s", "s", "s")
+	assert.Contains(t, stripANSI(m.View()), "sort: name ↑", "cycles through every field")
+}
+
+func TestDashboard_Filter(t *testing.T) {
+	m, _ := newTestDashboard(t, "api-server", "web", "api-client")
+
+	m, _ = press(t, m, "/", "w", "e", "b", "enter")
+
+	assert.Len(t, m.projects, 1)
+	assert.Equal(t, "web", selectedName(m))
+
+	m, _ = press(t, m, "esc")
+	assert.Len(t, m.projects, 3)
+}
+
+func TestDashboard_Actions(t *testing.T) {
+	t.Run("open quits with the selected project", func(t *testing.T) {
+		m, _ := newTestDashboard(t, "alpha", "beta")
+
+		m, cmd := press(t, m, "enter")
+
+		assert.Equal(t, ActionOpen, m.Result().Action)
+		assert.Equal(t, "beta", m.Result().Project.Name)
+		assert.IsType(t, tea.QuitMsg{}, cmd())
+	})
+
+	t.Run("cd quits with the selected project", func(t *testing.T) {
+		m, _ := newTestDashboard(t, "alpha", "beta")
+
+		m, cmd := press(t, m, "down", "c")
+
+		assert.Equal(t, ActionCd, m.Result().Action)
+		assert.Equal(t, "alpha", m.Result().Project.Name)
+		assert.IsType(t, tea.QuitMsg{}, cmd())
+	})
+
+	t.Run("quit leaves no action", func(t *testing.T) {
+		m, _ := newTestDashboard(t, "alpha")
+
+		m, _ = press(t, m, "q")
+
+		assert.Equal(t, ActionNone, m.Result().Action)
+	})
+}
+
+func TestDashboard_Edit(t *testing.T) {
+	t.Run("updates the description", func(t *testing.T) {
+		m, cat := newTestDashboard(t, "alpha")
+
+		m, _ = press(t, m, "e", "R", "E", "S", "T", "enter")
+
+		p, _ := m.Selected()
+		stored, err := cat.Get(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "REST", stored.Description)
+		assert.Contains(t, stripANSI(m.View()), "Updated alpha")
+	})
+
+	t.Run("adds and removes tags", func(t *testing.T) {
+		m, cat := newTestDashboard(t, "alpha")
+
+		m, _ = press(t, m, "t", "work go", "enter")
+		m, _ = press(t, m, "t", "-work", "enter")
+
+		p, _ := m.Selected()
+		stored, err := cat.Get(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go"}, stored.Tags)
+	})
+
+	t.Run("escape cancels", func(t *testing.T) {
+		m, cat := newTestDashboard(t, "alpha")
+
+		m, _ = press(t, m, "e", "x", "esc")
+
+		p, _ := m.Selected()
+		stored, _ := cat.Get(p.ID)
+		assert.Empty(t, stored.Description)
+	})
+}
+
+func TestDashboard_Remove(t *testing.T) {
+	m, cat := newTestDashboard(t, "alpha", "beta")
+
+	m, _ = press(t, m, "d")
+	assert.Contains(t, stripANSI(m.View()), "Remove beta from the catalog? [y/N]")
+
+	m, _ = press(t, m, "n")
+	assert.Len(t, cat.List(), 2)
+
+	m, _ = press(t, m, "d", "y")
+	assert.Len(t, cat.List(), 1)
+	assert.Equal(t, "alpha", selectedName(m))
+}
+
+func TestDashboard_Details(t *testing.T) {
+	m, _ := newTestDashboard(t, "alpha")
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 30})
+	m = model.(Dashboard)
+	p, _ := m.Selected()
+	require.NoError(t, os.WriteFile(filepath.Join(p.Path, "README.md"), []byte("# Alpha\n\nDoes things.\n"), 0o644))
+
+	assert.Contains(t, stripANSI(m.View()), "loading…")
+
+	model, _ = m.Update(m.Init()())
+	view := stripANSI(model.View())
+
+	assert.Contains(t, view, p.Path)
+	assert.Contains(t, view, "not a repository")
+	assert.Contains(t, view, "# Alpha")
+	assert.Contains(t, view, "Does things.")
+
+	model, _ = model.Update(detailsMsg{id: p.ID, details: details{git: &git.Status{Branch: "main", Ahead: 2, Changed: 1}}})
+	assert.Contains(t, stripANSI(model.View()), "main ↑2 1 changed, 0 untracked")
+}
+
+func TestApplyTags(t *testing.T) {
+	p := pj.NewProject("x", "/x").WithTags("old", "keep")
+
+	got := applyTags(p, "new -old  other")
+
+	assert.ElementsMatch(t, []string{"keep", "new", "other"}, got.Tags)
+}
@@ -0,0 +1,25 @@
+// Package pj is the public API for reading and changing the pj project
+// catalog from other Go programs.
+//
+// Open loads the same catalog the pj command uses, honoring its config
+// file, portable paths and storage backend:
+//
+//	cat, err := pj.Open(pj.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	defer cat.Close()
+//
+//	p, err := cat.Resolve("api")
+//
+// Changes go through Catalog.Mutate, which holds a lock shared with every
+// other pj process and persists all of a callback's changes together.
+//
+// The package follows semantic versioning: exported functions and the
+// Catalog, Options and error types are not removed or changed
+// incompatibly within a major version, and Options fields always have a
+// usable zero value. Project and the other types aliased from the catalog
+// are the catalog's own records: they may gain fields in any release, so
+// build them with NewProject and the With methods rather than composite
+// literals.
+package pj
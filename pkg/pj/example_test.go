@@ -0,0 +1,73 @@
+package pj_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"pj/pkg/pj"
+)
+
+func Example() {
+	dir, _ := os.MkdirTemp("", "pj-example")
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"api", "web"} {
+		_ = os.Mkdir(filepath.Join(dir, name), 0o755)
+	}
+
+	cat, err := pj.Open(pj.Options{
+		CatalogPath: filepath.Join(dir, "catalog.yaml"),
+		ConfigPath:  filepath.Join(dir, "config.yaml"),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cat.Close()
+
+	err = cat.Mutate(func(tx pj.Tx) error {
+		if err := tx.Add(pj.NewProject("api", filepath.Join(dir, "api"))); err != nil {
+			return err
+		}
+		return tx.Add(pj.NewProject("web", filepath.Join(dir, "web")).WithTags("frontend"))
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	p, err := cat.Resolve("api")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("resolved:", p.Name)
+
+	for _, p := range cat.Filter(pj.FilterOptions{Tag: "frontend"}) {
+		fmt.Println("frontend:", p.Name)
+	}
+	// Output:
+	// resolved: api
+	// frontend: web
+}
+
+func ExampleCatalog_Mutate() {
+	dir, _ := os.MkdirTemp("", "pj-example")
+	defer os.RemoveAll(dir)
+
+	cat, err := pj.Open(pj.Options{
+		CatalogPath: filepath.Join(dir, "catalog.yaml"),
+		ConfigPath:  filepath.Join(dir, "config.yaml"),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cat.Close()
+
+	// Neither project is added: one invalid change rejects the batch.
+	err = cat.Mutate(func(tx pj.Tx) error {
+		_ = tx.Add(pj.NewProject("ok", dir))
+		_ = tx.Add(pj.NewProject("missing", filepath.Join(dir, "missing")))
+		return nil
+	})
+	fmt.Println(err != nil, len(cat.List()))
+	// Output:
+	// true 0
+}
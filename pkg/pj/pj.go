@@ -0,0 +1,316 @@
+package pj
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"pj/internal/catalog"
+	"pj/internal/config"
+	"slices"
+	"strings"
+)
+
+// ErrNoMatch is returned by Resolve when no project matches the query.
+var ErrNoMatch = errors.New("no project found matching")
+
+// AmbiguousMatchError is returned by Resolve when a query matches more than
+// one project.
+type AmbiguousMatchError struct {
+	Query   string
+	Matches []Project
+}
+
+func (e *AmbiguousMatchError) Error() string {
+	return fmt.Sprintf("multiple projects match %q", e.Query)
+}
+
+// Options controls Open. The zero value opens the default catalog.
+type Options struct {
+	// CatalogPath overrides the catalog location. Its extension picks the
+	// backend: .db, .sqlite and .sqlite3 use SQLite, anything else YAML
+	// unless the config says otherwise.
+	CatalogPath string
+	// ConfigPath overrides the config file location.
+	ConfigPath string
+	// Hostname selects per-host roots from the config. Defaults to
+	// os.Hostname.
+	Hostname string
+}
+
+// Catalog is an open pj catalog. It is safe for concurrent use.
+type Catalog struct {
+	store  catalog.Catalog
+	path   string
+	mapper config.PathMapper
+}
+
+// Open loads the catalog described by opts.
+func Open(opts Options) (*Catalog, error) {
+	configPath := opts.ConfigPath
+	if configPath == "" {
+		configPath = config.DefaultConfigPath()
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname := opts.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	mapper, err := cfg.PathMapper(hostname)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %q: %w", configPath, err)
+	}
+
+	path, backend := resolveCatalog(opts.CatalogPath, cfg)
+	store, err := catalog.Open(path, backend, mapper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	return &Catalog{store: store, path: path, mapper: mapper}, nil
+}
+
+// resolveCatalog picks the catalog file and backend. An explicit path's
+// extension wins over the configured backend.
+func resolveCatalog(path string, cfg config.Config) (string, catalog.Backend) {
+	configured := catalog.Backend(cfg.Catalog.Backend)
+	if path == "" {
+		path = config.DefaultCatalogPath()
+		if configured == catalog.BackendSQLite {
+			path = catalog.PathForBackend(path, configured)
+		}
+	}
+	return path, catalog.BackendForPath(path, configured)
+}
+
+// Path returns the location of the catalog file.
+func (c *Catalog) Path() string {
+	return c.path
+}
+
+func (c *Catalog) Get(id string) (Project, error) {
+	return c.store.Get(id)
+}
+
+func (c *Catalog) GetByPath(path string) (Project, error) {
+	return c.store.GetByPath(path)
+}
+
+func (c *Catalog) List() []Project {
+	return c.store.List()
+}
+
+func (c *Catalog) Filter(opts FilterOptions) []Project {
+	return c.store.Filter(opts)
+}
+
+// Search returns the projects whose name or path contains query, ignoring
+// case.
+func (c *Catalog) Search(query string) []Project {
+	return c.store.Search(query)
+}
+
+func (c *Catalog) Count() int {
+	return c.store.Count()
+}
+
+func (c *Catalog) Workspaces() []Workspace {
+	return c.store.ListWorkspaces()
+}
+
+func (c *Catalog) GetWorkspace(name string) (Workspace, error) {
+	return c.store.GetWorkspace(name)
+}
+
+// Resolve finds the single project matching query by name or path, the way
+// the pj command resolves its project arguments.
+func (c *Catalog) Resolve(query string) (Project, error) {
+	return Resolve(c.store, query)
+}
+
+// Resolve finds the single project in store matching query by name or
+// path. A query qualified by its parent, such as "mono/api", prefers that
+// parent's sub-projects. It fails with ErrNoMatch or an
+// *AmbiguousMatchError otherwise.
+func Resolve(store Store, query string) (Project, error) {
+	return resolve(store, query, map[string]resolved{})
+}
+
+type resolved struct {
+	project Project
+	err     error
+}
+
+// resolve memoizes by query because path-like queries split at every slash.
+func resolve(store Store, query string, seen map[string]resolved) (Project, error) {
+	if r, ok := seen[query]; ok {
+		return r.project, r.err
+	}
+	p, err := resolveUncached(store, query, seen)
+	seen[query] = resolved{p, err}
+	return p, err
+}
+
+func resolveUncached(store Store, query string, seen map[string]resolved) (Project, error) {
+	if p, ok, err := resolveQualified(store, query, seen); ok {
+		return p, err
+	}
+	projects := withoutNestedMatches(store.Search(query), query)
+	if len(projects) > 1 {
+		// Archived projects only win when nothing else matches.
+		if active := slices.DeleteFunc(slices.Clone(projects), Project.Archived); len(active) > 0 {
+			projects = active
+		}
+	}
+	if len(projects) == 0 {
+		return Project{}, fmt.Errorf("%w: %s", ErrNoMatch, query)
+	}
+	if len(projects) > 1 {
+		return Project{}, &AmbiguousMatchError{Query: query, Matches: projects}
+	}
+	return projects[0], nil
+}
+
+// resolveQualified resolves "parent/child" among the parent's sub-projects,
+// exact names first. It reports false when no split of query names a
+// parent with a matching sub-project.
+func resolveQualified(store Store, query string, seen map[string]resolved) (Project, bool, error) {
+	for i, c := range query {
+		if c != '/' || i == 0 || i == len(query)-1 {
+			continue
+		}
+		parent, err := resolve(store, query[:i], seen)
+		if err != nil {
+			continue
+		}
+		name := strings.ToLower(query[i+1:])
+		var exact, partial []Project
+		for _, p := range store.List() {
+			if p.Parent != parent.ID {
+				continue
+			}
+			switch childName := strings.ToLower(p.Name); {
+			case childName == name:
+				exact = append(exact, p)
+			case strings.Contains(childName, name):
+				partial = append(partial, p)
+			}
+		}
+		matches := exact
+		if len(matches) == 0 {
+			matches = partial
+		}
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], true, nil
+		default:
+			return Project{}, true, &AmbiguousMatchError{Query: query, Matches: matches}
+		}
+	}
+	return Project{}, false, nil
+}
+
+// withoutNestedMatches drops sub-projects that match only because their
+// path lies inside a matching parent, so "mono" still means the monorepo.
+func withoutNestedMatches(projects []Project, query string) []Project {
+	matched := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		matched[p.ID] = true
+	}
+	query = strings.ToLower(query)
+	return slices.DeleteFunc(projects, func(p Project) bool {
+		return p.Parent != "" && matched[p.Parent] && !strings.Contains(strings.ToLower(p.Name), query)
+	})
+}
+
+// ResolveDir finds the project that dir is in: the deepest one whose
+// directory or one of whose worktrees contains it, so a sub-project wins
+// over its monorepo. It fails with ErrNoMatch when dir is in none.
+func ResolveDir(store Store, dir string) (Project, error) {
+	dir = filepath.Clean(dir)
+	var best Project
+	bestLen := -1
+	for _, p := range store.List() {
+		roots := []string{p.Path}
+		for _, w := range p.Worktrees {
+			roots = append(roots, w.Path)
+		}
+		for _, root := range roots {
+			if len(root) > bestLen && isWithin(dir, root) {
+				best, bestLen = p, len(root)
+			}
+		}
+	}
+	if bestLen < 0 {
+		return Project{}, fmt.Errorf("%w: %s", ErrNoMatch, dir)
+	}
+	return best, nil
+}
+
+func isWithin(path, dir string) bool {
+	dir = filepath.Clean(dir)
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// ExpandPath resolves "~", $HOME and the configured roots such as
+// "${code}/api" in path.
+func (c *Catalog) ExpandPath(path string) (string, error) {
+	return c.mapper.Expand(path)
+}
+
+// Mutate applies fn's changes under a lock shared with every other pj
+// process. The catalog is reloaded first so fn sees other processes'
+// changes, and all of fn's changes are saved together or not at all.
+func (c *Catalog) Mutate(fn func(tx Tx) error) error {
+	if c.path != "" {
+		unlock, err := catalog.LockFile(c.path)
+		if err != nil {
+			return fmt.Errorf("failed to lock catalog: %w", err)
+		}
+		defer unlock()
+	}
+
+	if err := c.store.Load(); err != nil {
+		return fmt.Errorf("failed to load catalog: %w", err)
+	}
+	return c.store.Batch(fn)
+}
+
+// Touch marks the project with id as accessed now and returns it.
+func (c *Catalog) Touch(id string) (Project, error) {
+	var touched Project
+	err := c.Mutate(func(tx Tx) error {
+		p, err := tx.Get(id)
+		if err != nil {
+			return err
+		}
+		p.Touch()
+		if err := tx.Update(p); err != nil {
+			return err
+		}
+		touched, err = tx.Get(id)
+		return err
+	})
+	return touched, err
+}
+
+// Watch streams changes to projects until ctx is done.
+func (c *Catalog) Watch(ctx context.Context) <-chan Event {
+	return c.store.Watch(ctx)
+}
+
+// Close releases the catalog's resources.
+func (c *Catalog) Close() error {
+	if closer, ok := c.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
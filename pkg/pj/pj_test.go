@@ -0,0 +1,247 @@
+package pj_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"pj/pkg/pj"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestCatalog(t *testing.T, name string) *pj.Catalog {
+	t.Helper()
+	cat, err := pj.Open(pj.Options{
+		CatalogPath: filepath.Join(t.TempDir(), name),
+		ConfigPath:  filepath.Join(t.TempDir(), "config.yaml"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cat.Close() })
+	return cat
+}
+
+func addProject(t *testing.T, cat *pj.Catalog, name string) pj.Project {
+	t.Helper()
+	p := pj.NewProject(name, t.TempDir())
+	require.NoError(t, cat.Mutate(func(tx pj.Tx) error { return tx.Add(p) }))
+	return p
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("uses default path for configured backend", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", t.TempDir())
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("catalog:\n  backend: sqlite\n"), 0o644))
+
+		cat, err := pj.Open(pj.Options{ConfigPath: configPath})
+		require.NoError(t, err)
+		defer cat.Close()
+
+		assert.Equal(t, "catalog.db", filepath.Base(cat.Path()))
+	})
+
+	t.Run("catalog extension wins over config", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("catalog:\n  backend: sqlite\n"), 0o644))
+		path := filepath.Join(t.TempDir(), "catalog.yaml")
+
+		cat, err := pj.Open(pj.Options{CatalogPath: path, ConfigPath: configPath})
+		require.NoError(t, err)
+
+		addProject(t, cat, "api")
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "name: api")
+	})
+
+	t.Run("returns error for invalid config", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("roots: ["), 0o644))
+
+		_, err := pj.Open(pj.Options{ConfigPath: configPath})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCatalog_Resolve(t *testing.T) {
+	cat := openTestCatalog(t, "catalog.yaml")
+	addProject(t, cat, "api-server")
+	addProject(t, cat, "api-client")
+	addProject(t, cat, "web")
+
+	got, err := cat.Resolve("web")
+	require.NoError(t, err)
+	assert.Equal(t, "web", got.Name)
+
+	_, err = cat.Resolve("nope")
+	assert.ErrorIs(t, err, pj.ErrNoMatch)
+
+	_, err = cat.Resolve("api")
+	var ambErr *pj.AmbiguousMatchError
+	require.ErrorAs(t, err, &ambErr)
+	assert.Len(t, ambErr.Matches, 2)
+}
+
+func TestCatalog_ResolveSubprojects(t *testing.T) {
+	cat := openTestCatalog(t, "catalog.yaml")
+	mono := pj.NewProject("mono", filepath.Join(t.TempDir(), "mono"))
+	other := addProject(t, cat, "shop")
+	for _, dir := range []string{"services/api", "services/api-gateway", "apps/web"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(mono.Path, dir), 0o755))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(other.Path, "api"), 0o755))
+	require.NoError(t, cat.Mutate(func(tx pj.Tx) error {
+		for _, p := range []pj.Project{
+			mono,
+			pj.NewProject("api", filepath.Join(mono.Path, "services", "api")).WithParent(mono.ID),
+			pj.NewProject("api-gateway", filepath.Join(mono.Path, "services", "api-gateway")).WithParent(mono.ID),
+			pj.NewProject("web", filepath.Join(mono.Path, "apps", "web")).WithParent(mono.ID),
+			pj.NewProject("api", filepath.Join(other.Path, "api")).WithParent(other.ID),
+		} {
+			if err := tx.Add(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	t.Run("parent name ignores sub-projects inside it", func(t *testing.T) {
+		got, err := cat.Resolve("mono")
+		require.NoError(t, err)
+		assert.Equal(t, mono.ID, got.ID)
+	})
+
+	t.Run("qualified name prefers the exact sub-project", func(t *testing.T) {
+		got, err := cat.Resolve("mono/api")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(mono.Path, "services", "api"), got.Path)
+
+		got, err = cat.Resolve("shop/api")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(other.Path, "api"), got.Path)
+	})
+
+	t.Run("qualified name matches part of a sub-project name", func(t *testing.T) {
+		got, err := cat.Resolve("mono/gate")
+		require.NoError(t, err)
+		assert.Equal(t, "api-gateway", got.Name)
+	})
+
+	t.Run("unqualified name stays ambiguous", func(t *testing.T) {
+		_, err := cat.Resolve("api")
+		var ambErr *pj.AmbiguousMatchError
+		require.ErrorAs(t, err, &ambErr)
+		assert.Len(t, ambErr.Matches, 3)
+	})
+
+	t.Run("path fragments still match", func(t *testing.T) {
+		got, err := cat.Resolve("apps/web")
+		require.NoError(t, err)
+		assert.Equal(t, "web", got.Name)
+	})
+}
+
+func TestResolveDir(t *testing.T) {
+	cat := openTestCatalog(t, "catalog.yaml")
+	mono := pj.NewProject("mono", t.TempDir())
+	api := pj.NewProject("api", filepath.Join(mono.Path, "api")).WithParent(mono.ID)
+	worktree := t.TempDir()
+	web := pj.NewProject("web", t.TempDir()).WithWorktrees([]pj.Worktree{{Branch: "fix", Path: worktree}})
+	require.NoError(t, os.Mkdir(api.Path, 0o755))
+	require.NoError(t, cat.Mutate(func(tx pj.Tx) error {
+		return errors.Join(tx.Add(mono), tx.Add(api), tx.Add(web))
+	}))
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{mono.Path, "mono"},
+		{filepath.Join(mono.Path, "docs"), "mono"},
+		{filepath.Join(api.Path, "cmd", "server"), "api"},
+		{worktree + "/src/", "web"},
+	}
+	for _, tt := range tests {
+		got, err := pj.ResolveDir(cat, tt.dir)
+		require.NoError(t, err, tt.dir)
+		assert.Equal(t, tt.want, got.Name, tt.dir)
+	}
+
+	_, err := pj.ResolveDir(cat, mono.Path+"-other")
+	assert.ErrorIs(t, err, pj.ErrNoMatch)
+}
+
+func TestCatalog_Mutate(t *testing.T) {
+	for _, name := range []string{"catalog.yaml", "catalog.db"} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("sees changes from other handles", func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), name)
+				opts := pj.Options{CatalogPath: path, ConfigPath: filepath.Join(t.TempDir(), "none.yaml")}
+				first, err := pj.Open(opts)
+				require.NoError(t, err)
+				defer first.Close()
+				second, err := pj.Open(opts)
+				require.NoError(t, err)
+				defer second.Close()
+
+				addProject(t, first, "api")
+				addProject(t, second, "web")
+
+				require.NoError(t, first.Mutate(func(tx pj.Tx) error { return nil }))
+				assert.Len(t, first.List(), 2)
+			})
+
+			t.Run("serializes concurrent writers", func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), name)
+				opts := pj.Options{CatalogPath: path, ConfigPath: filepath.Join(t.TempDir(), "none.yaml")}
+
+				var wg sync.WaitGroup
+				for range 4 {
+					cat, err := pj.Open(opts)
+					require.NoError(t, err)
+					defer cat.Close()
+					dir := t.TempDir()
+					wg.Go(func() {
+						assert.NoError(t, cat.Mutate(func(tx pj.Tx) error {
+							return tx.Add(pj.NewProject(filepath.Base(dir), dir))
+						}))
+					})
+				}
+				wg.Wait()
+
+				reopened, err := pj.Open(opts)
+				require.NoError(t, err)
+				defer reopened.Close()
+				assert.Len(t, reopened.List(), 4)
+			})
+
+			t.Run("rejects invalid changes", func(t *testing.T) {
+				cat := openTestCatalog(t, name)
+
+				err := cat.Mutate(func(tx pj.Tx) error {
+					return tx.Add(pj.NewProject("", t.TempDir()))
+				})
+
+				var batchErr *pj.BatchError
+				assert.ErrorAs(t, err, &batchErr)
+				assert.Empty(t, cat.List())
+			})
+		})
+	}
+}
+
+func TestCatalog_ExpandPath(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("roots:\n  code: /src\n"), 0o644))
+	cat, err := pj.Open(pj.Options{CatalogPath: filepath.Join(t.TempDir(), "c.yaml"), ConfigPath: configPath})
+	require.NoError(t, err)
+
+	got, err := cat.ExpandPath("${code}/api")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/src/api", got)
+}
@@ -0,0 +1,71 @@
+package pj
+
+import (
+	"pj/internal/catalog"
+	"pj/internal/config"
+)
+
+type (
+	Project       = catalog.Project
+	TmuxLayout    = catalog.TmuxLayout
+	TmuxWindow    = catalog.TmuxWindow
+	Status        = catalog.Status
+	Worktree      = catalog.Worktree
+	Workspace     = catalog.Workspace
+	FilterOptions = catalog.FilterOptions
+	SortField     = catalog.SortField
+	Event         = catalog.Event
+	EventKind     = catalog.EventKind
+	Backend       = catalog.Backend
+
+	// Tx is the view of the catalog passed to Catalog.Mutate.
+	Tx = catalog.Tx
+	// BatchError lists every change a rejected Mutate callback attempted
+	// that failed validation.
+	BatchError = catalog.BatchError
+	ItemError  = catalog.ItemError
+)
+
+const (
+	SortByName         = catalog.SortByName
+	SortByPath         = catalog.SortByPath
+	SortByLastAccessed = catalog.SortByLastAccessed
+	SortByAddedAt      = catalog.SortByAddedAt
+
+	EventAdded   = catalog.EventAdded
+	EventUpdated = catalog.EventUpdated
+	EventRemoved = catalog.EventRemoved
+
+	StatusActive   = catalog.StatusActive
+	StatusPaused   = catalog.StatusPaused
+	StatusArchived = catalog.StatusArchived
+
+	BackendYAML   = catalog.BackendYAML
+	BackendSQLite = catalog.BackendSQLite
+)
+
+// Store is the set of projects Resolve and ResolveDir search. *Catalog
+// implements it.
+type Store interface {
+	List() []Project
+	Search(query string) []Project
+}
+
+// SortFields lists every supported sort order.
+var SortFields = catalog.SortFields
+
+var (
+	ErrNotFound      = catalog.ErrNotFound
+	ErrAlreadyExists = catalog.ErrAlreadyExists
+	ErrPathNotExist  = catalog.ErrPathNotExist
+)
+
+// NewProject returns a project with a fresh ID, added and accessed now.
+func NewProject(name, path string) Project {
+	return catalog.NewProject(name, path)
+}
+
+// ExpandPath expands a leading "~" and $HOME in path.
+func ExpandPath(path string) (string, error) {
+	return config.ExpandPath(path)
+}
@@ -1,6 +1,7 @@
 package proptest
 
 import (
+	"maps"
 	"pj/internal/catalog"
 	"slices"
 
@@ -56,6 +57,13 @@ func (s *StateTracker) Update(p catalog.Project) error {
 	return nil
 }
 
+func (s *StateTracker) clone() *StateTracker {
+	return &StateTracker{
+		idToPath: maps.Clone(s.idToPath),
+		pathToID: maps.Clone(s.pathToID),
+	}
+}
+
 func (s *StateTracker) IDs() []string {
 	ids := make([]string, 0, len(s.idToPath))
 	for id := range s.idToPath {
@@ -165,3 +173,52 @@ func (c *CheckedCatalog) Filter(opts catalog.FilterOptions) []catalog.Project {
 	realResults := c.real.Filter(opts)
 	return realResults
 }
+
+// batchOp is one mutation inside a batch: a removal when removeID is set,
+// an add otherwise.
+type batchOp struct {
+	project  catalog.Project
+	removeID string
+}
+
+func (c *CheckedCatalog) Batch(ops []batchOp) error {
+	staged := c.model.clone()
+	modelOK := true
+	for _, op := range ops {
+		var err error
+		if op.removeID != "" {
+			err = staged.Remove(op.removeID)
+		} else {
+			err = staged.Add(op.project)
+		}
+		modelOK = modelOK && err == nil
+	}
+
+	realErr := c.real.Batch(func(tx catalog.Tx) error {
+		for _, op := range ops {
+			if op.removeID != "" {
+				_ = tx.Remove(op.removeID)
+			} else {
+				_ = tx.Add(op.project)
+			}
+		}
+		return nil
+	})
+	if (realErr == nil) != modelOK {
+		c.t.Fatalf("Batch divergence: real=%v model ok=%v", realErr, modelOK)
+	}
+	if realErr == nil {
+		c.model = staged
+	}
+
+	realIDs := make([]string, 0, c.real.Count())
+	for _, p := range c.real.List() {
+		realIDs = append(realIDs, p.ID)
+	}
+	slices.Sort(realIDs)
+	if !slices.Equal(realIDs, c.model.IDs()) {
+		c.t.Fatalf("Batch left catalog with IDs %v, model has %v", realIDs, c.model.IDs())
+	}
+	verifyStructuralInvariants(c.t, c.real)
+	return realErr
+}
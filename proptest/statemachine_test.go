@@ -1,6 +1,8 @@
 package proptest
 
 import (
+	"path/filepath"
+	"pj/internal/catalog"
 	"testing"
 
 	"pgregory.net/rapid"
@@ -63,6 +65,44 @@ func TestProperty_StateMachine_CatalogOperations(t *testing.T) {
 				_ = checked.Search(query)
 			},
 
+			"batch": func(rt *rapid.T) {
+				n := rapid.IntRange(1, 5).Draw(rt, "batchSize")
+				ops := make([]batchOp, 0, n)
+				for range n {
+					ids := checked.Model().IDs()
+					kinds := []string{"add", "removeUnknown"}
+					if len(ids) > 0 {
+						kinds = append(kinds, "addDuplicate", "remove")
+					}
+					switch rapid.SampledFrom(kinds).Draw(rt, "opKind") {
+					case "add":
+						ops = append(ops, batchOp{project: GenProject(rt, h.Dir)})
+					case "addDuplicate":
+						existing, _ := checked.Get(rapid.SampledFrom(ids).Draw(rt, "dupID"))
+						ops = append(ops, batchOp{project: catalog.NewProject("dup", existing.Path)})
+					case "remove":
+						ops = append(ops, batchOp{removeID: rapid.SampledFrom(ids).Draw(rt, "removeID")})
+					case "removeUnknown":
+						ops = append(ops, batchOp{removeID: "unknown-id"})
+					}
+				}
+
+				if err := checked.Batch(ops); err != nil {
+					return
+				}
+
+				reloaded, err := catalog.NewYAMLCatalog(filepath.Join(h.Dir, "catalog.yaml"))
+				if err != nil {
+					rt.Fatalf("failed to reopen catalog: %v", err)
+				}
+				if err := reloaded.Load(); err != nil {
+					rt.Fatalf("failed to reload catalog: %v", err)
+				}
+				if reloaded.Count() != checked.Model().Count() {
+					rt.Fatalf("batch persisted %d projects, model has %d", reloaded.Count(), checked.Model().Count())
+				}
+			},
+
 			"filter": func(rt *rapid.T) {
 				opts := filterOptionsGen().Draw(rt, "filterOpts")
 				_ = checked.Filter(opts)